@@ -1,35 +0,0 @@
-package database
-
-import (
-	"database/sql"
-	"fmt"
-	"log"
-	"os"
-
-	_ "github.com/lib/pq"
-)
-
-// Connect estabelece conexão com o banco de dados PostgreSQL
-func Connect() (*sql.DB, error) {
-	databaseURL := os.Getenv("DATABASE_URL")
-	if databaseURL == "" {
-		return nil, fmt.Errorf("DATABASE_URL não configurada")
-	}
-
-	db, err := sql.Open("postgres", databaseURL)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao abrir conexão com banco: %w", err)
-	}
-
-	// Configurações de pool de conexões
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
-
-	// Testar conexão
-	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("erro ao conectar ao banco: %w", err)
-	}
-
-	log.Println("Conectado ao PostgreSQL com sucesso")
-	return db, nil
-}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+
+	"hospital-cqrs/internal/commands"
+	"hospital-cqrs/internal/domain"
+	"hospital-cqrs/internal/idempotencia"
+	"hospital-cqrs/pkg/auth"
+)
+
+const problemaIdempotencyKey = "/problemas/idempotency-key"
+
+// localReserva guarda em c.Locals a reserva da Idempotency-Key da requisição
+const localReserva = "idempotencia.reserva"
+
+// idempotente aplica Idempotency-Key ao comando seguinte: a primeira requisição executa o
+// comando; repetições com o mesmo corpo recebem a resposta original (Idempotent-Replayed: true)
+// e a mesma chave com outro corpo recebe 409. Sem o header, o comando executa normalmente.
+// A rota grava a resposta na transação do comando (ver respostaIdempotente).
+func idempotente(store *idempotencia.Store) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		chave := c.Get(idempotencia.Header)
+		if chave == "" {
+			return c.Next()
+		}
+		if len(chave) > idempotencia.TamanhoMaximoChave {
+			return responderProblema(c, Problema{
+				Type:   problemaDadosInvalidos,
+				Title:  titulosProblema[400],
+				Status: 400,
+				Detail: fmt.Sprintf("%s deve ter no máximo %d caracteres", idempotencia.Header, idempotencia.TamanhoMaximoChave),
+			})
+		}
+
+		// As chaves são por usuário: a mesma chave de dois médicos são requisições diferentes
+		usuario := auth.Usuario(c).Subject
+		hash := idempotencia.HashRequisicao(c.Method(), c.Path(), c.Body())
+
+		resposta, reserva, err := store.Reservar(c.UserContext(), usuario, chave, hash)
+		switch {
+		case errors.Is(err, idempotencia.ErrChaveReutilizada), errors.Is(err, idempotencia.ErrEmProcessamento):
+			return responderProblema(c, Problema{
+				Type:   problemaIdempotencyKey,
+				Title:  titulosProblema[409],
+				Status: 409,
+				Detail: err.Error(),
+			})
+		case err != nil:
+			return erroComoProblema(c, err)
+		case resposta != nil:
+			for nome, valor := range resposta.Cabecalhos {
+				c.Set(nome, valor)
+			}
+			c.Set("Idempotent-Replayed", "true")
+			return c.Status(resposta.Status).Send(resposta.Corpo)
+		}
+
+		// Chave reservada: o comando grava a resposta na própria transação. Se falhar, liberar a
+		// reserva (só apaga se não houver resposta, ou seja, se o comando não foi confirmado)
+		c.Locals(localReserva, reserva)
+		if err := c.Next(); err != nil {
+			liberarChave(store, reserva)
+			return err
+		}
+
+		status := c.Response().StatusCode()
+		if status < 200 || status >= 300 {
+			liberarChave(store, reserva)
+		}
+		return nil
+	}
+}
+
+// respostaIdempotente devolve a conclusão que grava a resposta 201 de POST /prescricoes na
+// transação do comando; nil sem Idempotency-Key. O corpo é o mesmo que a rota envia
+func respostaIdempotente(c *fiber.Ctx) commands.ConclusaoCriacao {
+	reserva, ok := c.Locals(localReserva).(*idempotencia.Reserva)
+	if !ok {
+		return nil
+	}
+
+	return func(ctx context.Context, tx *sql.Tx, prescricao *domain.Prescricao, token string) error {
+		corpo, err := json.Marshal(respostaCriacao(prescricao, token))
+		if err != nil {
+			return fmt.Errorf("erro ao serializar resposta: %w", err)
+		}
+		return reserva.Concluir(ctx, tx, idempotencia.Resposta{
+			Status: 201,
+			Corpo:  corpo,
+			Cabecalhos: map[string]string{
+				fiber.HeaderContentType:       fiber.MIMEApplicationJSON,
+				domain.ConsistencyTokenHeader: token,
+			},
+		})
+	}
+}
+
+// liberarChave desfaz a reserva de um comando que falhou para que o cliente possa repetir
+// (com context.Background para liberar mesmo se o cliente desconectou)
+func liberarChave(store *idempotencia.Store, reserva *idempotencia.Reserva) {
+	if err := store.Liberar(context.Background(), reserva); err != nil {
+		log.Printf("AVISO: %v", err)
+	}
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+
+	"hospital-cqrs/internal/commands"
+	"hospital-cqrs/internal/domain"
+	"hospital-cqrs/internal/idempotencia"
+	"hospital-cqrs/pkg/auth"
+	"hospital-cqrs/pkg/database"
+)
+
+func main() {
+	log.Println("Iniciando Command Service (Write Side - CQRS com Materialized Views)...")
+
+	// Conectar ao banco de dados
+	db, err := database.Connect()
+	if err != nil {
+		log.Fatalf("Erro ao conectar ao banco de dados: %v", err)
+	}
+	defer db.Close()
+
+	// Autenticação JWT (chave em JWT_SECRET)
+	autenticador, err := auth.NewAutenticador()
+	if err != nil {
+		log.Fatalf("Erro de configuração: %v", err)
+	}
+
+	// Criar handler de comandos
+	// Sem producer: o read side é recalculado pelo refresh das materialized views
+	prescricaoHandler := commands.NewPrescricaoHandler(db)
+	idempotencyStore := idempotencia.NewStore(db)
+
+	// Configurar Fiber
+	app := fiber.New(fiber.Config{
+		AppName: "Hospital CQRS - Command Service (Materialized Views)",
+	})
+
+	// Middlewares
+	app.Use(recover.New())
+	app.Use(logger.New())
+	app.Use(cors.New())
+
+	// Rotas de saúde
+	app.Get("/health", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"status":  "healthy",
+			"service": "command-service",
+		})
+	})
+
+	// Rotas de comandos: exigem token; cada comando restringe o perfil
+	api := app.Group("/api/v1", autenticador.Middleware())
+
+	// Endpoints auxiliares (para consulta rápida - não são queries do CQRS)
+	api.Get("/medicos", func(c *fiber.Ctx) error {
+		medicos, err := prescricaoHandler.ListMedicos(c.Context())
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(medicos)
+	})
+
+	api.Get("/pacientes", func(c *fiber.Ctx) error {
+		pacientes, err := prescricaoHandler.ListPacientes(c.Context())
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(pacientes)
+	})
+
+	api.Get("/medicamentos", func(c *fiber.Ctx) error {
+		medicamentos, err := prescricaoHandler.ListMedicamentos(c.Context())
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(medicamentos)
+	})
+
+	// Comando: Criar Prescrição (Write Side); aceita Idempotency-Key para repetições seguras
+	api.Post("/prescricoes", auth.ExigirPapel(auth.PapelMedico), idempotente(idempotencyStore), func(c *fiber.Ctx) error {
+		var dto domain.CriarPrescricaoDTO
+		if err := c.BodyParser(&dto); err != nil {
+			return corpoInvalido(c, err)
+		}
+
+		// O médico só prescreve em seu próprio nome
+		if dto.IDMedico != auth.Usuario(c).MedicoID {
+			return responderProblema(c, Problema{Type: "about:blank", Title: titulosProblema[403], Status: 403, Detail: "O médico só pode prescrever em seu próprio nome"})
+		}
+
+		// Com Idempotency-Key, a resposta é gravada na transação que cria a prescrição
+		prescricao, token, err := prescricaoHandler.CriarPrescricao(c.Context(), dto, respostaIdempotente(c))
+		if err != nil {
+			log.Printf("Erro ao criar prescrição: %v", err)
+			return erroComoProblema(c, err)
+		}
+
+		// Token de consistência: enviado de volta ao query service para ler a própria escrita
+		c.Set(domain.ConsistencyTokenHeader, token)
+		return c.Status(201).JSON(respostaCriacao(prescricao, token))
+	})
+
+	// Comando: Atualizar dosagem/horário dos medicamentos da prescrição
+	api.Put("/prescricoes/:id", auth.ExigirPapel(auth.PapelMedico), func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return responderProblema(c, Problema{Type: "about:blank", Title: titulosProblema[400], Status: 400, Detail: "ID inválido"})
+		}
+
+		var dto domain.AtualizarPrescricaoDTO
+		if err := c.BodyParser(&dto); err != nil {
+			return corpoInvalido(c, err)
+		}
+
+		medicamentos, token, err := prescricaoHandler.AtualizarPrescricao(c.Context(), id, dto)
+		if err != nil {
+			log.Printf("Erro ao atualizar prescrição %d: %v", id, err)
+			return erroComoProblema(c, err)
+		}
+
+		c.Set(domain.ConsistencyTokenHeader, token)
+		return c.JSON(fiber.Map{
+			"message":           "Prescrição atualizada com sucesso",
+			"medicamentos":      medicamentos,
+			"consistency_token": token,
+		})
+	})
+
+	// Comando: Cancelar Prescrição
+	api.Post("/prescricoes/:id/cancelar", auth.ExigirPapel(auth.PapelMedico), func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return responderProblema(c, Problema{Type: "about:blank", Title: titulosProblema[400], Status: 400, Detail: "ID inválido"})
+		}
+
+		var dto domain.CancelarPrescricaoDTO
+		if err := c.BodyParser(&dto); err != nil {
+			return corpoInvalido(c, err)
+		}
+
+		prescricao, token, err := prescricaoHandler.CancelarPrescricao(c.Context(), id, dto)
+		if err != nil {
+			log.Printf("Erro ao cancelar prescrição %d: %v", id, err)
+			return erroComoProblema(c, err)
+		}
+
+		c.Set(domain.ConsistencyTokenHeader, token)
+		return c.JSON(fiber.Map{
+			"message":           "Prescrição cancelada com sucesso",
+			"prescricao":        prescricao,
+			"consistency_token": token,
+		})
+	})
+
+	// Comandos de cadastro: a alteração aparece nas views no próximo refresh
+	api.Put("/medicos/:id", auth.ExigirPapel(auth.PapelAdmin), func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "ID inválido"})
+		}
+
+		var dto domain.AtualizarMedicoDTO
+		if err := c.BodyParser(&dto); err != nil || dto.Nome == "" || dto.Especialidade == "" || dto.CRM == "" {
+			return c.Status(400).JSON(fiber.Map{"error": "Dados inválidos"})
+		}
+
+		medico, err := prescricaoHandler.AtualizarMedico(c.Context(), id, dto)
+		if err != nil {
+			log.Printf("Erro ao atualizar médico %d: %v", id, err)
+			return c.Status(statusDoErro(err)).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "Médico atualizado com sucesso",
+			"medico":  medico,
+		})
+	})
+
+	api.Put("/pacientes/:id", auth.ExigirPapel(auth.PapelAdmin), func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "ID inválido"})
+		}
+
+		var dto domain.AtualizarPacienteDTO
+		if err := c.BodyParser(&dto); err != nil || dto.Nome == "" || dto.DataNascimento == "" {
+			return c.Status(400).JSON(fiber.Map{"error": "Dados inválidos"})
+		}
+
+		paciente, err := prescricaoHandler.AtualizarPaciente(c.Context(), id, dto)
+		if err != nil {
+			log.Printf("Erro ao atualizar paciente %d: %v", id, err)
+			return c.Status(statusDoErro(err)).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		return c.JSON(fiber.Map{
+			"message":  "Paciente atualizado com sucesso",
+			"paciente": paciente,
+		})
+	})
+
+	api.Put("/medicamentos/:id", auth.ExigirPapel(auth.PapelAdmin), func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "ID inválido"})
+		}
+
+		var dto domain.AtualizarMedicamentoDTO
+		if err := c.BodyParser(&dto); err != nil || dto.Nome == "" {
+			return c.Status(400).JSON(fiber.Map{"error": "Dados inválidos"})
+		}
+
+		medicamento, err := prescricaoHandler.AtualizarMedicamento(c.Context(), id, dto)
+		if err != nil {
+			log.Printf("Erro ao atualizar medicamento %d: %v", id, err)
+			return c.Status(statusDoErro(err)).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		return c.JSON(fiber.Map{
+			"message":     "Medicamento atualizado com sucesso",
+			"medicamento": medicamento,
+		})
+	})
+
+	// Iniciar servidor
+	port := os.Getenv("SERVICE_PORT")
+	if port == "" {
+		port = "3000"
+	}
+
+	// Graceful shutdown
+	go func() {
+		if err := app.Listen(":" + port); err != nil {
+			log.Fatalf("Erro ao iniciar servidor: %v", err)
+		}
+	}()
+
+	log.Printf("Command Service rodando na porta %s", port)
+
+	// Aguardar sinal de interrupção
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	<-quit
+
+	log.Println("Encerrando Command Service...")
+	if err := app.ShutdownWithContext(context.Background()); err != nil {
+		log.Printf("Erro ao encerrar servidor: %v", err)
+	}
+}
+
+// respostaCriacao é o corpo 201 de POST /prescricoes (também gravado para a Idempotency-Key)
+func respostaCriacao(prescricao *domain.Prescricao, token string) fiber.Map {
+	return fiber.Map{
+		"message":           "Prescrição criada com sucesso",
+		"prescricao":        prescricao,
+		"consistency_token": token,
+	}
+}
+
+// statusDoErro traduz erros de domínio dos comandos em status HTTP
+func statusDoErro(err error) int {
+	switch {
+	case errors.Is(err, commands.ErrPrescricaoNaoEncontrada),
+		errors.Is(err, commands.ErrMedicoNaoEncontrado),
+		errors.Is(err, commands.ErrPacienteNaoEncontrado),
+		errors.Is(err, commands.ErrMedicamentoNaoEncontrado):
+		return 404
+	case errors.Is(err, commands.ErrPrescricaoCancelada),
+		errors.Is(err, commands.ErrCRMDuplicado),
+		errors.Is(err, idempotencia.ErrReservaPerdida):
+		return 409
+	case errors.Is(err, commands.ErrDataNascimentoInvalida):
+		return 400
+	case errors.Is(err, commands.ErrMedicamentoNaoPrescrito):
+		return 422
+	default:
+		return 500
+	}
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+
+	"hospital-cqrs/internal/domain"
+	"hospital-cqrs/internal/queries"
+	"hospital-cqrs/internal/refresh"
+	"hospital-cqrs/pkg/auth"
+	"hospital-cqrs/pkg/database"
+)
+
+func main() {
+	log.Println("Iniciando Query Service (Read Side - CQRS com Materialized Views)...")
+
+	// Conectar ao banco de dados
+	db, err := database.Connect()
+	if err != nil {
+		log.Fatalf("Erro ao conectar ao banco de dados: %v", err)
+	}
+	defer db.Close()
+
+	// Autenticação JWT (chave em JWT_SECRET)
+	autenticador, err := auth.NewAutenticador()
+	if err != nil {
+		log.Fatalf("Erro de configuração: %v", err)
+	}
+
+	// Criar repositório de queries
+	queryRepo := queries.NewQueryRepository(db)
+
+	// Refresh das materialized views: agendado (REFRESH_INTERVAL) e sob demanda
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	refresher := refresh.NewRefresher(db)
+	refreshDone := make(chan struct{})
+	go func() {
+		defer close(refreshDone)
+		refresher.Executar(ctx)
+	}()
+
+	// Configurar Fiber
+	app := fiber.New(fiber.Config{
+		AppName: "Hospital CQRS - Query Service (Materialized Views)",
+	})
+
+	// Middlewares
+	app.Use(recover.New())
+	app.Use(logger.New())
+	app.Use(cors.New())
+
+	// Rotas de saúde
+	app.Get("/health", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"status":  "healthy",
+			"service": "query-service",
+		})
+	})
+
+	// Rotas de queries: exigem token; cada view restringe o perfil
+	api := app.Group("/api/v1", autenticador.Middleware())
+
+	// Read-your-writes: com o header X-Consistency-Token (devolvido pelo command service),
+	// a consulta espera a projeção alcançar a escrita por até CONSISTENCY_TIMEOUT.
+	// Se não alcançar, responde 202 com X-Consistency: stale para o cliente tentar de novo.
+	// Enquanto espera, antecipa o refresh em vez de aguardar o próximo agendado
+	consistencia := queries.NewConsistencia(db, refresher.Solicitar)
+	consistencyTimeout := 3 * time.Second
+	if v, err := time.ParseDuration(os.Getenv("CONSISTENCY_TIMEOUT")); err == nil && v > 0 {
+		consistencyTimeout = v
+	}
+
+	api.Use(func(c *fiber.Ctx) error {
+		token := c.Get(domain.ConsistencyTokenHeader)
+		if token == "" {
+			return c.Next()
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), consistencyTimeout)
+		defer cancel()
+
+		alcancou, err := consistencia.Aguardar(ctx, token)
+		if err != nil {
+			if errors.Is(err, queries.ErrTokenInvalido) {
+				return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+			}
+			log.Printf("Erro ao aguardar consistência: %v", err)
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		if !alcancou {
+			c.Set("X-Consistency", "stale")
+			c.Set("Retry-After", "1")
+			return c.Status(202).JSON(fiber.Map{
+				"message":           "Escrita ainda não refletida nas views; tente novamente",
+				"consistency_token": token,
+			})
+		}
+
+		c.Set("X-Consistency", "fresh")
+		return c.Next()
+	})
+
+	// Query Model 1: Farmácia (só o perfil farmácia; não expõe o prontuário)
+	farmacia := api.Group("/farmacia", auth.ExigirPapel(auth.PapelFarmacia, auth.PapelAdmin))
+
+	// Listar prescrições para a farmácia (paginado, com filtros)
+	// ?data_inicio=&data_fim=&paciente_id=&medico_id=&medicamento_id=&ordenacao=&direcao=&cursor=&limite=
+	farmacia.Get("/prescricoes", func(c *fiber.Ctx) error {
+		filtro, err := filtroDaQuery(c)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		pagina, err := queryRepo.GetPrescricoesFarmacia(c.Context(), filtro)
+		if err != nil {
+			if errors.Is(err, queries.ErrFiltroInvalido) {
+				return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+			}
+			log.Printf("Erro ao buscar prescrições da farmácia: %v", err)
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(pagina)
+	})
+
+	// Buscar prescrição específica para a farmácia
+	farmacia.Get("/prescricoes/:id", func(c *fiber.Ctx) error {
+		idStr := c.Params("id")
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "ID inválido"})
+		}
+
+		prescricao, err := queryRepo.GetPrescricaoFarmaciaByID(c.Context(), id)
+		if err != nil {
+			log.Printf("Erro ao buscar prescrição %d: %v", id, err)
+			return c.Status(404).JSON(fiber.Map{"error": "Prescrição não encontrada"})
+		}
+		return c.JSON(prescricao)
+	})
+
+	// Query Model 2: Prontuário do Paciente (médicos; o paciente só vê o próprio)
+	prontuario := api.Group("/prontuario", auth.ExigirPapel(auth.PapelMedico, auth.PapelPaciente, auth.PapelAdmin))
+
+	// Buscar prontuário de um paciente (prescrições paginadas, mesmos filtros da farmácia exceto paciente_id)
+	prontuario.Get("/pacientes/:id", func(c *fiber.Ctx) error {
+		idStr := c.Params("id")
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "ID inválido"})
+		}
+		if !auth.Usuario(c).PodeVerProntuario(id) {
+			return c.Status(403).JSON(fiber.Map{"error": "Acesso negado a este prontuário"})
+		}
+
+		filtro, err := filtroDaQuery(c)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		prontuarioData, err := queryRepo.GetProntuarioPaciente(c.Context(), id, filtro)
+		if err != nil {
+			if errors.Is(err, queries.ErrFiltroInvalido) {
+				return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+			}
+			log.Printf("Erro ao buscar prontuário do paciente %d: %v", id, err)
+			return c.Status(404).JSON(fiber.Map{"error": "Prontuário não encontrado"})
+		}
+		return c.JSON(prontuarioData)
+	})
+
+	// Situação das materialized views
+	views := api.Group("/views")
+
+	// Último refresh de cada view e defasagem em relação ao modelo de escrita
+	views.Get("/status", func(c *fiber.Ctx) error {
+		status, err := refresher.Status(c.Context())
+		if err != nil {
+			log.Printf("Erro ao consultar status das views: %v", err)
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(fiber.Map{
+			"intervalo_refresh": refresher.Intervalo().String(),
+			"views":             status,
+		})
+	})
+
+	// Refresh sob demanda (síncrono); views com refresh em andamento não são repetidas
+	views.Post("/refresh", auth.ExigirPapel(auth.PapelAdmin), func(c *fiber.Ctx) error {
+		resultado := fiber.Map{}
+		falhou := false
+		for _, v := range refresh.Views {
+			err := refresher.Atualizar(c.Context(), v.Nome)
+			switch {
+			case err == nil:
+				resultado[v.Nome] = "atualizada"
+			case errors.Is(err, refresh.ErrRefreshEmAndamento):
+				resultado[v.Nome] = "refresh já em andamento"
+			default:
+				log.Printf("Erro no refresh sob demanda: %v", err)
+				resultado[v.Nome] = err.Error()
+				falhou = true
+			}
+		}
+
+		status, err := refresher.Status(c.Context())
+		if err != nil {
+			log.Printf("Erro ao consultar status das views: %v", err)
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		code := 200
+		if falhou {
+			code = 500
+		}
+		return c.Status(code).JSON(fiber.Map{
+			"refresh": resultado,
+			"views":   status,
+		})
+	})
+
+	// Iniciar servidor
+	port := os.Getenv("SERVICE_PORT")
+	if port == "" {
+		port = "3001"
+	}
+
+	// Graceful shutdown
+	go func() {
+		if err := app.Listen(":" + port); err != nil {
+			log.Fatalf("Erro ao iniciar servidor: %v", err)
+		}
+	}()
+
+	log.Printf("Query Service rodando na porta %s", port)
+
+	// Aguardar sinal de interrupção
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	<-quit
+
+	log.Println("Encerrando Query Service...")
+	if err := app.ShutdownWithContext(context.Background()); err != nil {
+		log.Printf("Erro ao encerrar servidor: %v", err)
+	}
+	cancel()
+	<-refreshDone
+}
+
+// filtroDaQuery lê filtros, ordenação e paginação da query string
+func filtroDaQuery(c *fiber.Ctx) (queries.FiltroPrescricoes, error) {
+	filtro := queries.FiltroPrescricoes{
+		Ordenacao: c.Query("ordenacao"),
+		Direcao:   c.Query("direcao"),
+		Cursor:    c.Query("cursor"),
+	}
+
+	var err error
+	if filtro.DataInicio, err = queries.ParseData(c.Query("data_inicio"), false); err != nil {
+		return filtro, err
+	}
+	if filtro.DataFim, err = queries.ParseData(c.Query("data_fim"), true); err != nil {
+		return filtro, err
+	}
+
+	inteiros := map[string]*int{
+		"paciente_id":    &filtro.PacienteID,
+		"medico_id":      &filtro.MedicoID,
+		"medicamento_id": &filtro.MedicamentoID,
+		"limite":         &filtro.Limite,
+	}
+	for nome, destino := range inteiros {
+		valor := c.Query(nome)
+		if valor == "" {
+			continue
+		}
+		n, err := strconv.Atoi(valor)
+		if err != nil || n <= 0 {
+			return filtro, fmt.Errorf("%s deve ser um inteiro positivo", nome)
+		}
+		*destino = n
+	}
+
+	return filtro, nil
+}
@@ -0,0 +1,226 @@
+-- =========================================
+-- HOSPITAL CQRS - DATABASE INITIALIZATION
+-- =========================================
+
+-- =========================================
+-- COMMAND MODEL (Write Side - Normalized)
+-- =========================================
+
+-- Tabela de Médicos
+CREATE TABLE IF NOT EXISTS Medicos (
+    id SERIAL PRIMARY KEY,
+    nome VARCHAR(255) NOT NULL,
+    especialidade VARCHAR(255) NOT NULL,
+    crm VARCHAR(255) NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Tabela de Pacientes
+CREATE TABLE IF NOT EXISTS Pacientes (
+    id SERIAL PRIMARY KEY,
+    nome VARCHAR(255) NOT NULL,
+    data_nascimento DATE NOT NULL,
+    endereco VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Tabela de Medicamentos
+CREATE TABLE IF NOT EXISTS Medicamentos (
+    id SERIAL PRIMARY KEY,
+    nome VARCHAR(255) NOT NULL,
+    descricao TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Tabela de Prescrições (Modelo de Comando)
+CREATE TABLE IF NOT EXISTS Prescricoes (
+    id SERIAL PRIMARY KEY,
+    id_medico INT NOT NULL,
+    id_paciente INT NOT NULL,
+    data_prescricao TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    status VARCHAR(20) NOT NULL DEFAULT 'ATIVA',  -- ATIVA | CANCELADA
+    motivo_cancelamento TEXT NULL,
+    cancelada_em TIMESTAMP NULL,
+    versao INT NOT NULL DEFAULT 1,                -- incrementada a cada comando (token de consistência)
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, -- última escrita (detecção de views desatualizadas)
+    FOREIGN KEY (id_medico) REFERENCES Medicos(id),
+    FOREIGN KEY (id_paciente) REFERENCES Pacientes(id)
+);
+
+-- Tabela de Relacionamento Prescrição-Medicamentos
+CREATE TABLE IF NOT EXISTS Prescricao_Medicamentos (
+    id SERIAL PRIMARY KEY,
+    id_prescricao INT NOT NULL,
+    id_medicamento INT NOT NULL,
+    horario VARCHAR(50) NOT NULL,
+    dosagem VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (id_prescricao) REFERENCES Prescricoes(id) ON DELETE CASCADE,
+    FOREIGN KEY (id_medicamento) REFERENCES Medicamentos(id)
+);
+
+-- Índices para otimizar buscas no modelo de comando
+CREATE INDEX idx_prescricoes_medico ON Prescricoes(id_medico);
+CREATE INDEX idx_prescricoes_paciente ON Prescricoes(id_paciente);
+CREATE INDEX idx_prescricao_medicamentos_prescricao ON Prescricao_Medicamentos(id_prescricao);
+-- Um medicamento por prescrição: é a chave única das materialized views
+CREATE UNIQUE INDEX ux_prescricao_medicamentos ON Prescricao_Medicamentos(id_prescricao, id_medicamento);
+
+-- Idempotency-Key de POST /prescricoes: a mesma chave do mesmo usuário devolve a resposta
+-- original em vez de criar a prescrição de novo (chaves valem por 24h)
+CREATE TABLE IF NOT EXISTS Idempotency_Keys (
+    usuario VARCHAR(255) NOT NULL,             -- subject do JWT: cada usuário tem suas chaves
+    chave VARCHAR(255) NOT NULL,
+    hash_requisicao CHAR(64) NOT NULL,         -- SHA-256 de método, rota e corpo
+    reserva_id VARCHAR(36) NOT NULL,           -- Requisição dona da reserva (muda se for retomada)
+    status_resposta INT NULL,                  -- NULL enquanto a requisição está em processamento
+    corpo_resposta BYTEA NULL,
+    cabecalhos_resposta JSONB NULL,
+    criada_em TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    reservada_em TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (usuario, chave)
+);
+
+-- updated_at indexado: o status de refresh compara a última escrita de cada tabela
+-- com o início do último refresh
+CREATE INDEX idx_medicos_updated_at ON Medicos(updated_at);
+CREATE INDEX idx_pacientes_updated_at ON Pacientes(updated_at);
+CREATE INDEX idx_medicamentos_updated_at ON Medicamentos(updated_at);
+CREATE INDEX idx_prescricoes_updated_at ON Prescricoes(updated_at);
+CREATE INDEX idx_prescricao_medicamentos_updated_at ON Prescricao_Medicamentos(updated_at);
+
+-- =========================================
+-- QUERY MODELS (Read Side - Materialized Views)
+-- =========================================
+-- Nesta variante não há event handler: o read side é calculado pelo próprio Postgres a
+-- partir do modelo de escrita. O query service executa REFRESH MATERIALIZED VIEW
+-- CONCURRENTLY periodicamente e sob demanda; entre dois refreshes as views ficam
+-- defasadas. CONCURRENTLY não bloqueia leituras e exige um índice único em cada view.
+
+-- Query Model 1: View de Farmácia (só prescrições ativas)
+CREATE MATERIALIZED VIEW IF NOT EXISTS mv_farmacia AS
+SELECT
+    p.id AS id_prescricao,
+    p.data_prescricao,
+
+    -- Dados do Paciente
+    pa.id AS paciente_id,
+    pa.nome AS paciente_nome,
+    pa.data_nascimento AS paciente_data_nascimento,
+
+    -- Dados do Medicamento
+    m.id AS medicamento_id,
+    m.nome AS medicamento_nome,
+    COALESCE(m.descricao, '') AS medicamento_descricao,
+    pm.horario,
+    pm.dosagem
+FROM Prescricoes p
+JOIN Pacientes pa ON pa.id = p.id_paciente
+JOIN Prescricao_Medicamentos pm ON pm.id_prescricao = p.id
+JOIN Medicamentos m ON m.id = pm.id_medicamento
+WHERE p.status = 'ATIVA'
+WITH DATA;
+
+CREATE UNIQUE INDEX ux_mv_farmacia_prescricao_medicamento ON mv_farmacia(id_prescricao, medicamento_id);
+CREATE INDEX idx_mv_farmacia_paciente ON mv_farmacia(paciente_id);
+CREATE INDEX idx_mv_farmacia_medicamento ON mv_farmacia(medicamento_id);
+CREATE INDEX idx_mv_farmacia_data ON mv_farmacia(data_prescricao);
+
+-- Query Model 2: View de Prontuário do Paciente (histórico completo, inclusive canceladas)
+CREATE MATERIALIZED VIEW IF NOT EXISTS mv_prontuario_paciente AS
+SELECT
+    p.id AS id_prescricao,
+    p.data_prescricao,
+    p.versao,                                  -- versão refletida (token de consistência)
+
+    -- Dados do Paciente
+    pa.id AS paciente_id,
+    pa.nome AS paciente_nome,
+    pa.data_nascimento AS paciente_data_nascimento,
+    COALESCE(pa.endereco, '') AS paciente_endereco,
+
+    -- Dados do Médico
+    me.id AS medico_id,
+    me.nome AS medico_nome,
+    me.especialidade AS medico_especialidade,
+    me.crm AS medico_crm,
+
+    -- Situação da prescrição (ATIVA | CANCELADA)
+    p.status,
+
+    -- Dados do Medicamento
+    m.id AS medicamento_id,
+    m.nome AS medicamento_nome,
+    COALESCE(m.descricao, '') AS medicamento_descricao,
+    pm.horario,
+    pm.dosagem
+FROM Prescricoes p
+JOIN Pacientes pa ON pa.id = p.id_paciente
+JOIN Medicos me ON me.id = p.id_medico
+JOIN Prescricao_Medicamentos pm ON pm.id_prescricao = p.id
+JOIN Medicamentos m ON m.id = pm.id_medicamento
+WITH DATA;
+
+CREATE UNIQUE INDEX ux_mv_prontuario_prescricao_medicamento ON mv_prontuario_paciente(id_prescricao, medicamento_id);
+CREATE INDEX idx_mv_prontuario_paciente ON mv_prontuario_paciente(paciente_id);
+CREATE INDEX idx_mv_prontuario_medico ON mv_prontuario_paciente(medico_id);
+CREATE INDEX idx_mv_prontuario_data ON mv_prontuario_paciente(data_prescricao);
+
+-- Situação do último refresh de cada materialized view
+-- dados_ate é o corte dos dados refletidos: o início da transação mais antiga em andamento
+-- quando o refresh começou (escritas com updated_at >= dados_ate podem não estar na view)
+CREATE TABLE IF NOT EXISTS Refresh_Views (
+    view_name VARCHAR(100) PRIMARY KEY,
+    dados_ate TIMESTAMP NOT NULL,
+    concluido_em TIMESTAMP NOT NULL,
+    duracao_ms INT NOT NULL DEFAULT 0,
+    ultimo_erro TEXT NULL,                     -- erro da última tentativa (NULL se concluiu)
+    erro_em TIMESTAMP NULL
+);
+
+-- As views nascem populadas (WITH DATA) junto com o banco
+INSERT INTO Refresh_Views (view_name, dados_ate, concluido_em) VALUES
+('mv_farmacia', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+('mv_prontuario_paciente', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+ON CONFLICT (view_name) DO NOTHING;
+
+-- =========================================
+-- DADOS DE EXEMPLO (SEED)
+-- =========================================
+
+-- Inserir médicos de exemplo
+INSERT INTO Medicos (nome, especialidade, crm) VALUES
+('Dr. João Silva', 'Cardiologia', 'CRM-SP-123456'),
+('Dra. Maria Santos', 'Clínica Geral', 'CRM-RJ-789012'),
+('Dr. Pedro Oliveira', 'Pediatria', 'CRM-MG-345678'),
+('Dra. Ana Costa', 'Dermatologia', 'CRM-SP-901234'),
+('Dr. Carlos Mendes', 'Ortopedia', 'CRM-RS-567890')
+ON CONFLICT (crm) DO NOTHING;
+
+-- Inserir pacientes de exemplo
+INSERT INTO Pacientes (nome, data_nascimento, endereco) VALUES
+('José da Silva', '1980-05-15', 'Rua das Flores, 123 - São Paulo, SP'),
+('Maria Oliveira', '1992-08-22', 'Av. Paulista, 1000 - São Paulo, SP'),
+('João Santos', '1975-03-10', 'Rua XV de Novembro, 456 - Rio de Janeiro, RJ'),
+('Ana Paula', '2010-12-05', 'Rua das Acácias, 789 - Belo Horizonte, MG'),
+('Carlos Eduardo', '1988-07-18', 'Av. Ipiranga, 321 - Porto Alegre, RS'),
+('Fernanda Lima', '1995-11-30', 'Rua Aurora, 555 - São Paulo, SP'),
+('Roberto Costa', '1968-09-25', 'Rua da Consolação, 888 - São Paulo, SP');
+
+-- Inserir medicamentos de exemplo
+INSERT INTO Medicamentos (nome, descricao) VALUES
+('Paracetamol 500mg', 'Analgésico e antipirético'),
+('Amoxicilina 500mg', 'Antibiótico de amplo espectro'),
+('Omeprazol 20mg', 'Inibidor de bomba de prótons para tratamento de úlcera'),
+('Dipirona 500mg', 'Analgésico e antitérmico'),
+('Ibuprofeno 600mg', 'Anti-inflamatório não esteroidal'),
+('Losartana 50mg', 'Anti-hipertensivo'),
+('Metformina 850mg', 'Antidiabético oral'),
+('Sinvastatina 20mg', 'Redutor de colesterol'),
+('Enalapril 10mg', 'Anti-hipertensivo inibidor da ECA'),
+('Captopril 25mg', 'Anti-hipertensivo');
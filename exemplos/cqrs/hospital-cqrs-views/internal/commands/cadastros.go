@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+
+	"hospital-cqrs/internal/domain"
+)
+
+// =========================================
+// COMANDOS DE CADASTRO (DADOS DE REFERÊNCIA)
+// =========================================
+// Médicos, pacientes e medicamentos aparecem desnormalizados nas views. Basta gravar a
+// alteração: o próximo refresh das materialized views recalcula as linhas a partir dos cadastros.
+
+var (
+	// ErrMedicoNaoEncontrado indica que o médico não existe no modelo de escrita
+	ErrMedicoNaoEncontrado = errors.New("médico não encontrado")
+	// ErrPacienteNaoEncontrado indica que o paciente não existe no modelo de escrita
+	ErrPacienteNaoEncontrado = errors.New("paciente não encontrado")
+	// ErrMedicamentoNaoEncontrado indica que o medicamento não existe no modelo de escrita
+	ErrMedicamentoNaoEncontrado = errors.New("medicamento não encontrado")
+	// ErrDataNascimentoInvalida indica data de nascimento fora do formato AAAA-MM-DD
+	ErrDataNascimentoInvalida = errors.New("data de nascimento inválida (esperado AAAA-MM-DD)")
+	// ErrCRMDuplicado indica que o CRM já pertence a outro médico
+	ErrCRMDuplicado = errors.New("CRM já cadastrado para outro médico")
+)
+
+// AtualizarMedico processa o comando de alterar o cadastro de um médico
+func (h *PrescricaoHandler) AtualizarMedico(ctx context.Context, id int, dto domain.AtualizarMedicoDTO) (*domain.Medico, error) {
+	medico, err := h.repo.AtualizarMedico(ctx, id, dto)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Médico atualizado com sucesso (ID %d) - visível após o próximo refresh", medico.ID)
+	return medico, nil
+}
+
+// AtualizarPaciente processa o comando de alterar o cadastro de um paciente
+func (h *PrescricaoHandler) AtualizarPaciente(ctx context.Context, id int, dto domain.AtualizarPacienteDTO) (*domain.Paciente, error) {
+	paciente, err := h.repo.AtualizarPaciente(ctx, id, dto)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Paciente atualizado com sucesso (ID %d) - visível após o próximo refresh", paciente.ID)
+	return paciente, nil
+}
+
+// AtualizarMedicamento processa o comando de alterar o cadastro de um medicamento
+func (h *PrescricaoHandler) AtualizarMedicamento(ctx context.Context, id int, dto domain.AtualizarMedicamentoDTO) (*domain.Medicamento, error) {
+	medicamento, err := h.repo.AtualizarMedicamento(ctx, id, dto)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Medicamento atualizado com sucesso (ID %d) - visível após o próximo refresh", medicamento.ID)
+	return medicamento, nil
+}
+
+// AtualizarMedico grava o novo cadastro do médico
+func (r *PrescricaoRepository) AtualizarMedico(ctx context.Context, id int, dto domain.AtualizarMedicoDTO) (*domain.Medico, error) {
+	var medico domain.Medico
+	query := `
+		UPDATE Medicos
+		SET nome = $1, especialidade = $2, crm = $3, updated_at = NOW()
+		WHERE id = $4
+		RETURNING id, nome, especialidade, crm, created_at, updated_at
+	`
+	err := r.db.QueryRowContext(ctx, query, dto.Nome, dto.Especialidade, dto.CRM, id).
+		Scan(&medico.ID, &medico.Nome, &medico.Especialidade, &medico.CRM, &medico.CreatedAt, &medico.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrMedicoNaoEncontrado
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return nil, ErrCRMDuplicado
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao atualizar médico: %w", err)
+	}
+	return &medico, nil
+}
+
+// AtualizarPaciente grava o novo cadastro do paciente
+func (r *PrescricaoRepository) AtualizarPaciente(ctx context.Context, id int, dto domain.AtualizarPacienteDTO) (*domain.Paciente, error) {
+	dataNascimento, err := time.Parse("2006-01-02", dto.DataNascimento)
+	if err != nil {
+		return nil, ErrDataNascimentoInvalida
+	}
+
+	var paciente domain.Paciente
+	query := `
+		UPDATE Pacientes
+		SET nome = $1, data_nascimento = $2, endereco = $3, updated_at = NOW()
+		WHERE id = $4
+		RETURNING id, nome, data_nascimento, COALESCE(endereco, ''), created_at, updated_at
+	`
+	err = r.db.QueryRowContext(ctx, query, dto.Nome, dataNascimento, dto.Endereco, id).
+		Scan(&paciente.ID, &paciente.Nome, &paciente.DataNascimento, &paciente.Endereco, &paciente.CreatedAt, &paciente.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPacienteNaoEncontrado
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao atualizar paciente: %w", err)
+	}
+	return &paciente, nil
+}
+
+// AtualizarMedicamento grava o novo cadastro do medicamento
+func (r *PrescricaoRepository) AtualizarMedicamento(ctx context.Context, id int, dto domain.AtualizarMedicamentoDTO) (*domain.Medicamento, error) {
+	var medicamento domain.Medicamento
+	query := `
+		UPDATE Medicamentos
+		SET nome = $1, descricao = $2, updated_at = NOW()
+		WHERE id = $3
+		RETURNING id, nome, COALESCE(descricao, ''), created_at, updated_at
+	`
+	err := r.db.QueryRowContext(ctx, query, dto.Nome, dto.Descricao, id).
+		Scan(&medicamento.ID, &medicamento.Nome, &medicamento.Descricao, &medicamento.CreatedAt, &medicamento.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrMedicamentoNaoEncontrado
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao atualizar medicamento: %w", err)
+	}
+	return &medicamento, nil
+}
@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"hospital-cqrs/internal/domain"
+)
+
+// PrescricaoHandler gerencia os comandos de prescrição
+type PrescricaoHandler struct {
+	repo *PrescricaoRepository
+}
+
+// NewPrescricaoHandler cria um novo handler de prescrições
+// Materialized views: não há eventos - o read side é recalculado a partir destas tabelas
+func NewPrescricaoHandler(db *sql.DB) *PrescricaoHandler {
+	return &PrescricaoHandler{
+		repo: NewPrescricaoRepository(db),
+	}
+}
+
+// ConclusaoCriacao roda na transação que cria a prescrição, com o resultado que o comando vai
+// devolver; um erro desfaz a criação. Grava o que precisa ser confirmado junto com a
+// prescrição (a resposta da Idempotency-Key)
+type ConclusaoCriacao func(ctx context.Context, tx *sql.Tx, prescricao *domain.Prescricao, token string) error
+
+// CriarPrescricao processa o comando de criar prescrição
+// Apenas persiste no banco - as materialized views refletem a mudança no próximo refresh
+// O token retornado identifica a versão gravada: o query service o usa para read-your-writes
+// concluir (opcional) roda na transação da criação
+func (h *PrescricaoHandler) CriarPrescricao(ctx context.Context, dto domain.CriarPrescricaoDTO, concluir ConclusaoCriacao) (*domain.Prescricao, string, error) {
+	if err := dto.Validar(); err != nil {
+		return nil, "", err
+	}
+
+	// Validar se médico, paciente e medicamentos existem
+	if err := h.validarReferencias(ctx, dto); err != nil {
+		return nil, "", err
+	}
+
+	var naTransacao conclusaoTransacao
+	if concluir != nil {
+		naTransacao = func(ctx context.Context, tx *sql.Tx, prescricao *domain.Prescricao) error {
+			return concluir(ctx, tx, prescricao, domain.TokenConsistencia(prescricao.ID, prescricao.Versao))
+		}
+	}
+
+	// Criar prescrição no banco de dados
+	prescricao, _, err := h.repo.CriarPrescricao(ctx, dto, naTransacao)
+	if err != nil {
+		return nil, "", fmt.Errorf("erro ao criar prescrição: %w", err)
+	}
+
+	log.Printf("Prescrição criada com sucesso: ID %d", prescricao.ID)
+	return prescricao, domain.TokenConsistencia(prescricao.ID, prescricao.Versao), nil
+}
+
+// AtualizarPrescricao processa o comando de alterar dosagem/horário dos medicamentos
+func (h *PrescricaoHandler) AtualizarPrescricao(ctx context.Context, idPrescricao int, dto domain.AtualizarPrescricaoDTO) ([]domain.PrescricaoMedicamento, string, error) {
+	if err := dto.Validar(); err != nil {
+		return nil, "", err
+	}
+
+	medicamentos, versao, err := h.repo.AtualizarPrescricao(ctx, idPrescricao, dto)
+	if err != nil {
+		return nil, "", fmt.Errorf("erro ao atualizar prescrição: %w", err)
+	}
+
+	log.Printf("Prescrição atualizada com sucesso: ID %d", idPrescricao)
+	return medicamentos, domain.TokenConsistencia(idPrescricao, versao), nil
+}
+
+// CancelarPrescricao processa o comando de cancelar prescrição
+func (h *PrescricaoHandler) CancelarPrescricao(ctx context.Context, idPrescricao int, dto domain.CancelarPrescricaoDTO) (*domain.Prescricao, string, error) {
+	if err := dto.Validar(); err != nil {
+		return nil, "", err
+	}
+
+	prescricao, err := h.repo.CancelarPrescricao(ctx, idPrescricao, dto.Motivo)
+	if err != nil {
+		return nil, "", fmt.Errorf("erro ao cancelar prescrição: %w", err)
+	}
+
+	log.Printf("Prescrição cancelada com sucesso: ID %d", prescricao.ID)
+	return prescricao, domain.TokenConsistencia(prescricao.ID, prescricao.Versao), nil
+}
+
+// ListMedicos retorna a lista de médicos
+func (h *PrescricaoHandler) ListMedicos(ctx context.Context) ([]domain.Medico, error) {
+	return h.repo.ListMedicos(ctx)
+}
+
+// ListPacientes retorna a lista de pacientes
+func (h *PrescricaoHandler) ListPacientes(ctx context.Context) ([]domain.Paciente, error) {
+	return h.repo.ListPacientes(ctx)
+}
+
+// ListMedicamentos retorna a lista de medicamentos
+func (h *PrescricaoHandler) ListMedicamentos(ctx context.Context) ([]domain.Medicamento, error) {
+	return h.repo.ListMedicamentos(ctx)
+}
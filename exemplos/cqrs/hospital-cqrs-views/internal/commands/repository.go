@@ -0,0 +1,310 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"hospital-cqrs/internal/domain"
+)
+
+var (
+	// ErrPrescricaoNaoEncontrada indica que a prescrição não existe no modelo de escrita
+	ErrPrescricaoNaoEncontrada = errors.New("prescrição não encontrada")
+	// ErrPrescricaoCancelada indica que a prescrição já foi cancelada e não aceita alterações
+	ErrPrescricaoCancelada = errors.New("prescrição cancelada")
+	// ErrMedicamentoNaoPrescrito indica que o medicamento não faz parte da prescrição
+	ErrMedicamentoNaoPrescrito = errors.New("medicamento não faz parte da prescrição")
+)
+
+// PrescricaoRepository gerencia a persistência de prescrições (Write Side)
+type PrescricaoRepository struct {
+	db *sql.DB
+}
+
+// NewPrescricaoRepository cria um novo repositório de prescrições
+func NewPrescricaoRepository(db *sql.DB) *PrescricaoRepository {
+	return &PrescricaoRepository{db: db}
+}
+
+// conclusaoTransacao é a ConclusaoCriacao vista pelo repositório, antes do token
+type conclusaoTransacao func(ctx context.Context, tx *sql.Tx, prescricao *domain.Prescricao) error
+
+// CriarPrescricao cria uma nova prescrição no banco de dados
+// concluir (se houver) roda por último, antes do commit
+func (r *PrescricaoRepository) CriarPrescricao(ctx context.Context, dto domain.CriarPrescricaoDTO, concluir conclusaoTransacao) (*domain.Prescricao, []domain.PrescricaoMedicamento, error) {
+	// Iniciar transação
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Inserir prescrição
+	var prescricao domain.Prescricao
+	query := `
+		INSERT INTO Prescricoes (id_medico, id_paciente, data_prescricao)
+		VALUES ($1, $2, $3)
+		RETURNING id, id_medico, id_paciente, data_prescricao, status, versao, created_at
+	`
+	err = tx.QueryRowContext(ctx, query, dto.IDMedico, dto.IDPaciente, time.Now()).
+		Scan(&prescricao.ID, &prescricao.IDMedico, &prescricao.IDPaciente,
+			&prescricao.DataPrescricao, &prescricao.Status, &prescricao.Versao, &prescricao.CreatedAt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao inserir prescrição: %w", err)
+	}
+
+	// Inserir medicamentos da prescrição
+	var prescricaoMedicamentos []domain.PrescricaoMedicamento
+	for _, med := range dto.Medicamentos {
+		var pm domain.PrescricaoMedicamento
+		queryMed := `
+			INSERT INTO Prescricao_Medicamentos (id_prescricao, id_medicamento, horario, dosagem)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, id_prescricao, id_medicamento, horario, dosagem, created_at, updated_at
+		`
+		err = tx.QueryRowContext(ctx, queryMed, prescricao.ID, med.IDMedicamento, med.Horario, med.Dosagem).
+			Scan(&pm.ID, &pm.IDPrescricao, &pm.IDMedicamento, &pm.Horario, &pm.Dosagem, &pm.CreatedAt, &pm.UpdatedAt)
+		if err != nil {
+			return nil, nil, fmt.Errorf("erro ao inserir medicamento da prescrição: %w", err)
+		}
+		prescricaoMedicamentos = append(prescricaoMedicamentos, pm)
+	}
+
+	if concluir != nil {
+		if err := concluir(ctx, tx, &prescricao); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Commit da transação
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+
+	return &prescricao, prescricaoMedicamentos, nil
+}
+
+// AtualizarPrescricao altera dosagem e horário dos medicamentos de uma prescrição ativa
+// Retorna também a nova versão da prescrição, usada como token de consistência
+func (r *PrescricaoRepository) AtualizarPrescricao(ctx context.Context, idPrescricao int, dto domain.AtualizarPrescricaoDTO) ([]domain.PrescricaoMedicamento, int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Bloquear a prescrição para serializar comandos concorrentes
+	if err := travarPrescricaoAtiva(ctx, tx, idPrescricao); err != nil {
+		return nil, 0, err
+	}
+
+	medicamentos, err := atualizarMedicamentos(ctx, tx, idPrescricao, dto.Medicamentos)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	versao, err := incrementarVersao(ctx, tx, idPrescricao)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, 0, fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+
+	return medicamentos, versao, nil
+}
+
+// CancelarPrescricao marca uma prescrição ativa como cancelada
+func (r *PrescricaoRepository) CancelarPrescricao(ctx context.Context, idPrescricao int, motivo string) (*domain.Prescricao, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := travarPrescricaoAtiva(ctx, tx, idPrescricao); err != nil {
+		return nil, err
+	}
+
+	prescricao, err := cancelarPrescricao(ctx, tx, idPrescricao, motivo)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+
+	return prescricao, nil
+}
+
+// travarPrescricaoAtiva obtém lock da prescrição e garante que ela ainda está ativa
+func travarPrescricaoAtiva(ctx context.Context, tx *sql.Tx, idPrescricao int) error {
+	var status string
+	query := `SELECT status FROM Prescricoes WHERE id = $1 FOR UPDATE`
+	err := tx.QueryRowContext(ctx, query, idPrescricao).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrPrescricaoNaoEncontrada
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao buscar prescrição: %w", err)
+	}
+
+	if status != domain.StatusPrescricaoAtiva {
+		return ErrPrescricaoCancelada
+	}
+	return nil
+}
+
+// atualizarMedicamentos grava a nova dosagem/horário de cada medicamento informado
+func atualizarMedicamentos(ctx context.Context, tx *sql.Tx, idPrescricao int, medicamentos []domain.MedicamentoPrescrito) ([]domain.PrescricaoMedicamento, error) {
+	query := `
+		UPDATE Prescricao_Medicamentos
+		SET horario = $1, dosagem = $2, updated_at = NOW()
+		WHERE id_prescricao = $3 AND id_medicamento = $4
+		RETURNING id, id_prescricao, id_medicamento, horario, dosagem, created_at, updated_at
+	`
+
+	var atualizados []domain.PrescricaoMedicamento
+	for _, med := range medicamentos {
+		var pm domain.PrescricaoMedicamento
+		err := tx.QueryRowContext(ctx, query, med.Horario, med.Dosagem, idPrescricao, med.IDMedicamento).
+			Scan(&pm.ID, &pm.IDPrescricao, &pm.IDMedicamento, &pm.Horario, &pm.Dosagem, &pm.CreatedAt, &pm.UpdatedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: medicamento %d", ErrMedicamentoNaoPrescrito, med.IDMedicamento)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao atualizar medicamento da prescrição: %w", err)
+		}
+		atualizados = append(atualizados, pm)
+	}
+
+	return atualizados, nil
+}
+
+// incrementarVersao avança a versão da prescrição na mesma transação do comando
+// A versão entra nas materialized views: o token de consistência compara a versão do
+// comando com a refletida no último refresh, mesmo quando só os medicamentos mudaram
+func incrementarVersao(ctx context.Context, tx *sql.Tx, idPrescricao int) (int, error) {
+	var versao int
+	query := `UPDATE Prescricoes SET versao = versao + 1, updated_at = NOW() WHERE id = $1 RETURNING versao`
+	if err := tx.QueryRowContext(ctx, query, idPrescricao).Scan(&versao); err != nil {
+		return 0, fmt.Errorf("erro ao incrementar versão da prescrição: %w", err)
+	}
+	return versao, nil
+}
+
+// cancelarPrescricao grava o cancelamento no modelo de escrita
+func cancelarPrescricao(ctx context.Context, tx *sql.Tx, idPrescricao int, motivo string) (*domain.Prescricao, error) {
+	var prescricao domain.Prescricao
+	query := `
+		UPDATE Prescricoes
+		SET status = $1, motivo_cancelamento = $2, cancelada_em = NOW(), versao = versao + 1, updated_at = NOW()
+		WHERE id = $3
+		RETURNING id, id_medico, id_paciente, data_prescricao, status, motivo_cancelamento, cancelada_em, versao, created_at
+	`
+	err := tx.QueryRowContext(ctx, query, domain.StatusPrescricaoCancelada, motivo, idPrescricao).
+		Scan(&prescricao.ID, &prescricao.IDMedico, &prescricao.IDPaciente, &prescricao.DataPrescricao,
+			&prescricao.Status, &prescricao.MotivoCancelamento, &prescricao.CanceladaEm, &prescricao.Versao, &prescricao.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao cancelar prescrição: %w", err)
+	}
+	return &prescricao, nil
+}
+
+// GetMedicoByID busca um médico por ID
+func (r *PrescricaoRepository) GetMedicoByID(ctx context.Context, id int) (*domain.Medico, error) {
+	var medico domain.Medico
+	query := `SELECT id, nome, especialidade, crm FROM Medicos WHERE id = $1`
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&medico.ID, &medico.Nome, &medico.Especialidade, &medico.CRM)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar médico: %w", err)
+	}
+	return &medico, nil
+}
+
+// GetPacienteByID busca um paciente por ID
+func (r *PrescricaoRepository) GetPacienteByID(ctx context.Context, id int) (*domain.Paciente, error) {
+	var paciente domain.Paciente
+	query := `SELECT id, nome, data_nascimento, endereco FROM Pacientes WHERE id = $1`
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&paciente.ID, &paciente.Nome, &paciente.DataNascimento, &paciente.Endereco)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar paciente: %w", err)
+	}
+	return &paciente, nil
+}
+
+// GetMedicamentoByID busca um medicamento por ID
+func (r *PrescricaoRepository) GetMedicamentoByID(ctx context.Context, id int) (*domain.Medicamento, error) {
+	var medicamento domain.Medicamento
+	query := `SELECT id, nome, descricao FROM Medicamentos WHERE id = $1`
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&medicamento.ID, &medicamento.Nome, &medicamento.Descricao)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar medicamento: %w", err)
+	}
+	return &medicamento, nil
+}
+
+// ListMedicos lista todos os médicos
+func (r *PrescricaoRepository) ListMedicos(ctx context.Context) ([]domain.Medico, error) {
+	query := `SELECT id, nome, especialidade, crm FROM Medicos ORDER BY nome`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar médicos: %w", err)
+	}
+	defer rows.Close()
+
+	var medicos []domain.Medico
+	for rows.Next() {
+		var m domain.Medico
+		if err := rows.Scan(&m.ID, &m.Nome, &m.Especialidade, &m.CRM); err != nil {
+			return nil, fmt.Errorf("erro ao scanear médico: %w", err)
+		}
+		medicos = append(medicos, m)
+	}
+	return medicos, nil
+}
+
+// ListPacientes lista todos os pacientes
+func (r *PrescricaoRepository) ListPacientes(ctx context.Context) ([]domain.Paciente, error) {
+	query := `SELECT id, nome, data_nascimento, endereco FROM Pacientes ORDER BY nome`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar pacientes: %w", err)
+	}
+	defer rows.Close()
+
+	var pacientes []domain.Paciente
+	for rows.Next() {
+		var p domain.Paciente
+		if err := rows.Scan(&p.ID, &p.Nome, &p.DataNascimento, &p.Endereco); err != nil {
+			return nil, fmt.Errorf("erro ao scanear paciente: %w", err)
+		}
+		pacientes = append(pacientes, p)
+	}
+	return pacientes, nil
+}
+
+// ListMedicamentos lista todos os medicamentos
+func (r *PrescricaoRepository) ListMedicamentos(ctx context.Context) ([]domain.Medicamento, error) {
+	query := `SELECT id, nome, descricao FROM Medicamentos ORDER BY nome`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar medicamentos: %w", err)
+	}
+	defer rows.Close()
+
+	var medicamentos []domain.Medicamento
+	for rows.Next() {
+		var m domain.Medicamento
+		if err := rows.Scan(&m.ID, &m.Nome, &m.Descricao); err != nil {
+			return nil, fmt.Errorf("erro ao scanear medicamento: %w", err)
+		}
+		medicamentos = append(medicamentos, m)
+	}
+	return medicamentos, nil
+}
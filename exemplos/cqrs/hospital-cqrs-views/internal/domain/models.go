@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // =========================================
 // COMMAND MODELS (Write Side)
@@ -35,13 +38,23 @@ type Medicamento struct {
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// Status possíveis de uma prescrição
+const (
+	StatusPrescricaoAtiva     = "ATIVA"
+	StatusPrescricaoCancelada = "CANCELADA"
+)
+
 // Prescricao representa uma prescrição médica
 type Prescricao struct {
-	ID             int       `json:"id" db:"id"`
-	IDMedico       int       `json:"id_medico" db:"id_medico"`
-	IDPaciente     int       `json:"id_paciente" db:"id_paciente"`
-	DataPrescricao time.Time `json:"data_prescricao" db:"data_prescricao"`
-	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	ID                 int        `json:"id" db:"id"`
+	IDMedico           int        `json:"id_medico" db:"id_medico"`
+	IDPaciente         int        `json:"id_paciente" db:"id_paciente"`
+	DataPrescricao     time.Time  `json:"data_prescricao" db:"data_prescricao"`
+	Status             string     `json:"status" db:"status"`
+	MotivoCancelamento *string    `json:"motivo_cancelamento,omitempty" db:"motivo_cancelamento"`
+	CanceladaEm        *time.Time `json:"cancelada_em,omitempty" db:"cancelada_em"`
+	Versao             int        `json:"versao" db:"versao"`
+	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
 }
 
 // PrescricaoMedicamento representa a relação entre prescrição e medicamento
@@ -52,6 +65,7 @@ type PrescricaoMedicamento struct {
 	Horario       string    `json:"horario" db:"horario"`
 	Dosagem       string    `json:"dosagem" db:"dosagem"`
 	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
 }
 
 // =========================================
@@ -72,6 +86,36 @@ type CriarPrescricaoDTO struct {
 	Medicamentos []MedicamentoPrescrito `json:"medicamentos" validate:"required,min=1"`
 }
 
+// AtualizarPrescricaoDTO é o DTO para alterar dosagem e horário dos medicamentos de uma prescrição
+type AtualizarPrescricaoDTO struct {
+	Medicamentos []MedicamentoPrescrito `json:"medicamentos" validate:"required,min=1"`
+}
+
+// CancelarPrescricaoDTO é o DTO para cancelar uma prescrição
+type CancelarPrescricaoDTO struct {
+	Motivo string `json:"motivo" validate:"required"`
+}
+
+// AtualizarMedicoDTO é o DTO para alterar o cadastro de um médico
+type AtualizarMedicoDTO struct {
+	Nome          string `json:"nome" validate:"required"`
+	Especialidade string `json:"especialidade" validate:"required"`
+	CRM           string `json:"crm" validate:"required"`
+}
+
+// AtualizarPacienteDTO é o DTO para alterar o cadastro de um paciente
+type AtualizarPacienteDTO struct {
+	Nome           string `json:"nome" validate:"required"`
+	DataNascimento string `json:"data_nascimento" validate:"required"` // AAAA-MM-DD
+	Endereco       string `json:"endereco"`
+}
+
+// AtualizarMedicamentoDTO é o DTO para alterar o cadastro de um medicamento
+type AtualizarMedicamentoDTO struct {
+	Nome      string `json:"nome" validate:"required"`
+	Descricao string `json:"descricao"`
+}
+
 // =========================================
 // QUERY MODELS (Read Side - Denormalized)
 // =========================================
@@ -106,6 +150,7 @@ type ViewProntuarioPaciente struct {
 	MedicoNome             string    `json:"medico_nome" db:"medico_nome"`
 	MedicoEspecialidade    string    `json:"medico_especialidade" db:"medico_especialidade"`
 	MedicoCRM              string    `json:"medico_crm" db:"medico_crm"`
+	Status                 string    `json:"status" db:"status"`
 	MedicamentoID          int       `json:"medicamento_id" db:"medicamento_id"`
 	MedicamentoNome        string    `json:"medicamento_nome" db:"medicamento_nome"`
 	MedicamentoDescricao   string    `json:"medicamento_descricao" db:"medicamento_descricao"`
@@ -115,6 +160,16 @@ type ViewProntuarioPaciente struct {
 	UpdatedAt              time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// ConsistencyTokenHeader é o header em que o command service devolve o token de consistência
+// e em que o query service o recebe para esperar a projeção alcançar a escrita (read-your-writes)
+const ConsistencyTokenHeader = "X-Consistency-Token"
+
+// TokenConsistencia monta o token de uma escrita nesta variante: não existe evento de domínio
+// com id próprio, então o token identifica a versão da prescrição gravada pelo comando
+func TokenConsistencia(idPrescricao, versao int) string {
+	return fmt.Sprintf("prescricao:%d:v%d", idPrescricao, versao)
+}
+
 // =========================================
 // DTOs PARA QUERIES
 // =========================================
@@ -138,6 +193,22 @@ type MedicamentoFarmaciaDTO struct {
 	Dosagem              string `json:"dosagem"`
 }
 
+// PaginacaoDTO descreve a página retornada por uma listagem
+// ProximoCursor vazio indica a última página
+type PaginacaoDTO struct {
+	Total         int    `json:"total"`
+	Limite        int    `json:"limite"`
+	Ordenacao     string `json:"ordenacao"`
+	Direcao       string `json:"direcao"`
+	ProximoCursor string `json:"proximo_cursor,omitempty"`
+}
+
+// PaginaPrescricoesFarmaciaDTO é uma página da listagem da farmácia
+type PaginaPrescricoesFarmaciaDTO struct {
+	Prescricoes []PrescricaoFarmaciaDTO `json:"prescricoes"`
+	Paginacao   PaginacaoDTO            `json:"paginacao"`
+}
+
 // ProntuarioPacienteDTO agrupa prescrições do prontuário
 type ProntuarioPacienteDTO struct {
 	PacienteID             int                       `json:"paciente_id"`
@@ -145,6 +216,7 @@ type ProntuarioPacienteDTO struct {
 	PacienteDataNascimento time.Time                 `json:"paciente_data_nascimento"`
 	PacienteEndereco       string                    `json:"paciente_endereco"`
 	Prescricoes            []PrescricaoProntuarioDTO `json:"prescricoes"`
+	Paginacao              *PaginacaoDTO             `json:"paginacao,omitempty"`
 }
 
 // PrescricaoProntuarioDTO representa uma prescrição no prontuário
@@ -155,6 +227,7 @@ type PrescricaoProntuarioDTO struct {
 	MedicoNome          string                     `json:"medico_nome"`
 	MedicoEspecialidade string                     `json:"medico_especialidade"`
 	MedicoCRM           string                     `json:"medico_crm"`
+	Status              string                     `json:"status"`
 	Medicamentos        []MedicamentoProntuarioDTO `json:"medicamentos"`
 }
 
@@ -0,0 +1,219 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// =========================================
+// VALIDAÇÃO DOS COMANDOS
+// =========================================
+// Os DTOs são validados antes de qualquer acesso ao banco. Todos os campos inválidos são
+// reportados de uma vez, com o caminho do campo no JSON (ex.: medicamentos[1].dosagem).
+
+var (
+	// ErrDadosInvalidos indica campos ausentes ou fora do formato esperado
+	ErrDadosInvalidos = errors.New("dados inválidos")
+	// ErrReferenciaInexistente indica médico, paciente ou medicamento que não existe no cadastro
+	ErrReferenciaInexistente = errors.New("referência inexistente")
+)
+
+// TamanhoMaximoCampo é o limite das colunas horario e dosagem (VARCHAR(50))
+const TamanhoMaximoCampo = 50
+
+// ErroCampo descreve o problema de um campo da requisição
+type ErroCampo struct {
+	Campo    string `json:"campo"`
+	Mensagem string `json:"mensagem"`
+}
+
+// ErroValidacao reúne os campos com problema de um comando
+// Tipo é ErrDadosInvalidos ou ErrReferenciaInexistente (use errors.Is)
+type ErroValidacao struct {
+	Tipo   error
+	Campos []ErroCampo
+}
+
+func (e *ErroValidacao) Error() string {
+	partes := make([]string, len(e.Campos))
+	for i, c := range e.Campos {
+		partes[i] = c.Campo + ": " + c.Mensagem
+	}
+	return fmt.Sprintf("%v: %s", e.Tipo, strings.Join(partes, "; "))
+}
+
+func (e *ErroValidacao) Unwrap() error {
+	return e.Tipo
+}
+
+// Validacao acumula os campos inválidos de um comando
+type Validacao struct {
+	tipo   error
+	campos []ErroCampo
+}
+
+// NovaValidacao inicia uma validação do tipo informado
+func NovaValidacao(tipo error) *Validacao {
+	return &Validacao{tipo: tipo}
+}
+
+// Adicionar registra um campo inválido
+func (v *Validacao) Adicionar(campo, formato string, args ...any) {
+	v.campos = append(v.campos, ErroCampo{Campo: campo, Mensagem: fmt.Sprintf(formato, args...)})
+}
+
+// Erro retorna nil se nenhum campo foi registrado, ou o *ErroValidacao com todos eles
+func (v *Validacao) Erro() error {
+	if len(v.campos) == 0 {
+		return nil
+	}
+	return &ErroValidacao{Tipo: v.tipo, Campos: v.campos}
+}
+
+// Validar confere os campos do comando de criar prescrição
+func (dto CriarPrescricaoDTO) Validar() error {
+	v := NovaValidacao(ErrDadosInvalidos)
+	if dto.IDMedico <= 0 {
+		v.Adicionar("id_medico", "obrigatório")
+	}
+	if dto.IDPaciente <= 0 {
+		v.Adicionar("id_paciente", "obrigatório")
+	}
+	validarMedicamentos(v, dto.Medicamentos)
+	return v.Erro()
+}
+
+// Validar confere os campos do comando de alterar dosagem/horário
+func (dto AtualizarPrescricaoDTO) Validar() error {
+	v := NovaValidacao(ErrDadosInvalidos)
+	validarMedicamentos(v, dto.Medicamentos)
+	return v.Erro()
+}
+
+// Validar confere os campos do comando de cancelar prescrição
+func (dto CancelarPrescricaoDTO) Validar() error {
+	v := NovaValidacao(ErrDadosInvalidos)
+	if strings.TrimSpace(dto.Motivo) == "" {
+		v.Adicionar("motivo", "obrigatório")
+	}
+	return v.Erro()
+}
+
+// validarMedicamentos exige ao menos um medicamento, sem repetição, com horário e dosagem válidos
+func validarMedicamentos(v *Validacao, medicamentos []MedicamentoPrescrito) {
+	if len(medicamentos) == 0 {
+		v.Adicionar("medicamentos", "informe ao menos um medicamento")
+		return
+	}
+
+	posicao := make(map[int]int, len(medicamentos))
+	for i, med := range medicamentos {
+		campo := fmt.Sprintf("medicamentos[%d]", i)
+
+		if med.IDMedicamento <= 0 {
+			v.Adicionar(campo+".id_medicamento", "obrigatório")
+		} else if anterior, repetido := posicao[med.IDMedicamento]; repetido {
+			v.Adicionar(campo+".id_medicamento", "medicamento %d repetido (já informado em medicamentos[%d])", med.IDMedicamento, anterior)
+		} else {
+			posicao[med.IDMedicamento] = i
+		}
+
+		if msg := validarHorario(med.Horario); msg != "" {
+			v.Adicionar(campo+".horario", "%s", msg)
+		}
+		if msg := validarDosagem(med.Dosagem); msg != "" {
+			v.Adicionar(campo+".dosagem", "%s", msg)
+		}
+	}
+}
+
+// =========================================
+// HORÁRIO E DOSAGEM
+// =========================================
+
+var (
+	reHoraMinuto = regexp.MustCompile(`^([01]\d|2[0-3]):[0-5]\d$`)
+	reIntervalo  = regexp.MustCompile(`(?i)^de (\d{1,2}) em (\d{1,2}) horas?$`)
+	// Quantidade positiva + unidade, com observação opcional entre parênteses: "250mg (infantil)"
+	reDosagem = regexp.MustCompile(`(?i)^(\d+(?:[.,]\d+)?)\s*(mg|mcg|g|ml|ui|gotas?|comprimidos?|cápsulas?)(?:\s*\([^()]*\))?$`)
+)
+
+// OrientacoesHorario são os horários descritivos aceitos além de HH:MM e "De N em N horas"
+var OrientacoesHorario = []string{
+	"Em jejum",
+	"Antes do café da manhã",
+	"Após o café da manhã",
+	"Antes do almoço",
+	"Após o almoço",
+	"Antes do jantar",
+	"Após o jantar",
+	"Antes de dormir",
+	"Se necessário",
+	"Dose única",
+}
+
+// validarHorario aceita uma lista de horários HH:MM separados por vírgula ("08:00, 20:00"),
+// um intervalo ("De 8 em 8 horas") ou uma das OrientacoesHorario; retorna a mensagem de erro
+func validarHorario(horario string) string {
+	horario = strings.TrimSpace(horario)
+	switch {
+	case horario == "":
+		return "obrigatório"
+	case utf8.RuneCountInString(horario) > TamanhoMaximoCampo:
+		return fmt.Sprintf("no máximo %d caracteres", TamanhoMaximoCampo)
+	}
+
+	for _, o := range OrientacoesHorario {
+		if strings.EqualFold(horario, o) {
+			return ""
+		}
+	}
+
+	if m := reIntervalo.FindStringSubmatch(horario); m != nil {
+		a, _ := strconv.Atoi(m[1])
+		b, _ := strconv.Atoi(m[2])
+		if a != b || a < 1 || a > 24 {
+			return `intervalo inválido: use "De N em N horas" com N entre 1 e 24`
+		}
+		return ""
+	}
+
+	vistos := make(map[string]bool)
+	for _, h := range strings.Split(horario, ",") {
+		h = strings.TrimSpace(h)
+		if !reHoraMinuto.MatchString(h) {
+			return `formato inválido: use horários HH:MM separados por vírgula ("08:00, 20:00"), ` +
+				`"De N em N horas" ou uma orientação como "Antes de dormir"`
+		}
+		if vistos[h] {
+			return fmt.Sprintf("horário %s repetido", h)
+		}
+		vistos[h] = true
+	}
+	return ""
+}
+
+// validarDosagem exige quantidade positiva e unidade conhecida (mg, mcg, g, ml, UI, gotas,
+// comprimidos, cápsulas), com observação opcional entre parênteses; retorna a mensagem de erro
+func validarDosagem(dosagem string) string {
+	dosagem = strings.TrimSpace(dosagem)
+	switch {
+	case dosagem == "":
+		return "obrigatório"
+	case utf8.RuneCountInString(dosagem) > TamanhoMaximoCampo:
+		return fmt.Sprintf("no máximo %d caracteres", TamanhoMaximoCampo)
+	}
+
+	m := reDosagem.FindStringSubmatch(dosagem)
+	if m == nil {
+		return `formato inválido: use quantidade e unidade (mg, mcg, g, ml, UI, gotas, comprimidos, cápsulas), ex.: "500mg"`
+	}
+	if q, err := strconv.ParseFloat(strings.Replace(m[1], ",", ".", 1), 64); err != nil || q <= 0 {
+		return "a quantidade deve ser maior que zero"
+	}
+	return ""
+}
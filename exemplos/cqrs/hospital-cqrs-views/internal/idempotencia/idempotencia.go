@@ -0,0 +1,183 @@
+package idempotencia
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// =========================================
+// IDEMPOTENCY-KEY
+// =========================================
+// O cliente envia Idempotency-Key em um comando que pode repetir (ex.: após timeout).
+// A primeira requisição reserva a chave; a resposta é gravada na mesma transação do comando
+// (Reserva.Concluir) e as repetições com o mesmo corpo a recebem de volta sem executar o
+// comando outra vez. Comando confirmado implica resposta gravada: não há janela entre os dois.
+//
+// Respostas de erro não são gravadas: a chave é liberada e a repetição executa o comando de
+// novo. Uma reserva sem resposta há mais de um minuto (processo que caiu no meio do comando)
+// pode ser retomada por uma repetição com o mesmo corpo; a retomada troca o id da reserva, e o
+// comando original, se ainda estiver rodando, falha ao concluir e é desfeito
+// (ErrReservaPerdida). Assim no máximo um comando por chave é confirmado.
+
+// Header é o header HTTP da chave de idempotência
+const Header = "Idempotency-Key"
+
+// TamanhoMaximoChave é o limite da coluna chave
+const TamanhoMaximoChave = 255
+
+var (
+	// ErrChaveReutilizada indica a mesma chave com outra requisição (rota ou corpo diferente)
+	ErrChaveReutilizada = errors.New("Idempotency-Key já usada com outra requisição")
+	// ErrEmProcessamento indica que a requisição original com esta chave ainda não terminou
+	ErrEmProcessamento = errors.New("requisição com esta Idempotency-Key ainda em processamento")
+	// ErrReservaPerdida indica que a reserva foi retomada por uma repetição antes do comando concluir
+	ErrReservaPerdida = errors.New("reserva da Idempotency-Key retomada por outra requisição")
+)
+
+// Resposta é o resultado gravado da requisição original
+type Resposta struct {
+	Status     int
+	Corpo      []byte
+	Cabecalhos map[string]string
+}
+
+// Reserva identifica a reserva de uma chave feita por esta requisição
+type Reserva struct {
+	Usuario string
+	Chave   string
+	ID      string // trocado quando outra requisição retoma a reserva
+}
+
+// Store guarda as chaves e respostas na tabela Idempotency_Keys
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore cria o store de chaves de idempotência
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// HashRequisicao identifica a requisição pelo método, rota e corpo
+// Corpos JSON são normalizados (espaços e ordem das chaves não mudam o hash)
+func HashRequisicao(metodo, rota string, corpo []byte) string {
+	var v any
+	if err := json.Unmarshal(corpo, &v); err == nil {
+		if normalizado, err := json.Marshal(v); err == nil {
+			corpo = normalizado
+		}
+	}
+
+	h := sha256.New()
+	h.Write([]byte(metodo + " " + rota + "\n"))
+	h.Write(bytes.TrimSpace(corpo))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Reservar tenta reservar a chave para a requisição
+//   - chave nova (ou expirada): reserva e retorna a Reserva; o chamador executa o comando
+//     e conclui a reserva na transação dele
+//   - chave concluída com o mesmo hash: retorna a resposta original
+//   - hash diferente: ErrChaveReutilizada
+//   - ainda em processamento: ErrEmProcessamento
+func (s *Store) Reservar(ctx context.Context, usuario, chave, hash string) (*Resposta, *Reserva, error) {
+	// A reserva é um único upsert: concorrentes com a mesma chave disputam a mesma linha
+	// Se o comando original estiver gravando a resposta, o upsert espera o commit e não retoma
+	reserva := &Reserva{Usuario: usuario, Chave: chave, ID: uuid.NewString()}
+	query := `
+		INSERT INTO Idempotency_Keys (usuario, chave, hash_requisicao, reserva_id)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (usuario, chave) DO UPDATE
+		SET hash_requisicao = EXCLUDED.hash_requisicao, reserva_id = EXCLUDED.reserva_id,
+			status_resposta = NULL, corpo_resposta = NULL,
+			cabecalhos_resposta = NULL, criada_em = NOW(), reservada_em = NOW()
+		WHERE Idempotency_Keys.criada_em < NOW() - INTERVAL '24 hours'
+			OR (Idempotency_Keys.status_resposta IS NULL
+				AND Idempotency_Keys.hash_requisicao = EXCLUDED.hash_requisicao
+				AND Idempotency_Keys.reservada_em < NOW() - INTERVAL '1 minute')
+		RETURNING chave
+	`
+	var reservada string
+	err := s.db.QueryRowContext(ctx, query, usuario, chave, hash, reserva.ID).Scan(&reservada)
+	if err == nil {
+		return nil, reserva, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, fmt.Errorf("erro ao reservar Idempotency-Key: %w", err)
+	}
+
+	// Conflito sem atualização: a chave já existe e é válida
+	var hashOriginal string
+	var status sql.NullInt64
+	var corpo, cabecalhos []byte
+	query = `
+		SELECT hash_requisicao, status_resposta, corpo_resposta, cabecalhos_resposta
+		FROM Idempotency_Keys
+		WHERE usuario = $1 AND chave = $2
+	`
+	err = s.db.QueryRowContext(ctx, query, usuario, chave).Scan(&hashOriginal, &status, &corpo, &cabecalhos)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Liberada entre o upsert e a leitura: o cliente pode repetir
+		return nil, nil, ErrEmProcessamento
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao buscar Idempotency-Key: %w", err)
+	}
+
+	if hashOriginal != hash {
+		return nil, nil, ErrChaveReutilizada
+	}
+	if !status.Valid {
+		return nil, nil, ErrEmProcessamento
+	}
+
+	resposta := &Resposta{Status: int(status.Int64), Corpo: corpo}
+	if len(cabecalhos) > 0 {
+		if err := json.Unmarshal(cabecalhos, &resposta.Cabecalhos); err != nil {
+			return nil, nil, fmt.Errorf("erro ao ler cabeçalhos gravados: %w", err)
+		}
+	}
+	return resposta, nil, nil
+}
+
+// Concluir grava a resposta na transação do comando
+// Se a reserva foi retomada por outra requisição, retorna ErrReservaPerdida: o chamador
+// precisa desfazer a transação para que só a outra requisição confirme o comando
+func (r *Reserva) Concluir(ctx context.Context, tx *sql.Tx, resposta Resposta) error {
+	cabecalhos, err := json.Marshal(resposta.Cabecalhos)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar cabeçalhos: %w", err)
+	}
+
+	query := `
+		UPDATE Idempotency_Keys
+		SET status_resposta = $4, corpo_resposta = $5, cabecalhos_resposta = $6
+		WHERE usuario = $1 AND chave = $2 AND reserva_id = $3 AND status_resposta IS NULL
+	`
+	result, err := tx.ExecContext(ctx, query, r.Usuario, r.Chave, r.ID, resposta.Status, resposta.Corpo, cabecalhos)
+	if err != nil {
+		return fmt.Errorf("erro ao gravar resposta da Idempotency-Key: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrReservaPerdida
+	}
+	return nil
+}
+
+// Liberar apaga a reserva de uma requisição que falhou, permitindo repetir o comando
+// Só apaga a própria reserva e sem resposta: um comando confirmado nunca é liberado
+func (s *Store) Liberar(ctx context.Context, reserva *Reserva) error {
+	query := `DELETE FROM Idempotency_Keys WHERE usuario = $1 AND chave = $2 AND reserva_id = $3 AND status_resposta IS NULL`
+	if _, err := s.db.ExecContext(ctx, query, reserva.Usuario, reserva.Chave, reserva.ID); err != nil {
+		return fmt.Errorf("erro ao liberar Idempotency-Key: %w", err)
+	}
+	return nil
+}
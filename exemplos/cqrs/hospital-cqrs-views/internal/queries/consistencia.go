@@ -0,0 +1,65 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTokenInvalido indica um token de consistência malformado
+var ErrTokenInvalido = errors.New("token de consistência inválido")
+
+// Consistencia verifica se as projeções já refletem uma escrita (read-your-writes)
+// Nesta variante o token é "prescricao:<id>:v<versao>" (ver domain.TokenConsistencia): a
+// escrita está visível quando um refresh já levou para mv_prontuario_paciente uma versão igual
+// ou maior. O refresh atualiza o prontuário antes da farmácia, então a farmácia também está em dia
+type Consistencia struct {
+	db        *sql.DB
+	intervalo time.Duration
+	aoEsperar func()
+}
+
+// NewConsistencia cria o verificador de consistência
+// aoEsperar (opcional) é chamada uma vez quando a view ainda não alcançou o token, para
+// antecipar o refresh em vez de esperar o próximo agendado
+func NewConsistencia(db *sql.DB, aoEsperar func()) *Consistencia {
+	return &Consistencia{db: db, intervalo: 50 * time.Millisecond, aoEsperar: aoEsperar}
+}
+
+// Aguardar espera a projeção alcançar o token até o ctx expirar
+// Retorna false (sem erro) se o prazo acabou antes
+func (c *Consistencia) Aguardar(ctx context.Context, token string) (bool, error) {
+	var idPrescricao, versao int
+	if _, err := fmt.Sscanf(token, "prescricao:%d:v%d", &idPrescricao, &versao); err != nil || versao < 1 {
+		return false, fmt.Errorf("%w: %q", ErrTokenInvalido, token)
+	}
+
+	ticker := time.NewTicker(c.intervalo)
+	defer ticker.Stop()
+
+	for tentativa := 0; ; tentativa++ {
+		var alcancou bool
+		query := `SELECT EXISTS(SELECT 1 FROM mv_prontuario_paciente WHERE id_prescricao = $1 AND versao >= $2)`
+		err := c.db.QueryRowContext(ctx, query, idPrescricao, versao).Scan(&alcancou)
+		if err != nil {
+			if ctx.Err() != nil {
+				return false, nil
+			}
+			return false, fmt.Errorf("erro ao verificar token de consistência: %w", err)
+		}
+		if alcancou {
+			return true, nil
+		}
+		if tentativa == 0 && c.aoEsperar != nil {
+			c.aoEsperar()
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, nil
+		case <-ticker.C:
+		}
+	}
+}
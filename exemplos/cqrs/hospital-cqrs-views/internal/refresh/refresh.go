@@ -0,0 +1,231 @@
+package refresh
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// =========================================
+// REFRESH DAS MATERIALIZED VIEWS
+// =========================================
+// O read side desta variante são materialized views recalculadas a partir do modelo de
+// escrita. O Refresher as atualiza com REFRESH MATERIALIZED VIEW CONCURRENTLY (leituras
+// continuam servidas durante o refresh) a cada REFRESH_INTERVAL e sob demanda.
+
+// ErrRefreshEmAndamento indica que outro refresh da mesma view está em execução
+// (neste processo ou em outra instância do query service)
+var ErrRefreshEmAndamento = errors.New("refresh já em andamento")
+
+// View é uma materialized view e as tabelas do modelo de escrita de onde ela vem
+type View struct {
+	Nome   string
+	Fontes []string
+}
+
+// Views na ordem de refresh: o prontuário vem antes da farmácia porque o token de
+// consistência é verificado no prontuário
+var Views = []View{
+	{Nome: "mv_prontuario_paciente", Fontes: []string{"Prescricoes", "Prescricao_Medicamentos", "Pacientes", "Medicos", "Medicamentos"}},
+	{Nome: "mv_farmacia", Fontes: []string{"Prescricoes", "Prescricao_Medicamentos", "Pacientes", "Medicamentos"}},
+}
+
+// Refresher atualiza as materialized views periodicamente e sob demanda
+type Refresher struct {
+	db          *sql.DB
+	intervalo   time.Duration
+	solicitacao chan struct{}
+}
+
+// NewRefresher cria o refresher com o intervalo de REFRESH_INTERVAL (padrão 30s)
+func NewRefresher(db *sql.DB) *Refresher {
+	intervalo := 30 * time.Second
+	if v, err := time.ParseDuration(os.Getenv("REFRESH_INTERVAL")); err == nil && v > 0 {
+		intervalo = v
+	}
+	return &Refresher{db: db, intervalo: intervalo, solicitacao: make(chan struct{}, 1)}
+}
+
+// Intervalo retorna o intervalo entre refreshes agendados
+func (r *Refresher) Intervalo() time.Duration {
+	return r.intervalo
+}
+
+// Executar roda o agendamento até o ctx ser cancelado
+// Solicitações feitas durante um refresh são agrupadas em um único refresh seguinte
+func (r *Refresher) Executar(ctx context.Context) {
+	ticker := time.NewTicker(r.intervalo)
+	defer ticker.Stop()
+
+	log.Printf("Refresh das materialized views a cada %s", r.intervalo)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.solicitacao:
+		}
+
+		if err := r.AtualizarTodas(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Erro no refresh agendado: %v", err)
+		}
+	}
+}
+
+// Solicitar antecipa o próximo refresh agendado sem esperar por ele
+func (r *Refresher) Solicitar() {
+	select {
+	case r.solicitacao <- struct{}{}:
+	default:
+	}
+}
+
+// AtualizarTodas faz o refresh de todas as views, na ordem de Views
+// Views ocupadas por outro refresh são puladas (o outro refresh já as atualiza)
+func (r *Refresher) AtualizarTodas(ctx context.Context) error {
+	var erros []error
+	for _, v := range Views {
+		if err := r.Atualizar(ctx, v.Nome); err != nil && !errors.Is(err, ErrRefreshEmAndamento) {
+			erros = append(erros, err)
+		}
+	}
+	return errors.Join(erros...)
+}
+
+// Atualizar faz o refresh concorrente de uma view e registra o resultado em Refresh_Views
+//
+// Um advisory lock por view garante um único refresh por vez entre todas as instâncias.
+// Antes do refresh é registrado o corte dos dados: o início da transação mais antiga em
+// andamento no banco. Toda escrita confirmada antes do refresh começou depois desse corte
+// ou já está na view, então updated_at >= dados_ate indica possível defasagem.
+func (r *Refresher) Atualizar(ctx context.Context, view string) error {
+	if !viewConhecida(view) {
+		return fmt.Errorf("materialized view desconhecida: %s", view)
+	}
+
+	// Lock, refresh e unlock precisam da mesma sessão
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao obter conexão: %w", err)
+	}
+	defer conn.Close()
+
+	var travou bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock(hashtext($1))`, view).Scan(&travou); err != nil {
+		return fmt.Errorf("erro ao obter lock de refresh de %s: %w", view, err)
+	}
+	if !travou {
+		return fmt.Errorf("%s: %w", view, ErrRefreshEmAndamento)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock(hashtext($1))`, view)
+
+	var dadosAte time.Time
+	query := `SELECT MIN(xact_start)::timestamp FROM pg_stat_activity WHERE datname = current_database()`
+	if err := conn.QueryRowContext(ctx, query).Scan(&dadosAte); err != nil {
+		return fmt.Errorf("erro ao registrar corte do refresh de %s: %w", view, err)
+	}
+
+	inicio := time.Now()
+	// O nome vem de Views (não da requisição), então pode ser interpolado
+	if _, err := conn.ExecContext(ctx, `REFRESH MATERIALIZED VIEW CONCURRENTLY `+view); err != nil {
+		r.registrarErro(view, err)
+		return fmt.Errorf("erro no refresh de %s: %w", view, err)
+	}
+	duracao := time.Since(inicio)
+
+	query = `
+		UPDATE Refresh_Views
+		SET dados_ate = $2, concluido_em = NOW(), duracao_ms = $3, ultimo_erro = NULL, erro_em = NULL
+		WHERE view_name = $1
+	`
+	if _, err := conn.ExecContext(ctx, query, view, dadosAte, duracao.Milliseconds()); err != nil {
+		return fmt.Errorf("erro ao registrar refresh de %s: %w", view, err)
+	}
+
+	log.Printf("Refresh de %s concluído em %s", view, duracao.Round(time.Millisecond))
+	return nil
+}
+
+// registrarErro guarda a falha sem apagar o último refresh bem-sucedido
+func (r *Refresher) registrarErro(view string, causa error) {
+	query := `UPDATE Refresh_Views SET ultimo_erro = $2, erro_em = NOW() WHERE view_name = $1`
+	if _, err := r.db.ExecContext(context.Background(), query, view, causa.Error()); err != nil {
+		log.Printf("Erro ao registrar falha do refresh de %s: %v", view, err)
+	}
+}
+
+func viewConhecida(nome string) bool {
+	for _, v := range Views {
+		if v.Nome == nome {
+			return true
+		}
+	}
+	return false
+}
+
+// =========================================
+// STATUS
+// =========================================
+
+// StatusView descreve o último refresh de uma view e o quanto ela pode estar defasada
+type StatusView struct {
+	View              string     `json:"view"`
+	DadosAte          time.Time  `json:"dados_ate"`
+	ConcluidoEm       time.Time  `json:"concluido_em"`
+	DuracaoMs         int        `json:"duracao_ms"`
+	UltimaEscrita     *time.Time `json:"ultima_escrita,omitempty"`
+	Desatualizada     bool       `json:"desatualizada"`
+	DefasagemSegundos float64    `json:"defasagem_segundos"`
+	UltimoErro        string     `json:"ultimo_erro,omitempty"`
+	ErroEm            *time.Time `json:"erro_em,omitempty"`
+}
+
+// Status retorna a situação de cada view
+// Uma view está desatualizada quando alguma tabela de origem foi escrita depois do corte
+// do último refresh; a defasagem é o tempo desde esse corte (zero se está em dia)
+func (r *Refresher) Status(ctx context.Context) ([]StatusView, error) {
+	status := make([]StatusView, 0, len(Views))
+	for _, v := range Views {
+		s := StatusView{View: v.Nome}
+
+		ultimas := make([]string, len(v.Fontes))
+		for i, tabela := range v.Fontes {
+			ultimas[i] = `(SELECT MAX(updated_at) FROM ` + tabela + `)`
+		}
+
+		var erro sql.NullString
+		var erroEm, ultimaEscrita sql.NullTime
+		var defasagem float64
+		query := `
+			SELECT rv.dados_ate, rv.concluido_em, rv.duracao_ms, rv.ultimo_erro, rv.erro_em,
+				e.ultima, EXTRACT(EPOCH FROM (NOW()::timestamp - rv.dados_ate))::float8
+			FROM Refresh_Views rv
+			CROSS JOIN (SELECT GREATEST(` + strings.Join(ultimas, ", ") + `) AS ultima) e
+			WHERE rv.view_name = $1
+		`
+		err := r.db.QueryRowContext(ctx, query, v.Nome).Scan(&s.DadosAte, &s.ConcluidoEm, &s.DuracaoMs,
+			&erro, &erroEm, &ultimaEscrita, &defasagem)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao consultar status de %s: %w", v.Nome, err)
+		}
+
+		s.UltimoErro = erro.String
+		if erroEm.Valid {
+			s.ErroEm = &erroEm.Time
+		}
+		if ultimaEscrita.Valid {
+			s.UltimaEscrita = &ultimaEscrita.Time
+			s.Desatualizada = !ultimaEscrita.Time.Before(s.DadosAte)
+		}
+		if s.Desatualizada {
+			s.DefasagemSegundos = defasagem
+		}
+		status = append(status, s)
+	}
+	return status, nil
+}
@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// =========================================
+// AUTENTICAÇÃO JWT COM PAPÉIS
+// =========================================
+// Os serviços aceitam um JWT HS256 no header Authorization: Bearer <token>, assinado com
+// JWT_SECRET. O papel define o que o usuário pode fazer e os ids ligam o usuário ao seu
+// cadastro: o médico só prescreve em seu nome e o paciente só vê o próprio prontuário.
+
+// Papel é o perfil de acesso do usuário
+type Papel string
+
+const (
+	PapelMedico   Papel = "medico"
+	PapelFarmacia Papel = "farmacia"
+	PapelPaciente Papel = "paciente"
+	PapelAdmin    Papel = "admin" // cadastros e operação (refresh, etc.)
+)
+
+// Emissor identifica os tokens emitidos para este sistema
+const Emissor = "hospital-cqrs"
+
+const chaveUsuario = "auth.usuario"
+
+var (
+	// ErrTokenAusente indica requisição sem Authorization: Bearer
+	ErrTokenAusente = errors.New("token de acesso ausente")
+	// ErrTokenInvalido indica token malformado, expirado, com assinatura ou claims inválidas
+	ErrTokenInvalido = errors.New("token de acesso inválido")
+)
+
+// Claims são os dados do usuário carregados no token
+type Claims struct {
+	Papel      Papel `json:"papel"`
+	MedicoID   int   `json:"medico_id,omitempty"`
+	PacienteID int   `json:"paciente_id,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// validar confere se o papel é conhecido e vem com o id do cadastro que ele exige
+func (c *Claims) validar() error {
+	switch c.Papel {
+	case PapelMedico:
+		if c.MedicoID <= 0 {
+			return errors.New("papel medico exige medico_id")
+		}
+	case PapelPaciente:
+		if c.PacienteID <= 0 {
+			return errors.New("papel paciente exige paciente_id")
+		}
+	case PapelFarmacia, PapelAdmin:
+	default:
+		return fmt.Errorf("papel desconhecido: %q", c.Papel)
+	}
+	return nil
+}
+
+// PodeVerProntuario informa se o usuário pode ler o prontuário do paciente
+// Médicos e administradores veem qualquer prontuário; pacientes, só o próprio
+func (c *Claims) PodeVerProntuario(idPaciente int) bool {
+	switch c.Papel {
+	case PapelMedico, PapelAdmin:
+		return true
+	case PapelPaciente:
+		return c.PacienteID == idPaciente
+	default:
+		return false
+	}
+}
+
+// Autenticador emite e valida tokens com a chave compartilhada
+type Autenticador struct {
+	segredo []byte
+}
+
+// NewAutenticador lê a chave de JWT_SECRET (obrigatória)
+func NewAutenticador() (*Autenticador, error) {
+	segredo := os.Getenv("JWT_SECRET")
+	if segredo == "" {
+		return nil, fmt.Errorf("JWT_SECRET não configurada")
+	}
+	return &Autenticador{segredo: []byte(segredo)}, nil
+}
+
+// Emitir assina um token para o usuário com a validade informada
+func (a *Autenticador) Emitir(claims Claims, validade time.Duration) (string, error) {
+	if err := claims.validar(); err != nil {
+		return "", err
+	}
+
+	agora := time.Now()
+	claims.Issuer = Emissor
+	claims.IssuedAt = jwt.NewNumericDate(agora)
+	claims.ExpiresAt = jwt.NewNumericDate(agora.Add(validade))
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(a.segredo)
+}
+
+// Validar confere assinatura, emissor e validade do token e retorna as claims
+func (a *Autenticador) Validar(token string) (*Claims, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return a.segredo, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(Emissor),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTokenInvalido, err)
+	}
+	if err := claims.validar(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTokenInvalido, err)
+	}
+	return &claims, nil
+}
+
+// Middleware exige um token válido e guarda as claims na requisição
+func (a *Autenticador) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		if !ok || token == "" {
+			c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="hospital-cqrs"`)
+			return c.Status(401).JSON(fiber.Map{"error": ErrTokenAusente.Error()})
+		}
+
+		claims, err := a.Validar(token)
+		if err != nil {
+			c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="hospital-cqrs", error="invalid_token"`)
+			return c.Status(401).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		c.Locals(chaveUsuario, claims)
+		return c.Next()
+	}
+}
+
+// ExigirPapel libera a rota apenas para os papéis informados
+func ExigirPapel(papeis ...Papel) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		usuario := Usuario(c)
+		if usuario != nil {
+			for _, p := range papeis {
+				if usuario.Papel == p {
+					return c.Next()
+				}
+			}
+		}
+		return c.Status(403).JSON(fiber.Map{"error": "Acesso negado para este perfil"})
+	}
+}
+
+// Usuario retorna as claims do usuário autenticado (nil fora do Middleware)
+func Usuario(c *fiber.Ctx) *Claims {
+	claims, _ := c.Locals(chaveUsuario).(*Claims)
+	return claims
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"hospital-cqrs/internal/events"
+	"hospital-cqrs/internal/projections"
+	"hospital-cqrs/internal/readmodel"
+	"hospital-cqrs/pkg/database"
+)
+
+// Códigos de saída, para uso em jobs agendados (cron, CronJob do Kubernetes...)
+const (
+	saidaOK          = 0 // views batem com o modelo de escrita
+	saidaDivergencia = 1 // divergências encontradas (mesmo que reparadas, para gerar alerta)
+	saidaErro        = 2 // falha ao verificar
+)
+
+// check-views compara View_Farmacia e View_Prontuario_Paciente com o modelo de escrita
+// (Prescricoes, Prescricao_Medicamentos, Medicos, Pacientes e Medicamentos) e reporta linhas
+// faltando, sobrando e com valores divergentes. Com -reparar corrige só as linhas afetadas,
+// sem o custo de um rebuild completo.
+//
+// Uso:
+//
+//	go run ./cmd/check-views                           # verifica todas as views
+//	go run ./cmd/check-views -view farmacia -reparar   # verifica e repara a farmácia
+//	go run ./cmd/check-views -margem 2m                # ignora prescrições alteradas nos últimos 2 minutos
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	viewFlag := flag.String("view", "todas", "view a verificar: farmacia, prontuario ou todas")
+	reparar := flag.Bool("reparar", false, "corrigir as linhas divergentes")
+	margem := flag.Duration("margem", 30*time.Second, "ignorar prescrições alteradas dentro deste intervalo (eventos ainda em trânsito)")
+	limite := flag.Int("limite", 50, "máximo de divergências detalhadas no log por view")
+	flag.Parse()
+
+	// Com o event store não há Prescricoes para comparar: as views só se refazem pelo replay
+	if estrategia, err := events.Propagacao(); err == nil && estrategia == events.PropagacaoEventStore {
+		log.Printf("Com EVENT_PROPAGATION=%s o modelo de escrita é o event store e check-views não se aplica (veja rebuild-views)", events.PropagacaoEventStore)
+		return saidaErro
+	}
+
+	// A verificação compara as tabelas View_*: com o Redis o query service não as lê
+	backend, err := readmodel.Backend()
+	if err != nil {
+		log.Printf("Erro de configuração: %v", err)
+		return saidaErro
+	}
+	if backend != readmodel.BackendPostgres {
+		log.Printf("Com READ_MODEL_BACKEND=%s o query service lê o Redis e check-views só verifica as tabelas View_* do Postgres (use READ_MODEL_BACKEND=%s)",
+			backend, readmodel.BackendPostgres)
+		return saidaErro
+	}
+
+	views := projections.Views()
+	if *viewFlag != "todas" {
+		views = []projections.View{projections.View(*viewFlag)}
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		log.Printf("Erro ao conectar ao banco de dados: %v", err)
+		return saidaErro
+	}
+	defer db.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	verificador := projections.NewVerificador(db, *margem)
+	saida := saidaOK
+
+	for _, view := range views {
+		var relatorio *projections.RelatorioDivergencias
+		if *reparar {
+			relatorio, err = verificador.Reparar(ctx, view)
+		} else {
+			relatorio, err = verificador.Verificar(ctx, view)
+		}
+		if err != nil {
+			log.Printf("Erro ao verificar view %s: %v", view, err)
+			return saidaErro
+		}
+
+		if len(relatorio.Divergencias) == 0 {
+			log.Printf("View %s: sem divergências", view)
+			continue
+		}
+
+		saida = saidaDivergencia
+		contagem := relatorio.Contagem()
+		log.Printf("View %s: %d divergência(s) - faltando=%d sobrando=%d divergente=%d",
+			view, len(relatorio.Divergencias),
+			contagem[projections.DivergenciaFaltando],
+			contagem[projections.DivergenciaSobrando],
+			contagem[projections.DivergenciaValores])
+
+		for i, d := range relatorio.Divergencias {
+			if i == *limite {
+				log.Printf("  ... e mais %d", len(relatorio.Divergencias)-*limite)
+				break
+			}
+			log.Printf("  %s", d)
+		}
+
+		if *reparar {
+			log.Printf("View %s: %d divergência(s) reparada(s)", view, relatorio.Reparadas)
+		}
+	}
+
+	return saida
+}
@@ -0,0 +1,448 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/google/uuid"
+
+	"hospital-cqrs/internal/commands"
+	"hospital-cqrs/internal/domain"
+	"hospital-cqrs/internal/events"
+	"hospital-cqrs/internal/eventstore"
+	"hospital-cqrs/internal/idempotencia"
+	"hospital-cqrs/internal/regras"
+	"hospital-cqrs/pkg/auth"
+	"hospital-cqrs/pkg/database"
+	"hospital-cqrs/pkg/kafka"
+)
+
+func main() {
+	log.Println("Iniciando Command Service (Write Side - CQRS)...")
+
+	// Estratégia de propagação dos eventos (EVENT_PROPAGATION: direto, outbox, cdc ou eventstore)
+	estrategia, err := events.Propagacao()
+	if err != nil {
+		log.Fatalf("Erro de configuração: %v", err)
+	}
+
+	// Conectar ao banco de dados
+	db, err := database.Connect()
+	if err != nil {
+		log.Fatalf("Erro ao conectar ao banco de dados: %v", err)
+	}
+	defer db.Close()
+
+	// Autenticação JWT (chave em JWT_SECRET)
+	autenticador, err := auth.NewAutenticador()
+	if err != nil {
+		log.Fatalf("Erro de configuração: %v", err)
+	}
+
+	// Só a publicação direta usa o Kafka no command service: na outbox quem publica é o
+	// outbox-relay, no CDC é o Debezium e com o event store não há Kafka
+	var producer *kafka.Producer
+	if estrategia == events.PropagacaoDireta {
+		producer, err = kafka.NewProducer("prescricoes")
+		if err != nil {
+			log.Fatalf("Erro ao criar produtor Kafka: %v", err)
+		}
+		defer producer.Close()
+	}
+
+	propagador, err := commands.NewEventPropagator(estrategia, producer)
+	if err != nil {
+		log.Fatalf("Erro de configuração: %v", err)
+	}
+	log.Printf("Propagação de eventos: %s", propagador.Nome())
+
+	// Regras clínicas avaliadas na criação de prescrições (REGRAS_CLINICAS_ARQUIVO e REGRAS_CLINICAS_MODO)
+	motorRegras, err := regras.Carregar()
+	if err != nil {
+		log.Fatalf("Erro ao carregar regras clínicas: %v", err)
+	}
+	log.Printf("Regras clínicas: %s", motorRegras.Resumo())
+
+	// Criar handler de comandos (com event store, a prescrição é reconstruída dos seus eventos)
+	prescricaoHandler := commands.NewPrescricaoHandler(db, propagador, motorRegras)
+	if estrategia == events.PropagacaoEventStore {
+		intervalo, err := eventstore.IntervaloSnapshot()
+		if err != nil {
+			log.Fatalf("Erro de configuração: %v", err)
+		}
+		log.Printf("Snapshots da prescrição a cada %d eventos (0 = desligado)", intervalo)
+		prescricaoHandler = commands.NewPrescricaoHandlerComEventStore(db, propagador, eventstore.NewStore(db, intervalo), motorRegras)
+	}
+	idempotencyStore := idempotencia.NewStore(db)
+
+	// Configurar Fiber
+	app := fiber.New(fiber.Config{
+		AppName: "Hospital CQRS - Command Service",
+	})
+
+	// Middlewares
+	app.Use(recover.New())
+	app.Use(logger.New())
+	app.Use(cors.New())
+
+	// Correlation id: reaproveita o header do cliente ou gera um novo; na outbox segue para os headers Kafka
+	app.Use(func(c *fiber.Ctx) error {
+		correlationID := c.Get(events.CorrelationIDHeader)
+		if correlationID == "" {
+			correlationID = uuid.NewString()
+		}
+		c.Set(events.CorrelationIDHeader, correlationID)
+		c.SetUserContext(events.ComCorrelationID(c.UserContext(), correlationID))
+		return c.Next()
+	})
+
+	// Rotas de saúde
+	app.Get("/health", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"status":  "healthy",
+			"service": "command-service",
+		})
+	})
+
+	// Rotas de comandos: exigem token; cada comando restringe o perfil
+	api := app.Group("/api/v1", autenticador.Middleware())
+
+	// Endpoints auxiliares (para consulta rápida - não são queries do CQRS)
+	api.Get("/medicos", func(c *fiber.Ctx) error {
+		medicos, err := prescricaoHandler.ListMedicos(c.Context())
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(medicos)
+	})
+
+	api.Get("/pacientes", func(c *fiber.Ctx) error {
+		pacientes, err := prescricaoHandler.ListPacientes(c.Context())
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(pacientes)
+	})
+
+	api.Get("/medicamentos", func(c *fiber.Ctx) error {
+		medicamentos, err := prescricaoHandler.ListMedicamentos(c.Context())
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(medicamentos)
+	})
+
+	// Comando: Criar Prescrição (Write Side); aceita Idempotency-Key para repetições seguras
+	api.Post("/prescricoes", auth.ExigirPapel(auth.PapelMedico), idempotente(idempotencyStore), func(c *fiber.Ctx) error {
+		var dto domain.CriarPrescricaoDTO
+		if err := c.BodyParser(&dto); err != nil {
+			return corpoInvalido(c, err)
+		}
+
+		// O médico só prescreve em seu próprio nome
+		if dto.IDMedico != auth.Usuario(c).MedicoID {
+			return responderProblema(c, Problema{Type: "about:blank", Title: titulosProblema[403], Status: 403, Detail: "O médico só pode prescrever em seu próprio nome"})
+		}
+
+		// Com Idempotency-Key, a resposta é gravada na transação que cria a prescrição
+		prescricao, alertas, token, err := prescricaoHandler.CriarPrescricao(c.UserContext(), dto, respostaIdempotente(c))
+		if err != nil {
+			log.Printf("Erro ao criar prescrição: %v", err)
+			return erroComoProblema(c, err)
+		}
+
+		// Token de consistência: enviado de volta ao query service para ler a própria escrita
+		c.Set(domain.ConsistencyTokenHeader, token)
+		return c.Status(201).JSON(respostaCriacao(prescricao, alertas, token))
+	})
+
+	// Comando: Atualizar dosagem/horário dos medicamentos da prescrição
+	api.Put("/prescricoes/:id", auth.ExigirPapel(auth.PapelMedico), func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return responderProblema(c, Problema{Type: "about:blank", Title: titulosProblema[400], Status: 400, Detail: "ID inválido"})
+		}
+
+		var dto domain.AtualizarPrescricaoDTO
+		if err := c.BodyParser(&dto); err != nil {
+			return corpoInvalido(c, err)
+		}
+
+		medicamentos, token, err := prescricaoHandler.AtualizarPrescricao(c.UserContext(), id, dto)
+		if err != nil {
+			log.Printf("Erro ao atualizar prescrição %d: %v", id, err)
+			return erroComoProblema(c, err)
+		}
+
+		c.Set(domain.ConsistencyTokenHeader, token)
+		return c.JSON(fiber.Map{
+			"message":           "Prescrição atualizada com sucesso",
+			"medicamentos":      medicamentos,
+			"consistency_token": token,
+		})
+	})
+
+	// Comando: Cancelar Prescrição
+	api.Post("/prescricoes/:id/cancelar", auth.ExigirPapel(auth.PapelMedico), func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return responderProblema(c, Problema{Type: "about:blank", Title: titulosProblema[400], Status: 400, Detail: "ID inválido"})
+		}
+
+		var dto domain.CancelarPrescricaoDTO
+		if err := c.BodyParser(&dto); err != nil {
+			return corpoInvalido(c, err)
+		}
+
+		prescricao, token, err := prescricaoHandler.CancelarPrescricao(c.UserContext(), id, dto)
+		if err != nil {
+			log.Printf("Erro ao cancelar prescrição %d: %v", id, err)
+			return erroComoProblema(c, err)
+		}
+
+		c.Set(domain.ConsistencyTokenHeader, token)
+		return c.JSON(fiber.Map{
+			"message":           "Prescrição cancelada com sucesso",
+			"prescricao":        prescricao,
+			"consistency_token": token,
+		})
+	})
+
+	// Comando: Dispensar (total ou parcialmente) ou recusar um medicamento da prescrição
+	// O farmacêutico registrado é o usuário do token; aceita Idempotency-Key como a criação
+	api.Post("/prescricoes/:id/medicamentos/:idMedicamento/dispensacoes", auth.ExigirPapel(auth.PapelFarmacia), idempotente(idempotencyStore), func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return responderProblema(c, Problema{Type: "about:blank", Title: titulosProblema[400], Status: 400, Detail: "ID inválido"})
+		}
+		idMedicamento, err := strconv.Atoi(c.Params("idMedicamento"))
+		if err != nil {
+			return responderProblema(c, Problema{Type: "about:blank", Title: titulosProblema[400], Status: 400, Detail: "ID do medicamento inválido"})
+		}
+
+		var dto domain.DispensarMedicamentoDTO
+		if err := c.BodyParser(&dto); err != nil {
+			return corpoInvalido(c, err)
+		}
+
+		// Com Idempotency-Key, a resposta é gravada na transação da dispensação
+		dispensacao, token, err := prescricaoHandler.DispensarMedicamento(c.UserContext(), id, idMedicamento, auth.Usuario(c).Subject, dto, respostaDispensacaoIdempotente(c))
+		if err != nil {
+			log.Printf("Erro ao dispensar medicamento %d da prescrição %d: %v", idMedicamento, id, err)
+			return erroComoProblema(c, err)
+		}
+
+		c.Set(domain.ConsistencyTokenHeader, token)
+		return c.Status(201).JSON(respostaDispensacao(dispensacao, token))
+	})
+
+	// Comandos de cadastro: a alteração é propagada para as linhas já projetadas nas views
+	api.Put("/medicos/:id", auth.ExigirPapel(auth.PapelAdmin), func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "ID inválido"})
+		}
+
+		var dto domain.AtualizarMedicoDTO
+		if err := c.BodyParser(&dto); err != nil || dto.Nome == "" || dto.Especialidade == "" || dto.CRM == "" {
+			return c.Status(400).JSON(fiber.Map{"error": "Dados inválidos"})
+		}
+
+		medico, err := prescricaoHandler.AtualizarMedico(c.UserContext(), id, dto)
+		if err != nil {
+			log.Printf("Erro ao atualizar médico %d: %v", id, err)
+			return c.Status(statusDoErro(err)).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "Médico atualizado com sucesso",
+			"medico":  medico,
+		})
+	})
+
+	api.Put("/pacientes/:id", auth.ExigirPapel(auth.PapelAdmin), func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "ID inválido"})
+		}
+
+		var dto domain.AtualizarPacienteDTO
+		if err := c.BodyParser(&dto); err != nil || dto.Nome == "" || dto.DataNascimento == "" {
+			return c.Status(400).JSON(fiber.Map{"error": "Dados inválidos"})
+		}
+
+		paciente, err := prescricaoHandler.AtualizarPaciente(c.UserContext(), id, dto)
+		if err != nil {
+			log.Printf("Erro ao atualizar paciente %d: %v", id, err)
+			return c.Status(statusDoErro(err)).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		return c.JSON(fiber.Map{
+			"message":  "Paciente atualizado com sucesso",
+			"paciente": paciente,
+		})
+	})
+
+	api.Put("/medicamentos/:id", auth.ExigirPapel(auth.PapelAdmin), func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "ID inválido"})
+		}
+
+		var dto domain.AtualizarMedicamentoDTO
+		if err := c.BodyParser(&dto); err != nil || dto.Nome == "" {
+			return c.Status(400).JSON(fiber.Map{"error": "Dados inválidos"})
+		}
+
+		medicamento, err := prescricaoHandler.AtualizarMedicamento(c.UserContext(), id, dto)
+		if err != nil {
+			log.Printf("Erro ao atualizar medicamento %d: %v", id, err)
+			return c.Status(statusDoErro(err)).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		return c.JSON(fiber.Map{
+			"message":     "Medicamento atualizado com sucesso",
+			"medicamento": medicamento,
+		})
+	})
+
+	// Administração da outbox: eventos DEAD (tentativas esgotadas) ficam aqui até requeue manual
+	// Exige token de admin: os eventos expõem o payload das prescrições
+	if estrategia == events.PropagacaoOutbox {
+		admin := app.Group("/admin/outbox", autenticador.Middleware(), auth.ExigirPapel(auth.PapelAdmin))
+		registrarAdminOutbox(admin, events.NewOutboxAdmin(db))
+	}
+
+	// Iniciar servidor
+	port := os.Getenv("SERVICE_PORT")
+	if port == "" {
+		port = "3000"
+	}
+
+	// Graceful shutdown
+	go func() {
+		if err := app.Listen(":" + port); err != nil {
+			log.Fatalf("Erro ao iniciar servidor: %v", err)
+		}
+	}()
+
+	log.Printf("Command Service rodando na porta %s", port)
+
+	// Aguardar sinal de interrupção
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	<-quit
+
+	log.Println("🛑 Encerrando Command Service...")
+	if err := app.ShutdownWithContext(context.Background()); err != nil {
+		log.Printf("Erro ao encerrar servidor: %v", err)
+	}
+}
+
+// registrarAdminOutbox expõe os eventos da outbox para inspeção e requeue
+func registrarAdminOutbox(admin fiber.Router, outboxAdmin *events.OutboxAdmin) {
+	admin.Get("/dead", func(c *fiber.Ctx) error {
+		limite := c.QueryInt("limit", 50)
+		if limite <= 0 || limite > 500 {
+			return c.Status(400).JSON(fiber.Map{"error": "limit deve estar entre 1 e 500"})
+		}
+
+		eventos, err := outboxAdmin.ListarMortos(c.Context(), limite)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(fiber.Map{
+			"total":   len(eventos),
+			"eventos": eventos,
+		})
+	})
+
+	admin.Get("/:id", func(c *fiber.Ctx) error {
+		id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "ID inválido"})
+		}
+
+		evento, err := outboxAdmin.BuscarEvento(c.Context(), id)
+		if err != nil {
+			return c.Status(statusDoErro(err)).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(evento)
+	})
+
+	admin.Post("/:id/requeue", func(c *fiber.Ctx) error {
+		id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "ID inválido"})
+		}
+
+		evento, err := outboxAdmin.Reenfileirar(c.Context(), id)
+		if err != nil {
+			log.Printf("Erro ao reenfileirar evento %d da outbox: %v", id, err)
+			return c.Status(statusDoErro(err)).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		log.Printf("Evento %d da outbox reenfileirado", id)
+		return c.JSON(fiber.Map{
+			"message": "Evento reenfileirado",
+			"evento":  evento,
+		})
+	})
+}
+
+// respostaCriacao é o corpo 201 de POST /prescricoes (também gravado para a Idempotency-Key)
+func respostaCriacao(prescricao *domain.Prescricao, alertas []domain.AlertaClinico, token string) fiber.Map {
+	resposta := fiber.Map{
+		"message":           "Prescrição criada com sucesso",
+		"prescricao":        prescricao,
+		"consistency_token": token,
+	}
+	// Alertas das regras clínicas que o médico ignorou (já registrados na auditoria)
+	if len(alertas) > 0 {
+		resposta["alertas"] = alertas
+	}
+	return resposta
+}
+
+// respostaDispensacao é o corpo 201 de POST .../dispensacoes (também gravado para a Idempotency-Key)
+func respostaDispensacao(dispensacao *domain.Dispensacao, token string) fiber.Map {
+	return fiber.Map{
+		"message":           "Dispensação registrada com sucesso",
+		"dispensacao":       dispensacao,
+		"consistency_token": token,
+	}
+}
+
+// statusDoErro traduz erros de domínio dos comandos (e da administração da outbox) em status HTTP
+func statusDoErro(err error) int {
+	switch {
+	case errors.Is(err, commands.ErrPrescricaoNaoEncontrada),
+		errors.Is(err, commands.ErrMedicoNaoEncontrado),
+		errors.Is(err, commands.ErrPacienteNaoEncontrado),
+		errors.Is(err, commands.ErrMedicamentoNaoEncontrado),
+		errors.Is(err, events.ErrEventoOutboxNaoEncontrado):
+		return 404
+	case errors.Is(err, commands.ErrPrescricaoCancelada),
+		errors.Is(err, commands.ErrMedicamentoJaDispensado),
+		errors.Is(err, commands.ErrCRMDuplicado),
+		errors.Is(err, events.ErrEventoOutboxNaoMorto),
+		errors.Is(err, eventstore.ErrConflitoConcorrencia),
+		errors.Is(err, idempotencia.ErrReservaPerdida):
+		return 409
+	case errors.Is(err, commands.ErrDataNascimentoInvalida):
+		return 400
+	case errors.Is(err, commands.ErrMedicamentoNaoPrescrito):
+		return 422
+	default:
+		return 500
+	}
+}
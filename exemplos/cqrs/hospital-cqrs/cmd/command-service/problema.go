@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+
+	"hospital-cqrs/internal/domain"
+)
+
+// =========================================
+// RESPOSTAS DE ERRO (RFC 7807)
+// =========================================
+// Os comandos de prescrição respondem erros como application/problem+json. O campo "type"
+// identifica o problema; "erros" traz os campos inválidos quando houver.
+
+const contentTypeProblema = "application/problem+json"
+
+// Problema é o corpo de erro no formato RFC 7807
+type Problema struct {
+	Type     string             `json:"type"`
+	Title    string             `json:"title"`
+	Status   int                `json:"status"`
+	Detail   string             `json:"detail,omitempty"`
+	Instance string             `json:"instance,omitempty"`
+	Erros    []domain.ErroCampo `json:"erros,omitempty"`
+	// Alertas das regras clínicas que recusaram a prescrição
+	Alertas []domain.AlertaClinico `json:"alertas,omitempty"`
+}
+
+// Tipos de problema; status sem tipo próprio usam about:blank
+const (
+	problemaDadosInvalidos        = "/problemas/dados-invalidos"
+	problemaReferenciaInexistente = "/problemas/referencia-inexistente"
+	problemaRegrasClinicas        = "/problemas/regras-clinicas"
+)
+
+var titulosProblema = map[int]string{
+	400: "Dados inválidos",
+	403: "Acesso negado",
+	404: "Recurso não encontrado",
+	409: "Conflito com o estado atual",
+	422: "Requisição não processável",
+	500: "Erro interno",
+}
+
+// responderProblema envia o problema com o content type da RFC 7807
+func responderProblema(c *fiber.Ctx, p Problema) error {
+	if p.Instance == "" {
+		p.Instance = c.OriginalURL()
+	}
+	c.Status(p.Status)
+	c.Set(fiber.HeaderContentType, contentTypeProblema)
+	return c.JSON(p)
+}
+
+// corpoInvalido responde a um corpo que não pôde ser lido como JSON
+func corpoInvalido(c *fiber.Ctx, err error) error {
+	return responderProblema(c, Problema{
+		Type:   problemaDadosInvalidos,
+		Title:  titulosProblema[400],
+		Status: 400,
+		Detail: "Corpo da requisição não é um JSON válido: " + err.Error(),
+	})
+}
+
+// erroComoProblema converte o erro de um comando no problema correspondente
+//   - campos inválidos: 400, com os campos
+//   - médico/paciente/medicamento inexistente: 422, com os campos
+//   - regras clínicas violadas sem justificativa: 422, com os alertas
+//   - erros conhecidos do domínio: status de statusDoErro
+//   - demais: 500 sem expor o erro interno (que vai para o log)
+func erroComoProblema(c *fiber.Ctx, err error) error {
+	var validacao *domain.ErroValidacao
+	if errors.As(err, &validacao) {
+		p := Problema{
+			Type:   problemaDadosInvalidos,
+			Title:  titulosProblema[400],
+			Status: 400,
+			Detail: "Um ou mais campos são inválidos",
+			Erros:  validacao.Campos,
+		}
+		if errors.Is(err, domain.ErrReferenciaInexistente) {
+			p.Type = problemaReferenciaInexistente
+			p.Title = "Referência inexistente"
+			p.Status = 422
+			p.Detail = "Um ou mais cadastros referenciados não existem"
+		}
+		return responderProblema(c, p)
+	}
+
+	var regras *domain.ErroRegrasClinicas
+	if errors.As(err, &regras) {
+		return responderProblema(c, Problema{
+			Type:    problemaRegrasClinicas,
+			Title:   "Regras clínicas violadas",
+			Status:  422,
+			Detail:  "A prescrição gera alertas clínicos; revise-a ou informe justificativa_alertas para prescrever mesmo assim",
+			Alertas: regras.Alertas,
+		})
+	}
+
+	status := statusDoErro(err)
+	p := Problema{Type: "about:blank", Title: titulosProblema[status], Status: status, Detail: err.Error()}
+	if status == 500 {
+		log.Printf("Erro interno em %s %s: %v", c.Method(), c.OriginalURL(), err)
+		p.Detail = "Não foi possível processar o comando"
+	}
+	return responderProblema(c, p)
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"hospital-cqrs/internal/events"
+)
+
+// =========================================
+// CONSUMIDOR CDC (EVENT_PROPAGATION=cdc)
+// =========================================
+// O Debezium publica as mudanças de linha das tabelas de escrita; o CDCEventHandler as
+// agrupa pela transação de origem e projeta direto nas tabelas View_* do Postgres.
+
+// consumirCDC consome os tópicos do Debezium até o ctx ser cancelado
+func consumirCDC(ctx context.Context, db *sql.DB) error {
+	brokers := os.Getenv("KAFKA_BROKERS")
+	if brokers == "" {
+		return fmt.Errorf("KAFKA_BROKERS não configurada")
+	}
+	kafkaBrokers := strings.Split(brokers, ",")
+
+	groupID := os.Getenv("KAFKA_GROUP_ID")
+	if groupID == "" {
+		groupID = "event-handler-cdc-group"
+	}
+
+	config := sarama.NewConfig()
+	config.Version = sarama.V3_5_0_0
+	config.Consumer.Group.Rebalance.Strategy = sarama.NewBalanceStrategyRoundRobin()
+	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+	config.Consumer.Return.Errors = true
+
+	// Aguardar Kafka estar disponível
+	for i := 0; i < 30; i++ {
+		broker := sarama.NewBroker(kafkaBrokers[0])
+		err := broker.Open(config)
+		if err == nil {
+			broker.Close()
+			break
+		}
+		log.Printf("Aguardando Kafka... tentativa %d/30", i+1)
+		time.Sleep(2 * time.Second)
+	}
+
+	consumerGroup, err := sarama.NewConsumerGroup(kafkaBrokers, groupID, config)
+	if err != nil {
+		return fmt.Errorf("erro ao criar consumer group: %w", err)
+	}
+
+	log.Printf("Conectado ao Kafka: %v", kafkaBrokers)
+
+	// Criar handler CDC e o agrupamento por transação de origem
+	txTimeout := 30 * time.Second
+	if v, err := time.ParseDuration(os.Getenv("CDC_TX_TIMEOUT")); err == nil && v > 0 {
+		txTimeout = v
+	}
+	cdcHandler := events.NewCDCEventHandler(db)
+	agregador := events.NewAgregadorTransacoes(cdcHandler, txTimeout)
+	consumer := &CDCConsumer{agregador: agregador}
+
+	// Topics do Debezium (formato: server-name.schema.table) e metadados de transação (BEGIN/END)
+	topics := []string{
+		topicoPrescricoes,
+		topicoPrescricaoMedicamentos,
+		topicoDispensacoes,
+		topicoMedicos,
+		topicoPacientes,
+		topicoMedicamentos,
+		topicoTransacoes,
+	}
+
+	log.Printf("Consumindo tópicos CDC: %v", topics)
+
+	go agregador.Iniciar(ctx)
+
+	// Tratamento de erros do consumer
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for err := range consumerGroup.Errors() {
+			log.Printf("❌ Erro no consumer: %v", err)
+		}
+	}()
+
+	log.Println("Event Handler aguardando eventos CDC do Debezium...")
+
+	// Consumer group: Consume retorna a cada rebalance e é chamado de novo até o ctx acabar
+	for ctx.Err() == nil {
+		if err := consumerGroup.Consume(ctx, topics, consumer); err != nil {
+			log.Printf("Erro no consumer group: %v", err)
+		}
+	}
+
+	// Close também encerra o canal de erros
+	if err := consumerGroup.Close(); err != nil {
+		log.Printf("Erro ao fechar consumer group: %v", err)
+	}
+	wg.Wait()
+	return nil
+}
+
+const (
+	topicoPrescricoes            = "hospital_db.public.prescricoes"
+	topicoPrescricaoMedicamentos = "hospital_db.public.prescricao_medicamentos"
+	topicoDispensacoes           = "hospital_db.public.dispensacoes"
+	topicoMedicos                = "hospital_db.public.medicos"
+	topicoPacientes              = "hospital_db.public.pacientes"
+	topicoMedicamentos           = "hospital_db.public.medicamentos"
+	topicoTransacoes             = "hospital_db.transaction"
+)
+
+// CDCConsumer implementa sarama.ConsumerGroupHandler para eventos CDC
+type CDCConsumer struct {
+	agregador *events.AgregadorTransacoes
+}
+
+func (c *CDCConsumer) Setup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+// Cleanup descarta transações pela metade: as partições podem ir para outro consumidor,
+// que recebe de novo as mensagens ainda não confirmadas
+func (c *CDCConsumer) Cleanup(sarama.ConsumerGroupSession) error {
+	c.agregador.Descartar()
+	return nil
+}
+
+// ConsumeClaim processa as mensagens de uma partição em sequência
+// Uma mensagem que falha é tentada de novo (com backoff) antes de seguir para a próxima:
+// marcar as seguintes pularia a que falhou e inverteria a ordem das mudanças da mesma linha.
+// Mensagens de uma transação de origem ficam em memória até ela completar, então o offset
+// é confirmado depois (confirmar), possivelmente fora de ordem; offsetsPendentes só avança
+// o commit até a primeira mensagem ainda não aplicada.
+func (c *CDCConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	pendentes := newOffsetsPendentes()
+
+	for message := range claim.Messages() {
+		log.Printf("Evento CDC recebido do tópico %s (offset: %d)", message.Topic, message.Offset)
+
+		pendentes.adicionar(message.Offset)
+		msg := message
+		confirmar := func() {
+			if proximo, ok := pendentes.confirmar(msg.Offset); ok {
+				session.MarkOffset(msg.Topic, msg.Partition, proximo, "")
+			}
+		}
+
+		if err := c.processarComRetry(session.Context(), message, confirmar); err != nil {
+			// Sessão encerrada (rebalance/shutdown): sem marcar, a mensagem será reentregue
+			return nil
+		}
+	}
+	return nil
+}
+
+// processarComRetry entrega a mensagem até conseguir ou o ctx ser cancelado
+// Eventos malformados (ErrEventoInvalido) nunca vão passar e são descartados com log
+func (c *CDCConsumer) processarComRetry(ctx context.Context, message *sarama.ConsumerMessage, confirmar func()) error {
+	espera := 500 * time.Millisecond
+	for tentativa := 1; ; tentativa++ {
+		err := c.processar(ctx, message, confirmar)
+		if err == nil {
+			return nil
+		}
+		if errors.Is(err, events.ErrEventoInvalido) {
+			log.Printf("❌ Descartando evento CDC inválido (tópico %s, offset %d): %v", message.Topic, message.Offset, err)
+			confirmar()
+			return nil
+		}
+
+		log.Printf("Erro ao processar evento CDC (tentativa %d, nova tentativa em %s): %v", tentativa, espera, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(espera):
+		}
+		if espera < 30*time.Second {
+			espera *= 2
+		}
+	}
+}
+
+// processar roteia a mensagem para o agregador conforme o tópico
+func (c *CDCConsumer) processar(ctx context.Context, message *sarama.ConsumerMessage, confirmar func()) error {
+	switch message.Topic {
+	case topicoPrescricoes:
+		return c.agregador.ReceberMudanca(ctx, events.TabelaPrescricoes, message.Value, confirmar)
+	case topicoPrescricaoMedicamentos:
+		return c.agregador.ReceberMudanca(ctx, events.TabelaPrescricaoMedicamentos, message.Value, confirmar)
+	case topicoDispensacoes:
+		return c.agregador.ReceberMudanca(ctx, events.TabelaDispensacoes, message.Value, confirmar)
+	case topicoMedicos:
+		return c.agregador.ReceberMudanca(ctx, events.TabelaMedicos, message.Value, confirmar)
+	case topicoPacientes:
+		return c.agregador.ReceberMudanca(ctx, events.TabelaPacientes, message.Value, confirmar)
+	case topicoMedicamentos:
+		return c.agregador.ReceberMudanca(ctx, events.TabelaMedicamentos, message.Value, confirmar)
+	case topicoTransacoes:
+		return c.agregador.ReceberMarcador(ctx, message.Value, confirmar)
+	default:
+		log.Printf("Tópico desconhecido: %s", message.Topic)
+		confirmar()
+		return nil
+	}
+}
+
+// offsetsPendentes acompanha as mensagens de uma partição ainda não aplicadas
+type offsetsPendentes struct {
+	mu       sync.Mutex
+	ordem    []int64        // offsets recebidos, em ordem
+	aplicado map[int64]bool // offsets já confirmados
+}
+
+func newOffsetsPendentes() *offsetsPendentes {
+	return &offsetsPendentes{aplicado: make(map[int64]bool)}
+}
+
+func (o *offsetsPendentes) adicionar(offset int64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.ordem = append(o.ordem, offset)
+}
+
+// confirmar registra o offset como aplicado e devolve o próximo offset a commitar,
+// se o início da fila avançou
+func (o *offsetsPendentes) confirmar(offset int64) (int64, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.aplicado[offset] = true
+	avancou := false
+	var proximo int64
+	for len(o.ordem) > 0 && o.aplicado[o.ordem[0]] {
+		delete(o.aplicado, o.ordem[0])
+		proximo = o.ordem[0] + 1
+		o.ordem = o.ordem[1:]
+		avancou = true
+	}
+	return proximo, avancou
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"hospital-cqrs/internal/events"
+	"hospital-cqrs/internal/eventstore"
+	"hospital-cqrs/internal/readmodel"
+	"hospital-cqrs/pkg/database"
+	"hospital-cqrs/pkg/kafka"
+)
+
+func main() {
+	log.Println("Iniciando Event Handler (Async Processor - CQRS)...")
+
+	// Estratégia de propagação: a mesma do command service (EVENT_PROPAGATION)
+	estrategia, err := events.Propagacao()
+	if err != nil {
+		log.Fatalf("Erro de configuração: %v", err)
+	}
+
+	backend, err := readmodel.Backend()
+	if err != nil {
+		log.Fatalf("Erro de configuração: %v", err)
+	}
+	if estrategia == events.PropagacaoCDC && backend != readmodel.BackendPostgres {
+		log.Fatalf("Erro de configuração: com EVENT_PROPAGATION=%s as views são projetadas nas tabelas View_* (use READ_MODEL_BACKEND=%s)",
+			events.PropagacaoCDC, readmodel.BackendPostgres)
+	}
+
+	// Conectar ao banco de dados
+	db, err := database.Connect()
+	if err != nil {
+		log.Fatalf("Erro ao conectar ao banco de dados: %v", err)
+	}
+	defer db.Close()
+
+	// Criar handler de eventos: dados de referência no Postgres, views no modelo de leitura configurado
+	views := events.NewModeloLeituraPostgres(db)
+	if backend == readmodel.BackendRedis {
+		redis, err := readmodel.ConectarRedis(context.Background())
+		if err != nil {
+			log.Fatalf("Erro ao conectar ao modelo de leitura: %v", err)
+		}
+		defer redis.Close()
+		views = events.NewModeloLeituraRedis(redis)
+	}
+	log.Printf("Propagação: %s, modelo de leitura: %s", estrategia, backend)
+
+	// Context para cancelamento
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Goroutine para consumir eventos: de domínio (direto/outbox), mudanças de linha (cdc)
+	// ou a assinatura do event store (eventstore)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var err error
+		switch estrategia {
+		case events.PropagacaoCDC:
+			err = consumirCDC(ctx, db)
+		case events.PropagacaoEventStore:
+			err = consumirEventStore(ctx, db, views)
+		default:
+			err = consumirEventos(ctx, db, views)
+		}
+		if err != nil {
+			log.Printf("Erro no consumidor: %v", err)
+		}
+	}()
+
+	// Aguardar sinal de interrupção (ou falha do consumidor)
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	select {
+	case <-quit:
+	case <-done:
+	}
+
+	log.Println("Encerrando Event Handler...")
+	cancel()
+	<-done
+}
+
+// consumirEventos consome os eventos de domínio até o ctx ser cancelado
+// Publicados pelo command service (direto, tópico prescricoes) ou pelo outbox-relay (outbox):
+// os tópicos são os das rotas de OUTBOX_TOPIC_ROUTES, a mesma configuração do relay
+// O offset só é confirmado após o processamento; eventos malformados vão direto para a DLQ
+func consumirEventos(ctx context.Context, db *sql.DB, views events.ModeloLeitura) error {
+	eventHandler := events.NewPrescricaoEventHandlerComViews(db, views)
+
+	router, err := events.TopicRouterDoAmbiente("prescricoes")
+	if err != nil {
+		return fmt.Errorf("erro na configuração de tópicos: %w", err)
+	}
+
+	consumer, err := kafka.NewConsumerTopicos(router.Topicos(), "")
+	if err != nil {
+		return fmt.Errorf("erro ao criar consumidor Kafka: %w", err)
+	}
+	defer consumer.Close()
+
+	log.Println("Event Handler iniciado, aguardando eventos...")
+
+	return consumer.Consume(ctx, func(message []byte) error {
+		err := eventHandler.HandleEvent(ctx, message)
+		if errors.Is(err, events.ErrEventoInvalido) {
+			return fmt.Errorf("%w: %v", kafka.ErrMensagemInvalida, err)
+		}
+		return err
+	})
+}
+
+// consumirEventStore assina Event_Store e projeta os eventos na ordem global até o ctx ser cancelado
+// O checkpoint da assinatura fica em Projection_Checkpoints; o handler deduplica por id de evento,
+// então a reentrega após uma queda não duplica nada. Eventos malformados são registrados e pulados;
+// outras falhas são reentregues e, esgotadas as tentativas, estacionadas em Event_Store_Dead_Letters
+// (o evento continua no store e volta num replay depois de corrigida a causa).
+func consumirEventStore(ctx context.Context, db *sql.DB, views events.ModeloLeitura) error {
+	eventHandler := events.NewPrescricaoEventHandlerComViews(db, views)
+
+	assinatura := eventstore.NewAssinatura(db, eventstore.NewStore(db, 0), "projecoes-views")
+	if err := assinatura.EscutarNotificacoes(os.Getenv("DATABASE_URL"), 30*time.Second); err != nil {
+		log.Printf("AVISO: LISTEN indisponível, usando apenas polling: %v", err)
+	}
+
+	log.Println("Event Handler iniciado, assinando o event store...")
+
+	return assinatura.Executar(ctx, func(ctx context.Context, evento eventstore.EventoArmazenado) error {
+		err := eventHandler.HandleEvent(ctx, evento.Payload)
+		if errors.Is(err, events.ErrEventoInvalido) {
+			log.Printf("ERRO: evento %d (%s v%d) inválido, ignorado: %v", evento.Posicao, evento.StreamID, evento.Versao, err)
+			return nil
+		}
+		return err
+	})
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"hospital-cqrs/pkg/auth"
+)
+
+// gerar-token emite um JWT para desenvolvimento local, assinado com JWT_SECRET
+//
+//	JWT_SECRET=dev-secret go run ./cmd/gerar-token -papel medico -medico-id 1
+//	JWT_SECRET=dev-secret go run ./cmd/gerar-token -papel paciente -paciente-id 2
+//	JWT_SECRET=dev-secret go run ./cmd/gerar-token -papel farmacia -sub farmacia-ana
+func main() {
+	papel := flag.String("papel", "", "medico, farmacia, paciente ou admin")
+	sub := flag.String("sub", "", "identificação do usuário (obrigatória para farmacia e admin; padrão: <papel>-<id>)")
+	medicoID := flag.Int("medico-id", 0, "id do médico (obrigatório para o papel medico)")
+	pacienteID := flag.Int("paciente-id", 0, "id do paciente (obrigatório para o papel paciente)")
+	validade := flag.Duration("validade", 8*time.Hour, "validade do token")
+	flag.Parse()
+
+	autenticador, err := auth.NewAutenticador()
+	if err != nil {
+		log.Fatalf("Erro de configuração: %v", err)
+	}
+
+	claims := auth.Claims{
+		Papel:      auth.Papel(*papel),
+		MedicoID:   *medicoID,
+		PacienteID: *pacienteID,
+	}
+	// Farmácia e admin não têm id para derivar o sub: um padrão comum juntaria todos os usuários
+	// do papel em uma identidade só (o sub identifica o farmacêutico nas dispensações e separa
+	// as Idempotency-Keys de cada usuário)
+	claims.Subject = *sub
+	if claims.Subject == "" {
+		if claims.Papel == auth.PapelFarmacia || claims.Papel == auth.PapelAdmin {
+			log.Fatalf("Informe -sub para o papel %s (identificação única do usuário)", *papel)
+		}
+		claims.Subject = *papel
+		if id := max(*medicoID, *pacienteID); id > 0 {
+			claims.Subject = fmt.Sprintf("%s-%d", *papel, id)
+		}
+	}
+
+	token, err := autenticador.Emitir(claims, *validade)
+	if err != nil {
+		log.Fatalf("Erro ao emitir token: %v", err)
+	}
+	fmt.Println(token)
+}
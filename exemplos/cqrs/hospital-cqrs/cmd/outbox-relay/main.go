@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"hospital-cqrs/internal/events"
+	"hospital-cqrs/pkg/database"
+	"hospital-cqrs/pkg/kafka"
+)
+
+func main() {
+	log.Println("Iniciando Outbox Relay (Event Publisher - CQRS)...")
+	log.Println("Usado com EVENT_PROPAGATION=outbox: publica no Kafka os eventos gravados pelo command service")
+
+	// Conectar ao banco de dados
+	db, err := database.Connect()
+	if err != nil {
+		log.Fatalf("Erro ao conectar ao banco de dados: %v", err)
+	}
+	defer db.Close()
+
+	log.Println("Conectado ao PostgreSQL")
+
+	// Criar producer Kafka
+	producer, err := kafka.NewProducer()
+	if err != nil {
+		log.Fatalf("Erro ao criar producer Kafka: %v", err)
+	}
+	defer producer.Close()
+
+	log.Println("Conectado ao Kafka")
+
+	// Roteamento de tópicos: OUTBOX_TOPIC_ROUTES sobrescreve as regras padrão
+	router, err := topicRouter(producer.GetTopic())
+	if err != nil {
+		log.Fatalf("Erro na configuração de tópicos: %v", err)
+	}
+
+	// Criar Outbox Relay (o id da instância aparece nos leases da outbox)
+	relay := events.NewOutboxRelay(db, producer, router, instanceID())
+
+	// LISTEN/NOTIFY: publicar assim que o comando confirma; polling fica como fallback
+	if err := relay.EscutarNotificacoes(os.Getenv("DATABASE_URL"), 30*time.Second); err != nil {
+		log.Printf("AVISO: LISTEN indisponível, usando apenas polling: %v", err)
+		log.Println("Verificando eventos pendentes a cada 2 segundos...")
+	} else {
+		log.Printf("Escutando canal %s (polling de fallback a cada 30 segundos)", events.OutboxNotifyChannel)
+	}
+
+	// Métricas de atraso criação → publicação em /debug/vars (expvar)
+	expvar.Publish("outbox_publish_delay", expvar.Func(func() interface{} {
+		return relay.Metrics().Snapshot()
+	}))
+	if port := os.Getenv("METRICS_PORT"); port != "" {
+		go func() {
+			log.Printf("Métricas do relay em :%s/debug/vars", port)
+			if err := http.ListenAndServe(":"+port, nil); err != nil {
+				log.Printf("Erro no servidor de métricas: %v", err)
+			}
+		}()
+	}
+
+	log.Println("Outbox Relay configurado")
+
+	// Context para cancelamento
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Goroutine para executar o relay
+	go func() {
+		if err := relay.Start(ctx); err != nil && err != context.Canceled {
+			log.Printf("Erro no relay: %v", err)
+		}
+	}()
+
+	// Goroutine para limpeza periódica (housekeeping) de eventos PROCESSED
+	// Roda uma vez na subida e depois a cada OUTBOX_CLEANUP_INTERVAL; eventos DEAD não são apagados
+	retentionDays := envInt("OUTBOX_RETENTION_DAYS", 7)
+	cleanupInterval := envDuration("OUTBOX_CLEANUP_INTERVAL", time.Hour)
+	log.Printf("Limpeza da outbox a cada %s (retenção: %d dias)", cleanupInterval, retentionDays)
+
+	go func() {
+		ticker := time.NewTicker(cleanupInterval)
+		defer ticker.Stop()
+
+		for {
+			log.Println("Executando limpeza de eventos processados...")
+			if err := relay.LimparEventosProcessados(ctx, retentionDays); err != nil {
+				log.Printf("Erro na limpeza: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	// Goroutine para monitoramento
+	go func() {
+		ticker := time.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				count, err := relay.GetPendingCount(ctx)
+				if err != nil {
+					log.Printf("Erro ao obter contagem: %v", err)
+					continue
+				}
+				if count > 0 {
+					log.Printf("Eventos pendentes na Outbox: %d", count)
+				}
+
+				m := relay.Metrics().Snapshot()
+				if m.Publicados > 0 {
+					log.Printf("Atraso de publicação: último=%.0fms médio=%.0fms máximo=%.0fms (%d eventos)",
+						m.UltimoMs, m.MedioMs, m.MaximoMs, m.Publicados)
+				}
+			}
+		}
+	}()
+
+	// Aguardar sinal de interrupção
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	<-quit
+
+	log.Println("Encerrando Outbox Relay...")
+	cancel()
+
+	// Aguardar um pouco para o lote em andamento terminar
+	time.Sleep(2 * time.Second)
+	log.Println("Outbox Relay encerrado")
+}
+
+// topicRouter monta as regras de roteamento de tópicos
+// Formato de OUTBOX_TOPIC_ROUTES: "aggregate_type:event_type=topico,..." (ver events.ParseTopicRules)
+// O event handler lê a mesma variável e consome todos os tópicos das rotas
+func topicRouter(defaultTopic string) (*events.TopicRouter, error) {
+	router, err := events.TopicRouterDoAmbiente(defaultTopic)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range router.Regras() {
+		log.Printf("Rota de tópico: %s/%s → %s", valorOuTodos(rule.AggregateType), valorOuTodos(rule.EventType), rule.Topic)
+	}
+	return router, nil
+}
+
+func valorOuTodos(v string) string {
+	if v == "" {
+		return "*"
+	}
+	return v
+}
+
+// instanceID identifica esta réplica do relay; RELAY_INSTANCE_ID tem prioridade sobre hostname/pid
+func instanceID() string {
+	if id := os.Getenv("RELAY_INSTANCE_ID"); id != "" {
+		return id
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "relay"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// envInt lê um inteiro positivo do ambiente, usando o padrão se ausente ou inválido
+func envInt(nome string, padrao int) int {
+	if v, err := strconv.Atoi(os.Getenv(nome)); err == nil && v > 0 {
+		return v
+	}
+	return padrao
+}
+
+// envDuration lê uma duração (ex: "30m", "1h") do ambiente, usando o padrão se ausente ou inválida
+func envDuration(nome string, padrao time.Duration) time.Duration {
+	if v, err := time.ParseDuration(os.Getenv(nome)); err == nil && v > 0 {
+		return v
+	}
+	return padrao
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"hospital-cqrs/internal/events"
+	"hospital-cqrs/internal/projections"
+	"hospital-cqrs/internal/readmodel"
+	"hospital-cqrs/pkg/database"
+)
+
+// rebuild-views reconstrói View_Farmacia e View_Prontuario_Paciente a partir do modelo de escrita.
+// Útil depois de um bug de projeção: corrige o handler, roda o rebuild e as views voltam a refletir
+// Prescricoes/Prescricao_Medicamentos sem SQL manual e sem derrubar o query service.
+//
+// Uso:
+//
+//	go run ./cmd/rebuild-views                 # todas as views
+//	go run ./cmd/rebuild-views -view farmacia  # apenas a view da farmácia
+func main() {
+	viewFlag := flag.String("view", "todas", "view a reconstruir: farmacia, prontuario ou todas")
+	flag.Parse()
+
+	views := projections.Views()
+	if *viewFlag != "todas" {
+		views = []projections.View{projections.View(*viewFlag)}
+	}
+
+	log.Println("Iniciando rebuild das views de leitura (CQRS)...")
+
+	// Com o event store não há Prescricoes para comparar: as views se refazem pelo replay
+	if estrategia, err := events.Propagacao(); err == nil && estrategia == events.PropagacaoEventStore {
+		log.Fatalf("Com EVENT_PROPAGATION=%s o modelo de escrita é o event store: use make replay-views (a assinatura reprojeta todos os eventos)", events.PropagacaoEventStore)
+	}
+
+	// O rebuild refaz as tabelas View_*: com o Redis o query service não as lê
+	backend, err := readmodel.Backend()
+	if err != nil {
+		log.Fatalf("Erro de configuração: %v", err)
+	}
+	if backend != readmodel.BackendPostgres {
+		log.Fatalf("Com READ_MODEL_BACKEND=%s o query service lê o Redis e rebuild-views só reconstrói as tabelas View_* do Postgres (use READ_MODEL_BACKEND=%s)",
+			backend, readmodel.BackendPostgres)
+	}
+
+	// Conectar ao banco de dados
+	db, err := database.Connect()
+	if err != nil {
+		log.Fatalf("Erro ao conectar ao banco de dados: %v", err)
+	}
+	defer db.Close()
+
+	// Cancelar o rebuild em andamento (rollback) ao receber sinal de interrupção
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	rebuilder := projections.NewRebuilder(db)
+	for _, view := range views {
+		if _, err := rebuilder.Rebuild(ctx, view); err != nil {
+			log.Fatalf("Erro ao reconstruir view %s: %v", view, err)
+		}
+	}
+
+	log.Println("Rebuild concluído")
+}
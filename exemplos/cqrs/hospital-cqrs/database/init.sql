@@ -0,0 +1,409 @@
+-- =========================================
+-- HOSPITAL CQRS - DATABASE INITIALIZATION
+-- =========================================
+
+-- =========================================
+-- COMMAND MODEL (Write Side - Normalized)
+-- =========================================
+
+-- Tabela de Médicos
+CREATE TABLE IF NOT EXISTS Medicos (
+    id SERIAL PRIMARY KEY,
+    nome VARCHAR(255) NOT NULL,
+    especialidade VARCHAR(255) NOT NULL,
+    crm VARCHAR(255) NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Tabela de Pacientes
+CREATE TABLE IF NOT EXISTS Pacientes (
+    id SERIAL PRIMARY KEY,
+    nome VARCHAR(255) NOT NULL,
+    data_nascimento DATE NOT NULL,
+    endereco VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Tabela de Medicamentos
+CREATE TABLE IF NOT EXISTS Medicamentos (
+    id SERIAL PRIMARY KEY,
+    nome VARCHAR(255) NOT NULL,
+    descricao TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Tabela de Prescrições (Modelo de Comando)
+CREATE TABLE IF NOT EXISTS Prescricoes (
+    id SERIAL PRIMARY KEY,
+    id_medico INT NOT NULL,
+    id_paciente INT NOT NULL,
+    data_prescricao TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    status VARCHAR(20) NOT NULL DEFAULT 'ATIVA',  -- ATIVA | CANCELADA
+    motivo_cancelamento TEXT NULL,
+    cancelada_em TIMESTAMP NULL,
+    versao INT NOT NULL DEFAULT 1,                -- incrementada a cada comando (token de consistência)
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (id_medico) REFERENCES Medicos(id),
+    FOREIGN KEY (id_paciente) REFERENCES Pacientes(id)
+);
+
+-- Tabela de Relacionamento Prescrição-Medicamentos
+CREATE TABLE IF NOT EXISTS Prescricao_Medicamentos (
+    id SERIAL PRIMARY KEY,
+    id_prescricao INT NOT NULL,
+    id_medicamento INT NOT NULL,
+    horario VARCHAR(50) NOT NULL,
+    dosagem VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (id_prescricao) REFERENCES Prescricoes(id) ON DELETE CASCADE,
+    FOREIGN KEY (id_medicamento) REFERENCES Medicamentos(id)
+);
+
+-- Índices para otimizar buscas no modelo de comando
+CREATE INDEX idx_prescricoes_medico ON Prescricoes(id_medico);
+CREATE INDEX idx_prescricoes_paciente ON Prescricoes(id_paciente);
+CREATE INDEX idx_prescricao_medicamentos_prescricao ON Prescricao_Medicamentos(id_prescricao);
+
+-- Dispensações da farmácia: cada registro entrega (total ou parcialmente) ou recusa um
+-- medicamento prescrito. É append-only; o status de dispensação do item é o do registro mais
+-- recente (sem registros, PENDENTE). Depois de DISPENSADO o item não aceita nova dispensação.
+CREATE TABLE IF NOT EXISTS Dispensacoes (
+    id SERIAL PRIMARY KEY,
+    id_prescricao INT NOT NULL,
+    id_prescricao_medicamento INT NOT NULL,
+    id_medicamento INT NOT NULL,
+    status VARCHAR(20) NOT NULL
+        CHECK (status IN ('DISPENSADO', 'PARCIAL', 'RECUSADO')),
+    quantidade VARCHAR(50) NULL,               -- quantidade entregue (obrigatória em PARCIAL)
+    motivo TEXT NULL,                          -- obrigatório em RECUSADO
+    farmaceutico VARCHAR(255) NOT NULL,        -- subject do JWT de quem registrou
+    dispensado_em TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (id_prescricao) REFERENCES Prescricoes(id) ON DELETE CASCADE,
+    FOREIGN KEY (id_prescricao_medicamento) REFERENCES Prescricao_Medicamentos(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_dispensacoes_item ON Dispensacoes(id_prescricao_medicamento, dispensado_em);
+
+-- Idempotency-Key de POST /prescricoes: a mesma chave do mesmo usuário devolve a resposta
+-- original em vez de criar a prescrição de novo (chaves valem por 24h)
+-- A resposta é gravada na transação do comando: prescrição criada implica resposta gravada
+CREATE TABLE IF NOT EXISTS Idempotency_Keys (
+    usuario VARCHAR(255) NOT NULL,             -- subject do JWT: cada usuário tem suas chaves
+    chave VARCHAR(255) NOT NULL,
+    hash_requisicao CHAR(64) NOT NULL,         -- SHA-256 de método, rota e corpo
+    reserva_id VARCHAR(36) NOT NULL,           -- Requisição dona da reserva (muda se for retomada)
+    status_resposta INT NULL,                  -- NULL enquanto a requisição está em processamento
+    corpo_resposta BYTEA NULL,
+    cabecalhos_resposta JSONB NULL,
+    criada_em TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    reservada_em TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (usuario, chave)
+);
+
+-- Auditoria das regras clínicas: prescrições criadas apesar de alertas de interação,
+-- duplicidade terapêutica ou dose máxima (evento prescricao.alertas_ignorados)
+-- Sem FK para Prescricoes: com EVENT_PROPAGATION=eventstore a prescrição só existe no Event_Store
+CREATE TABLE IF NOT EXISTS Auditoria_Regras_Clinicas (
+    id SERIAL PRIMARY KEY,
+    event_id VARCHAR(36) NOT NULL UNIQUE,      -- Id do evento prescricao.alertas_ignorados
+    id_prescricao INT NOT NULL,
+    id_medico INT NOT NULL REFERENCES Medicos(id),
+    id_paciente INT NOT NULL REFERENCES Pacientes(id),
+    modo VARCHAR(20) NOT NULL CHECK (modo IN ('bloquear', 'alertar')),
+    justificativa TEXT NULL,                   -- Obrigatória no modo bloquear
+    alertas JSONB NOT NULL,                    -- Alertas ignorados (tipo, medicamentos, mensagem)
+    registrado_em TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_auditoria_regras_prescricao ON Auditoria_Regras_Clinicas(id_prescricao);
+CREATE INDEX idx_auditoria_regras_medico ON Auditoria_Regras_Clinicas(id_medico, registrado_em);
+
+-- CDC: o evento de DELETE precisa da linha completa (não só a PK) para localizar
+-- as linhas das views pela chave (id_prescricao, medicamento_id)
+ALTER TABLE Prescricoes REPLICA IDENTITY FULL;
+ALTER TABLE Prescricao_Medicamentos REPLICA IDENTITY FULL;
+
+-- =========================================
+-- OUTBOX PATTERN
+-- =========================================
+
+-- Tabela de Outbox para armazenar eventos pendentes de publicação
+-- Garante atomicidade entre persistência e publicação de eventos
+CREATE TABLE IF NOT EXISTS Outbox_Events (
+    id SERIAL PRIMARY KEY,
+    aggregate_type VARCHAR(255) NOT NULL,      -- Tipo do agregado (ex: 'prescricao')
+    aggregate_id VARCHAR(255) NOT NULL,        -- ID do agregado
+    event_type VARCHAR(255) NOT NULL,          -- Tipo do evento (ex: 'prescricao.criada', 'prescricao.cancelada')
+    payload JSONB NOT NULL,                    -- Payload do evento em JSON
+    event_id VARCHAR(36) NOT NULL,             -- Id do evento (UUID do envelope), enviado em header Kafka
+    schema_version INT NOT NULL DEFAULT 1,     -- Versão de schema do payload
+    correlation_id VARCHAR(100) NULL,          -- Correlation id da requisição que originou o evento
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING'
+        CHECK (status IN ('PENDING', 'PROCESSED', 'DEAD')), -- DEAD = tentativas esgotadas, aguarda requeue manual
+    processed_at TIMESTAMP NULL,               -- NULL = não processado, timestamp = processado
+    published_at TIMESTAMP NULL,               -- Quando foi publicado no Kafka
+    error_message TEXT NULL,                   -- Mensagem de erro se houver falha
+    retry_count INT DEFAULT 0,                 -- Contador de tentativas
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Próxima tentativa (backoff exponencial)
+    dead_at TIMESTAMP NULL,                    -- Quando o evento foi para DEAD
+    locked_by VARCHAR(255) NULL,               -- Instância do relay que reivindicou o evento
+    locked_until TIMESTAMP NULL                -- Fim do lease; após isso outra instância pode reivindicar
+);
+
+-- Índices para otimizar o relay de outbox
+CREATE INDEX idx_outbox_not_processed ON Outbox_Events(next_attempt_at) WHERE status = 'PENDING';
+CREATE INDEX idx_outbox_dead ON Outbox_Events(dead_at) WHERE status = 'DEAD';
+CREATE INDEX idx_outbox_created_at ON Outbox_Events(created_at);
+CREATE INDEX idx_outbox_aggregate ON Outbox_Events(aggregate_type, aggregate_id);
+CREATE INDEX idx_outbox_event_type ON Outbox_Events(event_type);
+CREATE INDEX idx_outbox_pending_aggregate ON Outbox_Events(aggregate_type, aggregate_id, id) WHERE status <> 'PROCESSED';
+
+-- =========================================
+-- EVENT STORE (EVENT_PROPAGATION=eventstore)
+-- =========================================
+
+-- Streams de eventos por agregado (prescricao-<id>, medico-<id>...), append-only
+-- posicao é a ordem global lida pelas assinaturas; versao é a ordem dentro do stream
+CREATE TABLE IF NOT EXISTS Event_Store (
+    posicao BIGSERIAL PRIMARY KEY,
+    stream_id VARCHAR(255) NOT NULL,           -- Agregado dono do evento (ex: 'prescricao-123')
+    versao INT NOT NULL,                       -- Versão do stream após o evento (1, 2, 3...)
+    event_id VARCHAR(36) NOT NULL UNIQUE,      -- Id do evento (UUID do envelope)
+    event_type VARCHAR(255) NOT NULL,          -- Tipo do evento (ex: 'prescricao.criada')
+    schema_version INT NOT NULL DEFAULT 1,     -- Versão de schema do payload como gravado
+    payload JSONB NOT NULL,                    -- Envelope completo do evento
+    correlation_id VARCHAR(100) NULL,          -- Correlation id da requisição que originou o evento
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (stream_id, versao)                 -- Concorrência otimista: dois comandos não gravam a mesma versão
+);
+
+CREATE INDEX idx_event_store_type ON Event_Store(event_type);
+-- Prescrições de um paciente (regras clínicas avaliam as ativas antes de criar outra)
+CREATE INDEX idx_event_store_paciente ON Event_Store (((payload->'data'->>'id_paciente')::int))
+    WHERE event_type = 'prescricao.criada';
+
+-- Eventos não se alteram nem se apagam: correções são novos eventos
+CREATE OR REPLACE FUNCTION event_store_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'Event_Store é append-only: % não permitido', TG_OP;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_event_store_append_only
+    BEFORE UPDATE OR DELETE ON Event_Store
+    FOR EACH ROW EXECUTE FUNCTION event_store_append_only();
+
+-- Último snapshot de cada stream (cache do estado; pode ser apagado a qualquer momento)
+CREATE TABLE IF NOT EXISTS Snapshots (
+    stream_id VARCHAR(255) PRIMARY KEY,
+    versao INT NOT NULL,                       -- Versão do stream refletida no estado
+    schema_version INT NOT NULL,               -- Formato do estado; outro formato é ignorado
+    estado JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Última posição do event store processada por cada assinatura (projeção)
+-- Apagar a linha de uma assinatura faz ela reprojetar todos os eventos
+CREATE TABLE IF NOT EXISTS Projection_Checkpoints (
+    assinatura VARCHAR(100) PRIMARY KEY,
+    posicao BIGINT NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Eventos que uma assinatura desistiu de projetar (tentativas esgotadas); o checkpoint segue
+-- adiante e o evento continua no Event_Store para um replay depois de corrigida a causa
+CREATE TABLE IF NOT EXISTS Event_Store_Dead_Letters (
+    assinatura VARCHAR(100) NOT NULL,
+    posicao BIGINT NOT NULL,                   -- Posição do evento em Event_Store
+    stream_id VARCHAR(255) NOT NULL,
+    versao INT NOT NULL,
+    erro TEXT NOT NULL,                        -- Erro da última tentativa
+    tentativas INT NOT NULL,
+    estacionado_em TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (assinatura, posicao)
+);
+
+-- =========================================
+-- QUERY MODELS (Read Side - Denormalized)
+-- =========================================
+
+-- Query Model 1: View de Farmácia
+-- Modelo otimizado para a farmácia visualizar prescrições e separar medicamentos
+CREATE TABLE IF NOT EXISTS View_Farmacia (
+    id SERIAL PRIMARY KEY,
+    id_prescricao INT NOT NULL,
+    data_prescricao TIMESTAMP NOT NULL,
+    
+    -- Dados do Paciente
+    paciente_id INT NOT NULL,
+    paciente_nome VARCHAR(255) NOT NULL,
+    paciente_data_nascimento DATE NOT NULL,
+    
+    -- Dados do Medicamento
+    medicamento_id INT NOT NULL,
+    medicamento_nome VARCHAR(255) NOT NULL,
+    medicamento_descricao TEXT,
+    horario VARCHAR(50) NOT NULL,
+    dosagem VARCHAR(50) NOT NULL,
+    
+    -- Dispensação mais recente do item (PENDENTE | DISPENSADO | PARCIAL | RECUSADO)
+    status_dispensacao VARCHAR(20) NOT NULL DEFAULT 'PENDENTE',
+    
+    -- Metadados
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Índices para otimizar consultas da farmácia
+CREATE INDEX idx_view_farmacia_prescricao ON View_Farmacia(id_prescricao);
+CREATE INDEX idx_view_farmacia_paciente ON View_Farmacia(paciente_id);
+CREATE INDEX idx_view_farmacia_medicamento ON View_Farmacia(medicamento_id);
+CREATE INDEX idx_view_farmacia_data ON View_Farmacia(data_prescricao);
+-- Chave natural da view: um medicamento por prescrição (permite upsert idempotente)
+CREATE UNIQUE INDEX ux_view_farmacia_prescricao_medicamento ON View_Farmacia(id_prescricao, medicamento_id);
+
+-- Query Model 2: View de Prontuário do Paciente
+-- Modelo otimizado para visualizar histórico completo do paciente
+CREATE TABLE IF NOT EXISTS View_Prontuario_Paciente (
+    id SERIAL PRIMARY KEY,
+    id_prescricao INT NOT NULL,
+    data_prescricao TIMESTAMP NOT NULL,
+    
+    -- Dados do Paciente
+    paciente_id INT NOT NULL,
+    paciente_nome VARCHAR(255) NOT NULL,
+    paciente_data_nascimento DATE NOT NULL,
+    paciente_endereco VARCHAR(255),
+    
+    -- Dados do Médico
+    medico_id INT NOT NULL,
+    medico_nome VARCHAR(255) NOT NULL,
+    medico_especialidade VARCHAR(255) NOT NULL,
+    medico_crm VARCHAR(255) NOT NULL,
+    
+    -- Situação da prescrição (ATIVA | CANCELADA) - o prontuário mantém o histórico
+    status VARCHAR(20) NOT NULL DEFAULT 'ATIVA',
+    
+    -- Dados do Medicamento
+    medicamento_id INT NOT NULL,
+    medicamento_nome VARCHAR(255) NOT NULL,
+    medicamento_descricao TEXT,
+    horario VARCHAR(50) NOT NULL,
+    dosagem VARCHAR(50) NOT NULL,
+    
+    -- Metadados
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Índices para otimizar consultas de prontuário
+CREATE INDEX idx_view_prontuario_prescricao ON View_Prontuario_Paciente(id_prescricao);
+CREATE INDEX idx_view_prontuario_paciente ON View_Prontuario_Paciente(paciente_id);
+CREATE INDEX idx_view_prontuario_medico ON View_Prontuario_Paciente(medico_id);
+CREATE INDEX idx_view_prontuario_data ON View_Prontuario_Paciente(data_prescricao);
+CREATE UNIQUE INDEX ux_view_prontuario_prescricao_medicamento ON View_Prontuario_Paciente(id_prescricao, medicamento_id);
+
+-- Histórico do prontuário: intervalos de validade de cada medicamento prescrito
+-- Toda mudança de status, horário ou dosagem fecha o intervalo vigente (valido_ate) e abre
+-- outro; o prontuário em uma data (as_of) é o conjunto de intervalos que a contêm.
+-- Os dados de cadastro (nomes, CRM, endereço) vêm da linha atual de View_Prontuario_Paciente.
+CREATE TABLE IF NOT EXISTS View_Prontuario_Historico (
+    id SERIAL PRIMARY KEY,
+    id_prescricao INT NOT NULL,
+    medicamento_id INT NOT NULL,
+    paciente_id INT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    horario VARCHAR(50) NOT NULL,
+    dosagem VARCHAR(50) NOT NULL,
+    valido_de TIMESTAMP NOT NULL,              -- Início da validade (instante da mudança no modelo de escrita)
+    valido_ate TIMESTAMP NULL,                 -- Fim da validade (NULL = vigente)
+    CHECK (valido_ate IS NULL OR valido_ate >= valido_de)
+);
+
+CREATE INDEX idx_view_prontuario_historico_paciente ON View_Prontuario_Historico(paciente_id, valido_de);
+CREATE INDEX idx_view_prontuario_historico_item ON View_Prontuario_Historico(id_prescricao, medicamento_id);
+-- No máximo um intervalo vigente por item
+CREATE UNIQUE INDEX ux_view_prontuario_historico_vigente ON View_Prontuario_Historico(id_prescricao, medicamento_id) WHERE valido_ate IS NULL;
+
+-- Histórico de dispensações de cada medicamento no prontuário
+-- id_dispensacao é o id em Dispensacoes: reaplicar o evento não duplica o registro
+CREATE TABLE IF NOT EXISTS View_Prontuario_Dispensacoes (
+    id_dispensacao INT PRIMARY KEY,
+    id_prescricao INT NOT NULL,
+    medicamento_id INT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    quantidade VARCHAR(50) NULL,
+    motivo TEXT NULL,
+    farmaceutico VARCHAR(255) NOT NULL,
+    dispensado_em TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_view_prontuario_dispensacoes_item ON View_Prontuario_Dispensacoes(id_prescricao, medicamento_id, dispensado_em);
+
+-- Controle de idempotência das projeções
+-- Cada evento aplicado nas views é registrado na mesma transação da atualização;
+-- reentregas (Kafka, outbox, CDC) com o mesmo ID são descartadas
+CREATE TABLE IF NOT EXISTS Processed_Events (
+    event_id VARCHAR(255) PRIMARY KEY,         -- Event.ID ou chave CDC (tabela + LSN + id da linha)
+    event_type VARCHAR(255) NOT NULL,
+    processed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Versão (updated_at) de cada cadastro de Medicos, Pacientes e Medicamentos já refletida
+-- nas views; uma alteração antiga reentregue fora de ordem não sobrescreve a mais recente
+CREATE TABLE IF NOT EXISTS Cadastros_Projetados (
+    tipo VARCHAR(50) NOT NULL,                 -- medico | paciente | medicamento
+    id INT NOT NULL,
+    atualizado_em TIMESTAMP NOT NULL,
+    PRIMARY KEY (tipo, id)
+);
+
+-- Versão de cada prescrição já refletida nas views
+-- O command service devolve "prescricao:<id>:v<versao>" e o query service espera
+-- até que a versão registrada aqui alcance a do token (read-your-writes)
+CREATE TABLE IF NOT EXISTS View_Versao_Prescricao (
+    id_prescricao INT PRIMARY KEY,
+    versao INT NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- =========================================
+-- DADOS DE EXEMPLO (SEED)
+-- =========================================
+
+-- Inserir médicos de exemplo
+INSERT INTO Medicos (nome, especialidade, crm) VALUES
+('Dr. João Silva', 'Cardiologia', 'CRM-SP-123456'),
+('Dra. Maria Santos', 'Clínica Geral', 'CRM-RJ-789012'),
+('Dr. Pedro Oliveira', 'Pediatria', 'CRM-MG-345678'),
+('Dra. Ana Costa', 'Dermatologia', 'CRM-SP-901234'),
+('Dr. Carlos Mendes', 'Ortopedia', 'CRM-RS-567890')
+ON CONFLICT (crm) DO NOTHING;
+
+-- Inserir pacientes de exemplo
+INSERT INTO Pacientes (nome, data_nascimento, endereco) VALUES
+('José da Silva', '1980-05-15', 'Rua das Flores, 123 - São Paulo, SP'),
+('Maria Oliveira', '1992-08-22', 'Av. Paulista, 1000 - São Paulo, SP'),
+('João Santos', '1975-03-10', 'Rua XV de Novembro, 456 - Rio de Janeiro, RJ'),
+('Ana Paula', '2010-12-05', 'Rua das Acácias, 789 - Belo Horizonte, MG'),
+('Carlos Eduardo', '1988-07-18', 'Av. Ipiranga, 321 - Porto Alegre, RS'),
+('Fernanda Lima', '1995-11-30', 'Rua Aurora, 555 - São Paulo, SP'),
+('Roberto Costa', '1968-09-25', 'Rua da Consolação, 888 - São Paulo, SP');
+
+-- Inserir medicamentos de exemplo
+INSERT INTO Medicamentos (nome, descricao) VALUES
+('Paracetamol 500mg', 'Analgésico e antipirético'),
+('Amoxicilina 500mg', 'Antibiótico de amplo espectro'),
+('Omeprazol 20mg', 'Inibidor de bomba de prótons para tratamento de úlcera'),
+('Dipirona 500mg', 'Analgésico e antitérmico'),
+('Ibuprofeno 600mg', 'Anti-inflamatório não esteroidal'),
+('Losartana 50mg', 'Anti-hipertensivo'),
+('Metformina 850mg', 'Antidiabético oral'),
+('Sinvastatina 20mg', 'Redutor de colesterol'),
+('Enalapril 10mg', 'Anti-hipertensivo inibidor da ECA'),
+('Captopril 25mg', 'Anti-hipertensivo');
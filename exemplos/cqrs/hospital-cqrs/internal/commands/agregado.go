@@ -0,0 +1,271 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"hospital-cqrs/internal/domain"
+	"hospital-cqrs/internal/events"
+)
+
+// =========================================
+// AGREGADO PRESCRIÇÃO (EVENT SOURCING)
+// =========================================
+// Com EVENT_PROPAGATION=eventstore o estado da prescrição não fica em tabela: é reconstruído
+// aplicando os eventos do stream prescricao-<id> (a partir do último snapshot, se houver).
+// Os métodos de comando validam as regras contra esse estado e só devolvem os eventos;
+// quem grava é o repositório, com concorrência otimista na versão do stream.
+
+// versaoSnapshotPrescricao é o formato do estado serializado nos snapshots
+// Mudou o struct? Incremente: snapshots antigos passam a ser ignorados e o estado volta do replay
+const versaoSnapshotPrescricao = 2
+
+// PrescricaoAgregado é o estado da prescrição derivado dos seus eventos
+type PrescricaoAgregado struct {
+	Prescricao   domain.Prescricao              `json:"prescricao"`
+	Medicamentos []domain.PrescricaoMedicamento `json:"medicamentos"`
+	// Dispensacoes guarda o status da última dispensação de cada medicamento (id do medicamento)
+	Dispensacoes map[int]string `json:"dispensacoes,omitempty"`
+}
+
+// streamPrescricao é o id do stream da prescrição no event store
+func streamPrescricao(idPrescricao int) string {
+	return fmt.Sprintf("prescricao-%d", idPrescricao)
+}
+
+// Versao é a versão do stream refletida no estado (0 para um agregado sem eventos)
+func (a *PrescricaoAgregado) Versao() int {
+	return a.Prescricao.Versao
+}
+
+// Aplicar muda o estado conforme um evento já gravado
+// Não valida regras: o que está no stream aconteceu
+func (a *PrescricaoAgregado) Aplicar(evento events.Event) error {
+	switch evento.Type {
+	case events.PrescricaoCriadaEvent:
+		data, err := events.DecodeData[events.PrescricaoCriadaEventData](evento, events.PrescricaoCriadaEvent)
+		if err != nil {
+			return err
+		}
+		a.Prescricao = domain.Prescricao{
+			ID:             data.IDPrescricao,
+			IDMedico:       data.IDMedico,
+			IDPaciente:     data.IDPaciente,
+			DataPrescricao: data.DataPrescricao,
+			Status:         domain.StatusPrescricaoAtiva,
+			CreatedAt:      evento.Timestamp,
+		}
+		a.Medicamentos = make([]domain.PrescricaoMedicamento, len(data.Medicamentos))
+		for i, med := range data.Medicamentos {
+			a.Medicamentos[i] = domain.PrescricaoMedicamento{
+				ID:            med.IDPrescricaoMedicamento,
+				IDPrescricao:  data.IDPrescricao,
+				IDMedicamento: med.IDMedicamento,
+				Horario:       med.Horario,
+				Dosagem:       med.Dosagem,
+				CreatedAt:     evento.Timestamp,
+				UpdatedAt:     evento.Timestamp,
+			}
+		}
+
+	case events.PrescricaoAtualizadaEvent:
+		data, err := events.DecodeData[events.PrescricaoAtualizadaEventData](evento, events.PrescricaoAtualizadaEvent)
+		if err != nil {
+			return err
+		}
+		for _, med := range data.Medicamentos {
+			for i := range a.Medicamentos {
+				if a.Medicamentos[i].IDMedicamento == med.IDMedicamento {
+					a.Medicamentos[i].Horario = med.Horario
+					a.Medicamentos[i].Dosagem = med.Dosagem
+					a.Medicamentos[i].UpdatedAt = data.AtualizadaEm
+				}
+			}
+		}
+
+	case events.PrescricaoCanceladaEvent:
+		data, err := events.DecodeData[events.PrescricaoCanceladaEventData](evento, events.PrescricaoCanceladaEvent)
+		if err != nil {
+			return err
+		}
+		motivo := data.Motivo
+		canceladaEm := data.CanceladaEm
+		a.Prescricao.Status = domain.StatusPrescricaoCancelada
+		a.Prescricao.MotivoCancelamento = &motivo
+		a.Prescricao.CanceladaEm = &canceladaEm
+
+	case events.DispensacaoRealizadaEvent, events.DispensacaoParcialEvent, events.DispensacaoRecusadaEvent:
+		status, _ := events.StatusDispensacao(evento.Type)
+		data, err := events.DecodeData[events.DispensacaoEventData](evento, evento.Type)
+		if err != nil {
+			return err
+		}
+		if a.Dispensacoes == nil {
+			a.Dispensacoes = make(map[int]string)
+		}
+		a.Dispensacoes[data.IDMedicamento] = status
+
+	case events.PrescricaoAlertasIgnoradosEvent:
+		// Só auditoria: ocupa uma versão do stream sem mudar o estado
+		if _, err := events.DecodeData[events.AlertasIgnoradosEventData](evento, events.PrescricaoAlertasIgnoradosEvent); err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("%w: evento %s não pertence ao agregado prescrição", events.ErrEventoInvalido, evento.Type)
+	}
+
+	a.Prescricao.Versao++
+	return nil
+}
+
+// Criar decide a criação da prescrição
+// Os ids da prescrição e de cada medicamento vêm do repositório (sequências do banco)
+func (a *PrescricaoAgregado) Criar(idPrescricao int, idsMedicamentos []int, dto domain.CriarPrescricaoDTO) (events.Event, error) {
+	if a.Versao() > 0 {
+		return events.Event{}, fmt.Errorf("prescrição %d já existe", idPrescricao)
+	}
+
+	medicamentos := make([]events.MedicamentoPrescritoEvent, len(dto.Medicamentos))
+	for i, med := range dto.Medicamentos {
+		medicamentos[i] = events.MedicamentoPrescritoEvent{
+			IDPrescricaoMedicamento: idsMedicamentos[i],
+			IDMedicamento:           med.IDMedicamento,
+			Horario:                 med.Horario,
+			Dosagem:                 med.Dosagem,
+		}
+	}
+
+	return events.NewPrescricaoCriadaEvent(events.PrescricaoCriadaEventData{
+		IDPrescricao:   idPrescricao,
+		IDMedico:       dto.IDMedico,
+		IDPaciente:     dto.IDPaciente,
+		DataPrescricao: time.Now(),
+		Medicamentos:   medicamentos,
+	})
+}
+
+// Atualizar decide a alteração de dosagem/horário: a prescrição precisa estar ativa e
+// todos os medicamentos informados precisam fazer parte dela
+func (a *PrescricaoAgregado) Atualizar(dto domain.AtualizarPrescricaoDTO) (events.Event, error) {
+	if err := a.exigirAtiva(); err != nil {
+		return events.Event{}, err
+	}
+
+	medicamentos := make([]events.MedicamentoPrescritoEvent, 0, len(dto.Medicamentos))
+	for _, med := range dto.Medicamentos {
+		item := a.medicamento(med.IDMedicamento)
+		if item == nil {
+			return events.Event{}, fmt.Errorf("%w: medicamento %d", ErrMedicamentoNaoPrescrito, med.IDMedicamento)
+		}
+		medicamentos = append(medicamentos, events.MedicamentoPrescritoEvent{
+			IDPrescricaoMedicamento: item.ID,
+			IDMedicamento:           med.IDMedicamento,
+			Horario:                 med.Horario,
+			Dosagem:                 med.Dosagem,
+		})
+	}
+
+	return events.NewPrescricaoAtualizadaEvent(events.PrescricaoAtualizadaEventData{
+		IDPrescricao: a.Prescricao.ID,
+		Medicamentos: medicamentos,
+		AtualizadaEm: time.Now(),
+	})
+}
+
+// Cancelar decide o cancelamento de uma prescrição ativa
+func (a *PrescricaoAgregado) Cancelar(motivo string) (events.Event, error) {
+	if err := a.exigirAtiva(); err != nil {
+		return events.Event{}, err
+	}
+
+	return events.NewPrescricaoCanceladaEvent(events.PrescricaoCanceladaEventData{
+		IDPrescricao: a.Prescricao.ID,
+		Motivo:       motivo,
+		CanceladaEm:  time.Now(),
+	})
+}
+
+// Dispensar decide a dispensação de um medicamento: a prescrição precisa estar ativa, o
+// medicamento precisa fazer parte dela e não pode ter sido dispensado por completo
+// O id da dispensação vem do repositório (sequência de Dispensacoes)
+func (a *PrescricaoAgregado) Dispensar(idDispensacao, idMedicamento int, farmaceutico string, dto domain.DispensarMedicamentoDTO) (*domain.Dispensacao, events.Event, error) {
+	if err := a.exigirAtiva(); err != nil {
+		return nil, events.Event{}, err
+	}
+
+	item := a.medicamento(idMedicamento)
+	if item == nil {
+		return nil, events.Event{}, fmt.Errorf("%w: medicamento %d", ErrMedicamentoNaoPrescrito, idMedicamento)
+	}
+	if err := exigirDispensavel(idMedicamento, a.statusDispensacao(idMedicamento)); err != nil {
+		return nil, events.Event{}, err
+	}
+
+	dispensacao := &domain.Dispensacao{
+		ID:                      idDispensacao,
+		IDPrescricao:            a.Prescricao.ID,
+		IDPrescricaoMedicamento: item.ID,
+		IDMedicamento:           idMedicamento,
+		Status:                  dto.Status,
+		Quantidade:              dto.Quantidade,
+		Motivo:                  dto.Motivo,
+		Farmaceutico:            farmaceutico,
+		DispensadoEm:            time.Now(),
+	}
+	evento, err := eventoDispensacao(dispensacao)
+	if err != nil {
+		return nil, events.Event{}, err
+	}
+	return dispensacao, evento, nil
+}
+
+// statusDispensacao retorna o status da última dispensação do medicamento (PENDENTE se não houver)
+func (a *PrescricaoAgregado) statusDispensacao(idMedicamento int) string {
+	if status, ok := a.Dispensacoes[idMedicamento]; ok {
+		return status
+	}
+	return domain.StatusDispensacaoPendente
+}
+
+// exigirAtiva aplica as mesmas regras de travarPrescricaoAtiva no modelo com estado
+func (a *PrescricaoAgregado) exigirAtiva() error {
+	if a.Versao() == 0 {
+		return ErrPrescricaoNaoEncontrada
+	}
+	if a.Prescricao.Status != domain.StatusPrescricaoAtiva {
+		return ErrPrescricaoCancelada
+	}
+	return nil
+}
+
+// medicamento retorna o item da prescrição com o medicamento informado (nil se não houver)
+func (a *PrescricaoAgregado) medicamento(idMedicamento int) *domain.PrescricaoMedicamento {
+	for i := range a.Medicamentos {
+		if a.Medicamentos[i].IDMedicamento == idMedicamento {
+			return &a.Medicamentos[i]
+		}
+	}
+	return nil
+}
+
+// medicamentosAtualizados retorna os itens do estado atual citados no DTO, na ordem do DTO
+func (a *PrescricaoAgregado) medicamentosAtualizados(dto domain.AtualizarPrescricaoDTO) []domain.PrescricaoMedicamento {
+	itens := make([]domain.PrescricaoMedicamento, 0, len(dto.Medicamentos))
+	for _, med := range dto.Medicamentos {
+		if item := a.medicamento(med.IDMedicamento); item != nil {
+			itens = append(itens, *item)
+		}
+	}
+	return itens
+}
+
+// snapshot serializa o estado para a tabela Snapshots
+func (a *PrescricaoAgregado) snapshot() (json.RawMessage, error) {
+	estado, err := json.Marshal(a)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar snapshot da prescrição %d: %w", a.Prescricao.ID, err)
+	}
+	return estado, nil
+}
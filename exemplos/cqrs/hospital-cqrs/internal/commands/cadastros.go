@@ -0,0 +1,231 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+
+	"hospital-cqrs/internal/domain"
+	"hospital-cqrs/internal/events"
+)
+
+// =========================================
+// COMANDOS DE CADASTRO (DADOS DE REFERÊNCIA)
+// =========================================
+// Médicos, pacientes e medicamentos aparecem desnormalizados nas views. Cada alteração
+// gera um evento com o cadastro completo para o event handler corrigir as linhas já
+// projetadas (no CDC, o próprio UPDATE capturado pelo Debezium faz esse papel).
+
+var (
+	// ErrMedicoNaoEncontrado indica que o médico não existe no modelo de escrita
+	ErrMedicoNaoEncontrado = errors.New("médico não encontrado")
+	// ErrPacienteNaoEncontrado indica que o paciente não existe no modelo de escrita
+	ErrPacienteNaoEncontrado = errors.New("paciente não encontrado")
+	// ErrMedicamentoNaoEncontrado indica que o medicamento não existe no modelo de escrita
+	ErrMedicamentoNaoEncontrado = errors.New("medicamento não encontrado")
+	// ErrDataNascimentoInvalida indica data de nascimento fora do formato AAAA-MM-DD
+	ErrDataNascimentoInvalida = errors.New("data de nascimento inválida (esperado AAAA-MM-DD)")
+	// ErrCRMDuplicado indica que o CRM já pertence a outro médico
+	ErrCRMDuplicado = errors.New("CRM já cadastrado para outro médico")
+)
+
+// AtualizarMedico processa o comando de alterar o cadastro de um médico
+func (h *PrescricaoHandler) AtualizarMedico(ctx context.Context, id int, dto domain.AtualizarMedicoDTO) (*domain.Medico, error) {
+	medico, ev, err := h.repo.AtualizarMedico(ctx, id, dto)
+	if err != nil {
+		return nil, err
+	}
+	h.propagador.AposCommit(ctx, ev)
+
+	log.Printf("Médico atualizado com sucesso: ID %d", medico.ID)
+	return medico, nil
+}
+
+// AtualizarPaciente processa o comando de alterar o cadastro de um paciente
+func (h *PrescricaoHandler) AtualizarPaciente(ctx context.Context, id int, dto domain.AtualizarPacienteDTO) (*domain.Paciente, error) {
+	paciente, ev, err := h.repo.AtualizarPaciente(ctx, id, dto)
+	if err != nil {
+		return nil, err
+	}
+	h.propagador.AposCommit(ctx, ev)
+
+	log.Printf("Paciente atualizado com sucesso: ID %d", paciente.ID)
+	return paciente, nil
+}
+
+// AtualizarMedicamento processa o comando de alterar o cadastro de um medicamento
+func (h *PrescricaoHandler) AtualizarMedicamento(ctx context.Context, id int, dto domain.AtualizarMedicamentoDTO) (*domain.Medicamento, error) {
+	medicamento, ev, err := h.repo.AtualizarMedicamento(ctx, id, dto)
+	if err != nil {
+		return nil, err
+	}
+	h.propagador.AposCommit(ctx, ev)
+
+	log.Printf("Medicamento atualizado com sucesso: ID %d", medicamento.ID)
+	return medicamento, nil
+}
+
+// AtualizarMedico grava o novo cadastro do médico e entrega o evento ao propagador (mesma transação)
+func (r *PrescricaoRepository) AtualizarMedico(ctx context.Context, id int, dto domain.AtualizarMedicoDTO) (*domain.Medico, EventoComando, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, EventoComando{}, fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	var medico domain.Medico
+	query := `
+		UPDATE Medicos
+		SET nome = $1, especialidade = $2, crm = $3, updated_at = NOW()
+		WHERE id = $4
+		RETURNING id, nome, especialidade, crm, created_at, updated_at
+	`
+	err = tx.QueryRowContext(ctx, query, dto.Nome, dto.Especialidade, dto.CRM, id).
+		Scan(&medico.ID, &medico.Nome, &medico.Especialidade, &medico.CRM, &medico.CreatedAt, &medico.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, EventoComando{}, ErrMedicoNaoEncontrado
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return nil, EventoComando{}, ErrCRMDuplicado
+	}
+	if err != nil {
+		return nil, EventoComando{}, fmt.Errorf("erro ao atualizar médico: %w", err)
+	}
+
+	event, err := novoEventoMedicoAtualizado(&medico)
+	if err != nil {
+		return nil, EventoComando{}, fmt.Errorf("erro ao criar evento: %w", err)
+	}
+
+	ev := EventoComando{TipoAgregado: "medico", IDAgregado: medico.ID, Evento: event}
+	if err := r.confirmarCadastro(ctx, tx, ev); err != nil {
+		return nil, EventoComando{}, err
+	}
+	return &medico, ev, nil
+}
+
+// AtualizarPaciente grava o novo cadastro do paciente e entrega o evento ao propagador (mesma transação)
+func (r *PrescricaoRepository) AtualizarPaciente(ctx context.Context, id int, dto domain.AtualizarPacienteDTO) (*domain.Paciente, EventoComando, error) {
+	dataNascimento, err := time.Parse("2006-01-02", dto.DataNascimento)
+	if err != nil {
+		return nil, EventoComando{}, ErrDataNascimentoInvalida
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, EventoComando{}, fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	var paciente domain.Paciente
+	query := `
+		UPDATE Pacientes
+		SET nome = $1, data_nascimento = $2, endereco = $3, updated_at = NOW()
+		WHERE id = $4
+		RETURNING id, nome, data_nascimento, COALESCE(endereco, ''), created_at, updated_at
+	`
+	err = tx.QueryRowContext(ctx, query, dto.Nome, dataNascimento, dto.Endereco, id).
+		Scan(&paciente.ID, &paciente.Nome, &paciente.DataNascimento, &paciente.Endereco, &paciente.CreatedAt, &paciente.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, EventoComando{}, ErrPacienteNaoEncontrado
+	}
+	if err != nil {
+		return nil, EventoComando{}, fmt.Errorf("erro ao atualizar paciente: %w", err)
+	}
+
+	event, err := novoEventoPacienteAtualizado(&paciente)
+	if err != nil {
+		return nil, EventoComando{}, fmt.Errorf("erro ao criar evento: %w", err)
+	}
+
+	ev := EventoComando{TipoAgregado: "paciente", IDAgregado: paciente.ID, Evento: event}
+	if err := r.confirmarCadastro(ctx, tx, ev); err != nil {
+		return nil, EventoComando{}, err
+	}
+	return &paciente, ev, nil
+}
+
+// AtualizarMedicamento grava o novo cadastro do medicamento e entrega o evento ao propagador (mesma transação)
+func (r *PrescricaoRepository) AtualizarMedicamento(ctx context.Context, id int, dto domain.AtualizarMedicamentoDTO) (*domain.Medicamento, EventoComando, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, EventoComando{}, fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	var medicamento domain.Medicamento
+	query := `
+		UPDATE Medicamentos
+		SET nome = $1, descricao = $2, updated_at = NOW()
+		WHERE id = $3
+		RETURNING id, nome, COALESCE(descricao, ''), created_at, updated_at
+	`
+	err = tx.QueryRowContext(ctx, query, dto.Nome, dto.Descricao, id).
+		Scan(&medicamento.ID, &medicamento.Nome, &medicamento.Descricao, &medicamento.CreatedAt, &medicamento.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, EventoComando{}, ErrMedicamentoNaoEncontrado
+	}
+	if err != nil {
+		return nil, EventoComando{}, fmt.Errorf("erro ao atualizar medicamento: %w", err)
+	}
+
+	event, err := novoEventoMedicamentoAtualizado(&medicamento)
+	if err != nil {
+		return nil, EventoComando{}, fmt.Errorf("erro ao criar evento: %w", err)
+	}
+
+	ev := EventoComando{TipoAgregado: "medicamento", IDAgregado: medicamento.ID, Evento: event}
+	if err := r.confirmarCadastro(ctx, tx, ev); err != nil {
+		return nil, EventoComando{}, err
+	}
+	return &medicamento, ev, nil
+}
+
+// confirmarCadastro entrega o evento do cadastro ao propagador e confirma a transação
+func (r *PrescricaoRepository) confirmarCadastro(ctx context.Context, tx *sql.Tx, ev EventoComando) error {
+	if err := r.propagador.NaTransacao(ctx, tx, ev); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+	return nil
+}
+
+// novoEventoMedicoAtualizado monta o evento com o cadastro completo do médico
+func novoEventoMedicoAtualizado(m *domain.Medico) (events.Event, error) {
+	return events.NewMedicoAtualizadoEvent(events.MedicoAtualizadoEventData{
+		IDMedico:      m.ID,
+		Nome:          m.Nome,
+		Especialidade: m.Especialidade,
+		CRM:           m.CRM,
+		AtualizadoEm:  m.UpdatedAt,
+	})
+}
+
+// novoEventoPacienteAtualizado monta o evento com o cadastro completo do paciente
+func novoEventoPacienteAtualizado(p *domain.Paciente) (events.Event, error) {
+	return events.NewPacienteAtualizadoEvent(events.PacienteAtualizadoEventData{
+		IDPaciente:     p.ID,
+		Nome:           p.Nome,
+		DataNascimento: p.DataNascimento,
+		Endereco:       p.Endereco,
+		AtualizadoEm:   p.UpdatedAt,
+	})
+}
+
+// novoEventoMedicamentoAtualizado monta o evento com o cadastro completo do medicamento
+func novoEventoMedicamentoAtualizado(m *domain.Medicamento) (events.Event, error) {
+	return events.NewMedicamentoAtualizadoEvent(events.MedicamentoAtualizadoEventData{
+		IDMedicamento: m.ID,
+		Nome:          m.Nome,
+		Descricao:     m.Descricao,
+		AtualizadoEm:  m.UpdatedAt,
+	})
+}
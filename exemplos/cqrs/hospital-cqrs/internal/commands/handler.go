@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"hospital-cqrs/internal/domain"
+	"hospital-cqrs/internal/eventstore"
+	"hospital-cqrs/internal/regras"
+)
+
+// PrescricaoHandler gerencia os comandos de prescrição
+type PrescricaoHandler struct {
+	repo        *PrescricaoRepository  // consultas e cadastros
+	prescricoes RepositorioPrescricoes // comandos de prescrição
+	propagador  EventPropagator
+	regras      *regras.Motor // regras clínicas avaliadas na criação
+}
+
+// NewPrescricaoHandler cria um novo handler de prescrições
+// O propagador define como os eventos dos comandos chegam às projeções (ver NewEventPropagator)
+func NewPrescricaoHandler(db *sql.DB, propagador EventPropagator, motor *regras.Motor) *PrescricaoHandler {
+	repo := NewPrescricaoRepository(db, propagador)
+	return &PrescricaoHandler{
+		repo:        repo,
+		prescricoes: repo,
+		propagador:  propagador,
+		regras:      motor,
+	}
+}
+
+// NewPrescricaoHandlerComEventStore cria o handler com o agregado prescrição event-sourced
+// Os cadastros continuam em tabela (o propagador registra seus eventos no store)
+func NewPrescricaoHandlerComEventStore(db *sql.DB, propagador EventPropagator, store *eventstore.Store, motor *regras.Motor) *PrescricaoHandler {
+	return &PrescricaoHandler{
+		repo:        NewPrescricaoRepository(db, propagador),
+		prescricoes: NewPrescricaoEventStoreRepository(db, store),
+		propagador:  propagador,
+		regras:      motor,
+	}
+}
+
+// ConclusaoCriacao roda na transação que cria a prescrição, com o resultado que o comando vai
+// devolver; um erro desfaz a criação. Grava o que precisa ser confirmado junto com a
+// prescrição (a resposta da Idempotency-Key)
+type ConclusaoCriacao func(ctx context.Context, tx *sql.Tx, prescricao *domain.Prescricao, alertas []domain.AlertaClinico, token string) error
+
+// conclusaoTransacao é a ConclusaoCriacao vista pelos repositórios, antes de alertas e token
+type conclusaoTransacao func(ctx context.Context, tx *sql.Tx, prescricao *domain.Prescricao, evs []EventoComando) error
+
+// ConclusaoDispensacao é a ConclusaoCriacao da dispensação: roda na transação que a registra
+type ConclusaoDispensacao func(ctx context.Context, tx *sql.Tx, dispensacao *domain.Dispensacao, token string) error
+
+// conclusaoDispensacao é a ConclusaoDispensacao vista pelos repositórios, antes do token
+type conclusaoDispensacao func(ctx context.Context, tx *sql.Tx, dispensacao *domain.Dispensacao, ev EventoComando) error
+
+// CriarPrescricao processa o comando de criar prescrição
+// O token retornado é enviado ao query service para read-your-writes (ver EventPropagator.Token)
+// Os alertas das regras clínicas ignorados (modo alertar ou com justificativa) voltam junto
+// concluir (opcional) roda na transação da criação
+func (h *PrescricaoHandler) CriarPrescricao(ctx context.Context, dto domain.CriarPrescricaoDTO, concluir ConclusaoCriacao) (*domain.Prescricao, []domain.AlertaClinico, string, error) {
+	if err := dto.Validar(); err != nil {
+		return nil, nil, "", err
+	}
+
+	// Validar se médico, paciente e medicamentos existem
+	if err := h.validarReferencias(ctx, dto); err != nil {
+		return nil, nil, "", err
+	}
+
+	// Interações, duplicidade e dose máxima contra as prescrições ativas do paciente, avaliadas
+	// na transação da criação com o paciente travado (criações concorrentes se enxergam)
+	var alertas []domain.AlertaClinico
+	avaliar := func(ativos []domain.PrescricaoMedicamento) (*AuditoriaAlertas, error) {
+		auditoria, err := h.avaliarRegrasClinicas(dto, ativos)
+		if auditoria != nil {
+			alertas = auditoria.Alertas
+		}
+		return auditoria, err
+	}
+
+	// O token é o da criação: é ela que o leitor espera ver nas views
+	var naTransacao conclusaoTransacao
+	if concluir != nil {
+		naTransacao = func(ctx context.Context, tx *sql.Tx, prescricao *domain.Prescricao, evs []EventoComando) error {
+			return concluir(ctx, tx, prescricao, alertas, h.propagador.Token(evs[0]))
+		}
+	}
+
+	// Criar prescrição (os eventos são entregues ao propagador na mesma transação)
+	prescricao, _, evs, err := h.prescricoes.CriarPrescricao(ctx, dto, avaliar, naTransacao)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("erro ao criar prescrição: %w", err)
+	}
+	for _, ev := range evs {
+		h.propagador.AposCommit(ctx, ev)
+	}
+
+	log.Printf("Prescrição criada com sucesso: ID %d (propagação: %s)", prescricao.ID, h.propagador.Nome())
+	return prescricao, alertas, h.propagador.Token(evs[0]), nil
+}
+
+// AtualizarPrescricao processa o comando de alterar dosagem/horário dos medicamentos
+func (h *PrescricaoHandler) AtualizarPrescricao(ctx context.Context, idPrescricao int, dto domain.AtualizarPrescricaoDTO) ([]domain.PrescricaoMedicamento, string, error) {
+	if err := dto.Validar(); err != nil {
+		return nil, "", err
+	}
+
+	medicamentos, ev, err := h.prescricoes.AtualizarPrescricao(ctx, idPrescricao, dto)
+	if err != nil {
+		return nil, "", fmt.Errorf("erro ao atualizar prescrição: %w", err)
+	}
+	h.propagador.AposCommit(ctx, ev)
+
+	log.Printf("Prescrição atualizada com sucesso: ID %d (propagação: %s)", idPrescricao, h.propagador.Nome())
+	return medicamentos, h.propagador.Token(ev), nil
+}
+
+// CancelarPrescricao processa o comando de cancelar prescrição
+func (h *PrescricaoHandler) CancelarPrescricao(ctx context.Context, idPrescricao int, dto domain.CancelarPrescricaoDTO) (*domain.Prescricao, string, error) {
+	if err := dto.Validar(); err != nil {
+		return nil, "", err
+	}
+
+	prescricao, ev, err := h.prescricoes.CancelarPrescricao(ctx, idPrescricao, dto.Motivo)
+	if err != nil {
+		return nil, "", fmt.Errorf("erro ao cancelar prescrição: %w", err)
+	}
+	h.propagador.AposCommit(ctx, ev)
+
+	log.Printf("Prescrição cancelada com sucesso: ID %d (propagação: %s)", prescricao.ID, h.propagador.Nome())
+	return prescricao, h.propagador.Token(ev), nil
+}
+
+// DispensarMedicamento processa o comando da farmácia de dispensar (total ou parcialmente)
+// ou recusar um medicamento da prescrição; farmaceutico identifica quem registrou
+// concluir (opcional) roda na transação da dispensação
+func (h *PrescricaoHandler) DispensarMedicamento(ctx context.Context, idPrescricao, idMedicamento int, farmaceutico string, dto domain.DispensarMedicamentoDTO, concluir ConclusaoDispensacao) (*domain.Dispensacao, string, error) {
+	if err := dto.Validar(); err != nil {
+		return nil, "", err
+	}
+
+	var naTransacao conclusaoDispensacao
+	if concluir != nil {
+		naTransacao = func(ctx context.Context, tx *sql.Tx, dispensacao *domain.Dispensacao, ev EventoComando) error {
+			return concluir(ctx, tx, dispensacao, h.propagador.Token(ev))
+		}
+	}
+
+	dispensacao, ev, err := h.prescricoes.DispensarMedicamento(ctx, idPrescricao, idMedicamento, farmaceutico, dto, naTransacao)
+	if err != nil {
+		return nil, "", fmt.Errorf("erro ao dispensar medicamento: %w", err)
+	}
+	h.propagador.AposCommit(ctx, ev)
+
+	log.Printf("Dispensação registrada: prescrição %d, medicamento %d, status %s (propagação: %s)",
+		idPrescricao, idMedicamento, dispensacao.Status, h.propagador.Nome())
+	return dispensacao, h.propagador.Token(ev), nil
+}
+
+// ListMedicos retorna a lista de médicos
+func (h *PrescricaoHandler) ListMedicos(ctx context.Context) ([]domain.Medico, error) {
+	return h.repo.ListMedicos(ctx)
+}
+
+// ListPacientes retorna a lista de pacientes
+func (h *PrescricaoHandler) ListPacientes(ctx context.Context) ([]domain.Paciente, error) {
+	return h.repo.ListPacientes(ctx)
+}
+
+// ListMedicamentos retorna a lista de medicamentos
+func (h *PrescricaoHandler) ListMedicamentos(ctx context.Context) ([]domain.Medicamento, error) {
+	return h.repo.ListMedicamentos(ctx)
+}
@@ -0,0 +1,179 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"hospital-cqrs/internal/domain"
+	"hospital-cqrs/internal/events"
+	"hospital-cqrs/internal/eventstore"
+	"hospital-cqrs/pkg/kafka"
+)
+
+// =========================================
+// PROPAGAÇÃO DOS EVENTOS
+// =========================================
+// Todo comando grava o modelo de escrita e monta o evento de domínio na mesma transação.
+// O EventPropagator decide como o evento chega às projeções (ver events.Propagacao):
+// gravando na outbox antes do commit, publicando no Kafka depois dele, ou deixando para o
+// Debezium capturar as linhas alteradas.
+
+// EventoComando é o evento de domínio gerado por um comando, com o agregado de origem
+type EventoComando struct {
+	TipoAgregado string // prescricao, medico, paciente ou medicamento
+	IDAgregado   int
+	Versao       int // versão da prescrição após o comando (0 nos cadastros)
+	Evento       events.Event
+}
+
+// chave é a key Kafka do evento: eventos do mesmo agregado caem na mesma partição e mantêm a ordem
+func (ev EventoComando) chave() string {
+	return fmt.Sprintf("%s-%d", ev.TipoAgregado, ev.IDAgregado)
+}
+
+// EventPropagator leva os eventos dos comandos até as projeções
+type EventPropagator interface {
+	// Nome identifica a estratégia nos logs
+	Nome() string
+	// NaTransacao roda dentro da transação do comando, antes do commit
+	NaTransacao(ctx context.Context, tx *sql.Tx, ev EventoComando) error
+	// AposCommit roda depois do commit; falhas aqui não desfazem o comando
+	AposCommit(ctx context.Context, ev EventoComando)
+	// Token retorna o token de consistência da escrita (read-your-writes)
+	Token(ev EventoComando) string
+}
+
+// NewEventPropagator cria o propagador da estratégia (events.Propagacao)
+// producer só é usado na publicação direta e pode ser nil nas demais
+func NewEventPropagator(estrategia string, producer *kafka.Producer) (EventPropagator, error) {
+	switch estrategia {
+	case events.PropagacaoDireta:
+		if producer == nil {
+			return nil, fmt.Errorf("a propagação %s precisa de um producer Kafka", estrategia)
+		}
+		return &propagacaoDireta{producer: producer}, nil
+	case events.PropagacaoOutbox:
+		return propagacaoOutbox{}, nil
+	case events.PropagacaoCDC:
+		return propagacaoCDC{}, nil
+	case events.PropagacaoEventStore:
+		return propagacaoEventStore{}, nil
+	default:
+		return nil, fmt.Errorf("estratégia de propagação desconhecida: %q", estrategia)
+	}
+}
+
+// propagacaoDireta publica o evento no Kafka depois do commit
+// Se a publicação falhar o comando já foi confirmado: o evento se perde e as views só se
+// corrigem com check-views/rebuild-views. É a estratégia mais simples e a de menor latência.
+type propagacaoDireta struct {
+	producer *kafka.Producer
+}
+
+// timeoutPublicacaoDireta limita a publicação após o commit, que não depende mais da requisição
+const timeoutPublicacaoDireta = 10 * time.Second
+
+func (p *propagacaoDireta) Nome() string { return events.PropagacaoDireta }
+
+func (p *propagacaoDireta) NaTransacao(context.Context, *sql.Tx, EventoComando) error { return nil }
+
+// O comando já foi confirmado: publicar com contexto desligado da requisição (mantendo os
+// valores, como o correlation id), para que um cliente que desconecta não cancele a publicação
+func (p *propagacaoDireta) AposCommit(ctx context.Context, ev EventoComando) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeoutPublicacaoDireta)
+	defer cancel()
+	if err := p.producer.Publish(ctx, ev.chave(), ev.Evento); err != nil {
+		log.Printf("AVISO: Erro ao publicar evento %s, mas o comando foi gravado (%s %d): %v",
+			ev.Evento.ID, ev.TipoAgregado, ev.IDAgregado, err)
+	}
+}
+
+// O id do evento é o token: o query service espera até ele aparecer em Processed_Events
+func (p *propagacaoDireta) Token(ev EventoComando) string { return ev.Evento.ID }
+
+// propagacaoOutbox grava o evento em Outbox_Events na transação do comando
+// O outbox-relay publica no Kafka depois; nenhum evento confirmado se perde.
+type propagacaoOutbox struct{}
+
+func (propagacaoOutbox) Nome() string { return events.PropagacaoOutbox }
+
+func (propagacaoOutbox) NaTransacao(ctx context.Context, tx *sql.Tx, ev EventoComando) error {
+	return gravarOutbox(ctx, tx, ev.TipoAgregado, ev.IDAgregado, ev.Evento)
+}
+
+func (propagacaoOutbox) AposCommit(context.Context, EventoComando) {}
+
+func (propagacaoOutbox) Token(ev EventoComando) string { return ev.Evento.ID }
+
+// gravarOutbox insere o evento na tabela Outbox_Events dentro da transação do comando
+// Id, versão de schema e correlation id vão em colunas próprias para o relay montar os headers Kafka
+func gravarOutbox(ctx context.Context, tx *sql.Tx, aggregateType string, aggregateID int, event events.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("erro ao criar payload do evento: %w", err)
+	}
+
+	queryOutbox := `
+		INSERT INTO Outbox_Events (aggregate_type, aggregate_id, event_type, payload, event_id, schema_version, correlation_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	idAgregado := fmt.Sprintf("%d", aggregateID)
+	var correlationID sql.NullString
+	if id := events.CorrelationIDFromContext(ctx); id != "" {
+		correlationID = sql.NullString{String: id, Valid: true}
+	}
+
+	_, err = tx.ExecContext(ctx, queryOutbox, aggregateType, idAgregado, string(event.Type), payload,
+		event.ID, event.SchemaVersion, correlationID)
+	if err != nil {
+		return fmt.Errorf("erro ao inserir evento na outbox: %w", err)
+	}
+
+	// NOTIFY é transacional: o relay só é acordado depois do commit, quando o evento já está visível
+	if _, err := tx.ExecContext(ctx, "SELECT pg_notify($1, $2)", events.OutboxNotifyChannel, idAgregado); err != nil {
+		return fmt.Errorf("erro ao notificar relay da outbox: %w", err)
+	}
+	return nil
+}
+
+// propagacaoCDC não faz nada além do commit: o Debezium captura as linhas alteradas no WAL
+// O evento de domínio montado pelo comando não sai do processo; as projeções são derivadas
+// das mudanças de linha (ver events.CDCEventHandler).
+type propagacaoCDC struct{}
+
+func (propagacaoCDC) Nome() string { return events.PropagacaoCDC }
+
+func (propagacaoCDC) NaTransacao(context.Context, *sql.Tx, EventoComando) error { return nil }
+
+func (propagacaoCDC) AposCommit(context.Context, EventoComando) {}
+
+// Sem id de evento, o token é a versão da prescrição, registrada em View_Versao_Prescricao
+// Cadastros não têm versão nem token
+func (propagacaoCDC) Token(ev EventoComando) string {
+	if ev.Versao == 0 {
+		return ""
+	}
+	return domain.TokenConsistencia(ev.IDAgregado, ev.Versao)
+}
+
+// propagacaoEventStore anexa o evento ao stream do agregado em Event_Store
+// Só os cadastros passam por aqui: a prescrição é event-sourced e o
+// PrescricaoEventStoreRepository grava seus eventos com controle de versão.
+// As projeções assinam o store (ver eventstore.Assinatura), sem Kafka.
+type propagacaoEventStore struct{}
+
+func (propagacaoEventStore) Nome() string { return events.PropagacaoEventStore }
+
+func (propagacaoEventStore) NaTransacao(ctx context.Context, tx *sql.Tx, ev EventoComando) error {
+	_, err := eventstore.Anexar(ctx, tx, ev.chave(), eventstore.QualquerVersao, ev.Evento)
+	return err
+}
+
+func (propagacaoEventStore) AposCommit(context.Context, EventoComando) {}
+
+// O handler da assinatura registra o id em Processed_Events, como no consumo do Kafka
+func (propagacaoEventStore) Token(ev EventoComando) string { return ev.Evento.ID }
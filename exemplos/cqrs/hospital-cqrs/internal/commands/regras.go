@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"hospital-cqrs/internal/domain"
+	"hospital-cqrs/internal/events"
+)
+
+// AuditoriaAlertas acompanha uma prescrição criada apesar dos alertas das regras clínicas
+// O repositório grava o evento prescricao.alertas_ignorados na mesma transação da criação
+type AuditoriaAlertas struct {
+	Modo          string
+	Justificativa string
+	Alertas       []domain.AlertaClinico
+}
+
+// evento monta o evento de auditoria da prescrição já criada
+func (a *AuditoriaAlertas) evento(prescricao *domain.Prescricao) (events.Event, error) {
+	return events.NewAlertasIgnoradosEvent(events.AlertasIgnoradosEventData{
+		IDPrescricao:  prescricao.ID,
+		IDMedico:      prescricao.IDMedico,
+		IDPaciente:    prescricao.IDPaciente,
+		Modo:          a.Modo,
+		Justificativa: a.Justificativa,
+		Alertas:       a.Alertas,
+		RegistradoEm:  time.Now(),
+	})
+}
+
+// avaliacaoRegras decide a criação a partir dos itens das prescrições ativas do paciente
+// Os repositórios a chamam na transação da criação, depois de travarPaciente
+type avaliacaoRegras func(ativos []domain.PrescricaoMedicamento) (*AuditoriaAlertas, error)
+
+// lockRegrasPaciente é o primeiro inteiro do advisory lock por paciente (o segundo é o id);
+// o espaço de chaves de dois inteiros não se sobrepõe ao de uma chave só (eventstore)
+const lockRegrasPaciente = 7_240_002
+
+// travarPaciente serializa as criações de prescrição do mesmo paciente até o fim da transação:
+// a próxima criação só lê as prescrições ativas depois que esta confirmar ou desfizer
+func travarPaciente(ctx context.Context, tx *sql.Tx, idPaciente int) error {
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1, $2)", lockRegrasPaciente, idPaciente); err != nil {
+		return fmt.Errorf("erro ao obter lock do paciente %d: %w", idPaciente, err)
+	}
+	return nil
+}
+
+// avaliarRegrasClinicas confere a nova prescrição contra os itens das prescrições ativas
+// Sem alertas retorna nil. No modo bloquear, alertas sem justificativa recusam o comando com
+// *domain.ErroRegrasClinicas; nos demais casos a criação segue com a auditoria dos alertas.
+func (h *PrescricaoHandler) avaliarRegrasClinicas(dto domain.CriarPrescricaoDTO, ativos []domain.PrescricaoMedicamento) (*AuditoriaAlertas, error) {
+	alertas := h.regras.Avaliar(dto.Medicamentos, ativos)
+	if len(alertas) == 0 {
+		return nil, nil
+	}
+	if h.regras.Bloqueia() && strings.TrimSpace(dto.JustificativaAlertas) == "" {
+		return nil, &domain.ErroRegrasClinicas{Alertas: alertas}
+	}
+
+	log.Printf("Prescrição do paciente %d com %d alerta(s) clínico(s) ignorado(s) pelo médico %d (modo %s)",
+		dto.IDPaciente, len(alertas), dto.IDMedico, h.regras.Modo())
+	return &AuditoriaAlertas{Modo: h.regras.Modo(), Justificativa: dto.JustificativaAlertas, Alertas: alertas}, nil
+}
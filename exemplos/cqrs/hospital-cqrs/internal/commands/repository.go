@@ -0,0 +1,581 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"hospital-cqrs/internal/domain"
+	"hospital-cqrs/internal/events"
+)
+
+var (
+	// ErrPrescricaoNaoEncontrada indica que a prescrição não existe no modelo de escrita
+	ErrPrescricaoNaoEncontrada = errors.New("prescrição não encontrada")
+	// ErrPrescricaoCancelada indica que a prescrição já foi cancelada e não aceita alterações
+	ErrPrescricaoCancelada = errors.New("prescrição cancelada")
+	// ErrMedicamentoNaoPrescrito indica que o medicamento não faz parte da prescrição
+	ErrMedicamentoNaoPrescrito = errors.New("medicamento não faz parte da prescrição")
+	// ErrMedicamentoJaDispensado indica que o item já foi dispensado por completo
+	ErrMedicamentoJaDispensado = errors.New("medicamento já dispensado")
+)
+
+// PrescricaoRepository gerencia a persistência de prescrições (Write Side)
+// Cada comando grava o modelo de escrita e entrega o evento ao propagador antes do commit
+type PrescricaoRepository struct {
+	db         *sql.DB
+	propagador EventPropagator
+}
+
+// NewPrescricaoRepository cria um novo repositório de prescrições
+func NewPrescricaoRepository(db *sql.DB, propagador EventPropagator) *PrescricaoRepository {
+	return &PrescricaoRepository{db: db, propagador: propagador}
+}
+
+// CriarPrescricao cria uma nova prescrição no banco de dados
+// Retorna também os eventos, já entregues ao propagador na transação: a prescrição criada e,
+// com auditoria, os alertas ignorados (gravados também em Auditoria_Regras_Clinicas)
+// avaliar recebe os itens ativos do paciente, lidos com o paciente travado; concluir (se
+// houver) roda por último, antes do commit
+func (r *PrescricaoRepository) CriarPrescricao(ctx context.Context, dto domain.CriarPrescricaoDTO, avaliar avaliacaoRegras, concluir conclusaoTransacao) (*domain.Prescricao, []domain.PrescricaoMedicamento, []EventoComando, error) {
+	// Iniciar transação
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 0. Regras clínicas contra as prescrições ativas, com as criações do paciente serializadas
+	if err := travarPaciente(ctx, tx, dto.IDPaciente); err != nil {
+		return nil, nil, nil, err
+	}
+	ativos, err := medicamentosAtivosDoPaciente(ctx, tx, dto.IDPaciente)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	auditoria, err := avaliar(ativos)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// 1. Inserir prescrição
+	var prescricao domain.Prescricao
+	query := `
+		INSERT INTO Prescricoes (id_medico, id_paciente, data_prescricao)
+		VALUES ($1, $2, $3)
+		RETURNING id, id_medico, id_paciente, data_prescricao, status, versao, created_at
+	`
+	err = tx.QueryRowContext(ctx, query, dto.IDMedico, dto.IDPaciente, time.Now()).
+		Scan(&prescricao.ID, &prescricao.IDMedico, &prescricao.IDPaciente,
+			&prescricao.DataPrescricao, &prescricao.Status, &prescricao.Versao, &prescricao.CreatedAt)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("erro ao inserir prescrição: %w", err)
+	}
+
+	// 2. Inserir medicamentos da prescrição
+	var prescricaoMedicamentos []domain.PrescricaoMedicamento
+	for _, med := range dto.Medicamentos {
+		var pm domain.PrescricaoMedicamento
+		queryMed := `
+			INSERT INTO Prescricao_Medicamentos (id_prescricao, id_medicamento, horario, dosagem)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, id_prescricao, id_medicamento, horario, dosagem, created_at, updated_at
+		`
+		err = tx.QueryRowContext(ctx, queryMed, prescricao.ID, med.IDMedicamento, med.Horario, med.Dosagem).
+			Scan(&pm.ID, &pm.IDPrescricao, &pm.IDMedicamento, &pm.Horario, &pm.Dosagem, &pm.CreatedAt, &pm.UpdatedAt)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("erro ao inserir medicamento da prescrição: %w", err)
+		}
+		prescricaoMedicamentos = append(prescricaoMedicamentos, pm)
+	}
+
+	// 3. Montar o evento e entregá-lo ao propagador (a outbox grava nesta transação)
+	event, err := events.NewPrescricaoCriadaEvent(events.PrescricaoCriadaEventData{
+		IDPrescricao:   prescricao.ID,
+		IDMedico:       prescricao.IDMedico,
+		IDPaciente:     prescricao.IDPaciente,
+		DataPrescricao: prescricao.DataPrescricao,
+		Medicamentos:   medicamentosDoEvento(prescricaoMedicamentos),
+	})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("erro ao criar evento: %w", err)
+	}
+
+	evs := []EventoComando{{TipoAgregado: "prescricao", IDAgregado: prescricao.ID, Versao: prescricao.Versao, Evento: event}}
+
+	// 4. Prescrita apesar dos alertas: registrar a auditoria na mesma transação
+	if auditoria != nil {
+		eventoAuditoria, err := auditoria.evento(&prescricao)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("erro ao criar evento de auditoria: %w", err)
+		}
+		if err := registrarAuditoria(ctx, tx, eventoAuditoria); err != nil {
+			return nil, nil, nil, err
+		}
+		evs = append(evs, EventoComando{TipoAgregado: "prescricao", IDAgregado: prescricao.ID, Versao: prescricao.Versao, Evento: eventoAuditoria})
+	}
+
+	for _, ev := range evs {
+		if err := r.propagador.NaTransacao(ctx, tx, ev); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	if concluir != nil {
+		if err := concluir(ctx, tx, &prescricao, evs); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	// 5. Commit da transação
+	if err := tx.Commit(); err != nil {
+		return nil, nil, nil, fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+
+	return &prescricao, prescricaoMedicamentos, evs, nil
+}
+
+// AtualizarPrescricao altera dosagem e horário dos medicamentos de uma prescrição ativa
+func (r *PrescricaoRepository) AtualizarPrescricao(ctx context.Context, idPrescricao int, dto domain.AtualizarPrescricaoDTO) ([]domain.PrescricaoMedicamento, EventoComando, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, EventoComando{}, fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 1. Bloquear a prescrição para serializar comandos concorrentes
+	if err := travarPrescricaoAtiva(ctx, tx, idPrescricao); err != nil {
+		return nil, EventoComando{}, err
+	}
+
+	// 2. Atualizar medicamentos e avançar a versão
+	medicamentos, err := atualizarMedicamentos(ctx, tx, idPrescricao, dto.Medicamentos)
+	if err != nil {
+		return nil, EventoComando{}, err
+	}
+
+	versao, err := incrementarVersao(ctx, tx, idPrescricao)
+	if err != nil {
+		return nil, EventoComando{}, err
+	}
+
+	// 3. Montar o evento e entregá-lo ao propagador
+	event, err := events.NewPrescricaoAtualizadaEvent(events.PrescricaoAtualizadaEventData{
+		IDPrescricao: idPrescricao,
+		Medicamentos: medicamentosDoEvento(medicamentos),
+		AtualizadaEm: time.Now(),
+	})
+	if err != nil {
+		return nil, EventoComando{}, fmt.Errorf("erro ao criar evento: %w", err)
+	}
+
+	ev := EventoComando{TipoAgregado: "prescricao", IDAgregado: idPrescricao, Versao: versao, Evento: event}
+	if err := r.propagador.NaTransacao(ctx, tx, ev); err != nil {
+		return nil, EventoComando{}, err
+	}
+
+	// 4. Commit da transação
+	if err := tx.Commit(); err != nil {
+		return nil, EventoComando{}, fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+
+	return medicamentos, ev, nil
+}
+
+// CancelarPrescricao marca uma prescrição ativa como cancelada
+func (r *PrescricaoRepository) CancelarPrescricao(ctx context.Context, idPrescricao int, motivo string) (*domain.Prescricao, EventoComando, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, EventoComando{}, fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 1. Bloquear a prescrição e garantir que ainda está ativa
+	if err := travarPrescricaoAtiva(ctx, tx, idPrescricao); err != nil {
+		return nil, EventoComando{}, err
+	}
+
+	// 2. Cancelar no modelo de escrita
+	prescricao, err := cancelarPrescricao(ctx, tx, idPrescricao, motivo)
+	if err != nil {
+		return nil, EventoComando{}, err
+	}
+
+	// 3. Montar o evento e entregá-lo ao propagador
+	event, err := events.NewPrescricaoCanceladaEvent(events.PrescricaoCanceladaEventData{
+		IDPrescricao: prescricao.ID,
+		Motivo:       motivo,
+		CanceladaEm:  *prescricao.CanceladaEm,
+	})
+	if err != nil {
+		return nil, EventoComando{}, fmt.Errorf("erro ao criar evento: %w", err)
+	}
+
+	ev := EventoComando{TipoAgregado: "prescricao", IDAgregado: prescricao.ID, Versao: prescricao.Versao, Evento: event}
+	if err := r.propagador.NaTransacao(ctx, tx, ev); err != nil {
+		return nil, EventoComando{}, err
+	}
+
+	// 4. Commit da transação
+	if err := tx.Commit(); err != nil {
+		return nil, EventoComando{}, fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+
+	return prescricao, ev, nil
+}
+
+// DispensarMedicamento registra a dispensação (total, parcial ou recusa) de um medicamento
+// de uma prescrição ativa
+func (r *PrescricaoRepository) DispensarMedicamento(ctx context.Context, idPrescricao, idMedicamento int, farmaceutico string, dto domain.DispensarMedicamentoDTO, concluir conclusaoDispensacao) (*domain.Dispensacao, EventoComando, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, EventoComando{}, fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 1. Bloquear a prescrição: dispensações concorrentes do mesmo item são serializadas
+	if err := travarPrescricaoAtiva(ctx, tx, idPrescricao); err != nil {
+		return nil, EventoComando{}, err
+	}
+
+	// 2. Conferir o item e o status da última dispensação
+	idItem, statusAtual, err := statusDispensacaoItem(ctx, tx, idPrescricao, idMedicamento)
+	if err != nil {
+		return nil, EventoComando{}, err
+	}
+	if err := exigirDispensavel(idMedicamento, statusAtual); err != nil {
+		return nil, EventoComando{}, err
+	}
+
+	// 3. Gravar a dispensação e avançar a versão
+	dispensacao := &domain.Dispensacao{
+		IDPrescricao:            idPrescricao,
+		IDPrescricaoMedicamento: idItem,
+		IDMedicamento:           idMedicamento,
+		Status:                  dto.Status,
+		Quantidade:              dto.Quantidade,
+		Motivo:                  dto.Motivo,
+		Farmaceutico:            farmaceutico,
+	}
+	query := `
+		INSERT INTO Dispensacoes (id_prescricao, id_prescricao_medicamento, id_medicamento, status, quantidade, motivo, farmaceutico)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7)
+		RETURNING id, dispensado_em
+	`
+	err = tx.QueryRowContext(ctx, query, idPrescricao, idItem, idMedicamento, dto.Status, dto.Quantidade, dto.Motivo, farmaceutico).
+		Scan(&dispensacao.ID, &dispensacao.DispensadoEm)
+	if err != nil {
+		return nil, EventoComando{}, fmt.Errorf("erro ao registrar dispensação: %w", err)
+	}
+
+	versao, err := incrementarVersao(ctx, tx, idPrescricao)
+	if err != nil {
+		return nil, EventoComando{}, err
+	}
+
+	// 4. Montar o evento e entregá-lo ao propagador
+	event, err := eventoDispensacao(dispensacao)
+	if err != nil {
+		return nil, EventoComando{}, fmt.Errorf("erro ao criar evento: %w", err)
+	}
+
+	ev := EventoComando{TipoAgregado: "prescricao", IDAgregado: idPrescricao, Versao: versao, Evento: event}
+	if err := r.propagador.NaTransacao(ctx, tx, ev); err != nil {
+		return nil, EventoComando{}, err
+	}
+
+	if concluir != nil {
+		if err := concluir(ctx, tx, dispensacao, ev); err != nil {
+			return nil, EventoComando{}, err
+		}
+	}
+
+	// 5. Commit da transação
+	if err := tx.Commit(); err != nil {
+		return nil, EventoComando{}, fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+
+	return dispensacao, ev, nil
+}
+
+// medicamentosAtivosDoPaciente retorna os itens das prescrições ativas do paciente
+func medicamentosAtivosDoPaciente(ctx context.Context, tx *sql.Tx, idPaciente int) ([]domain.PrescricaoMedicamento, error) {
+	query := `
+		SELECT pm.id, pm.id_prescricao, pm.id_medicamento, pm.horario, pm.dosagem, pm.created_at, pm.updated_at
+		FROM Prescricao_Medicamentos pm
+		JOIN Prescricoes p ON p.id = pm.id_prescricao
+		WHERE p.id_paciente = $1 AND p.status = $2
+		ORDER BY pm.id_prescricao, pm.id
+	`
+	rows, err := tx.QueryContext(ctx, query, idPaciente, domain.StatusPrescricaoAtiva)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar medicamentos ativos: %w", err)
+	}
+	defer rows.Close()
+
+	var medicamentos []domain.PrescricaoMedicamento
+	for rows.Next() {
+		var pm domain.PrescricaoMedicamento
+		if err := rows.Scan(&pm.ID, &pm.IDPrescricao, &pm.IDMedicamento, &pm.Horario, &pm.Dosagem, &pm.CreatedAt, &pm.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("erro ao scanear medicamento ativo: %w", err)
+		}
+		medicamentos = append(medicamentos, pm)
+	}
+	return medicamentos, rows.Err()
+}
+
+// registrarAuditoria grava os alertas ignorados em Auditoria_Regras_Clinicas
+// Com EVENT_PROPAGATION=cdc o evento de auditoria não sai do processo: a tabela é o registro
+func registrarAuditoria(ctx context.Context, tx *sql.Tx, evento events.Event) error {
+	data, err := events.DecodeData[events.AlertasIgnoradosEventData](evento, events.PrescricaoAlertasIgnoradosEvent)
+	if err != nil {
+		return err
+	}
+	alertas, err := json.Marshal(data.Alertas)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar alertas: %w", err)
+	}
+
+	query := `
+		INSERT INTO Auditoria_Regras_Clinicas (event_id, id_prescricao, id_medico, id_paciente, modo, justificativa, alertas, registrado_em)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8)
+	`
+	_, err = tx.ExecContext(ctx, query, evento.ID, data.IDPrescricao, data.IDMedico, data.IDPaciente, data.Modo, data.Justificativa, alertas, data.RegistradoEm)
+	if err != nil {
+		return fmt.Errorf("erro ao registrar auditoria das regras clínicas: %w", err)
+	}
+	return nil
+}
+
+// medicamentosDoEvento converte os itens gravados no formato dos eventos de prescrição
+func medicamentosDoEvento(medicamentos []domain.PrescricaoMedicamento) []events.MedicamentoPrescritoEvent {
+	medicamentosEvent := make([]events.MedicamentoPrescritoEvent, len(medicamentos))
+	for i, pm := range medicamentos {
+		medicamentosEvent[i] = events.MedicamentoPrescritoEvent{
+			IDPrescricaoMedicamento: pm.ID,
+			IDMedicamento:           pm.IDMedicamento,
+			Horario:                 pm.Horario,
+			Dosagem:                 pm.Dosagem,
+		}
+	}
+	return medicamentosEvent
+}
+
+// travarPrescricaoAtiva obtém lock da prescrição e garante que ela ainda está ativa
+func travarPrescricaoAtiva(ctx context.Context, tx *sql.Tx, idPrescricao int) error {
+	var status string
+	query := `SELECT status FROM Prescricoes WHERE id = $1 FOR UPDATE`
+	err := tx.QueryRowContext(ctx, query, idPrescricao).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrPrescricaoNaoEncontrada
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao buscar prescrição: %w", err)
+	}
+
+	if status != domain.StatusPrescricaoAtiva {
+		return ErrPrescricaoCancelada
+	}
+	return nil
+}
+
+// statusDispensacaoItem retorna o item do medicamento na prescrição e o status da sua última
+// dispensação (PENDENTE se ainda não houve nenhuma)
+func statusDispensacaoItem(ctx context.Context, tx *sql.Tx, idPrescricao, idMedicamento int) (int, string, error) {
+	query := `
+		SELECT pm.id, COALESCE(ultima.status, $3)
+		FROM Prescricao_Medicamentos pm
+		LEFT JOIN LATERAL (
+			SELECT d.status FROM Dispensacoes d
+			WHERE d.id_prescricao_medicamento = pm.id
+			ORDER BY d.dispensado_em DESC, d.id DESC
+			LIMIT 1
+		) ultima ON true
+		WHERE pm.id_prescricao = $1 AND pm.id_medicamento = $2
+	`
+	var idItem int
+	var status string
+	err := tx.QueryRowContext(ctx, query, idPrescricao, idMedicamento, domain.StatusDispensacaoPendente).Scan(&idItem, &status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, "", fmt.Errorf("%w: medicamento %d", ErrMedicamentoNaoPrescrito, idMedicamento)
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("erro ao buscar medicamento da prescrição: %w", err)
+	}
+	return idItem, status, nil
+}
+
+// exigirDispensavel recusa nova dispensação de um item já dispensado por completo
+// Depois de PARCIAL ou RECUSADO o item pode ser dispensado de novo (complemento ou nova tentativa)
+func exigirDispensavel(idMedicamento int, statusAtual string) error {
+	if statusAtual == domain.StatusDispensacaoDispensado {
+		return fmt.Errorf("%w: medicamento %d", ErrMedicamentoJaDispensado, idMedicamento)
+	}
+	return nil
+}
+
+// eventoDispensacao monta o evento correspondente ao status da dispensação
+func eventoDispensacao(d *domain.Dispensacao) (events.Event, error) {
+	return events.NewDispensacaoEvent(d.Status, events.DispensacaoEventData{
+		IDDispensacao:           d.ID,
+		IDPrescricao:            d.IDPrescricao,
+		IDPrescricaoMedicamento: d.IDPrescricaoMedicamento,
+		IDMedicamento:           d.IDMedicamento,
+		Quantidade:              d.Quantidade,
+		Motivo:                  d.Motivo,
+		Farmaceutico:            d.Farmaceutico,
+		DispensadoEm:            d.DispensadoEm,
+	})
+}
+
+// atualizarMedicamentos grava a nova dosagem/horário de cada medicamento informado
+func atualizarMedicamentos(ctx context.Context, tx *sql.Tx, idPrescricao int, medicamentos []domain.MedicamentoPrescrito) ([]domain.PrescricaoMedicamento, error) {
+	query := `
+		UPDATE Prescricao_Medicamentos
+		SET horario = $1, dosagem = $2, updated_at = NOW()
+		WHERE id_prescricao = $3 AND id_medicamento = $4
+		RETURNING id, id_prescricao, id_medicamento, horario, dosagem, created_at, updated_at
+	`
+
+	var atualizados []domain.PrescricaoMedicamento
+	for _, med := range medicamentos {
+		var pm domain.PrescricaoMedicamento
+		err := tx.QueryRowContext(ctx, query, med.Horario, med.Dosagem, idPrescricao, med.IDMedicamento).
+			Scan(&pm.ID, &pm.IDPrescricao, &pm.IDMedicamento, &pm.Horario, &pm.Dosagem, &pm.CreatedAt, &pm.UpdatedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: medicamento %d", ErrMedicamentoNaoPrescrito, med.IDMedicamento)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao atualizar medicamento da prescrição: %w", err)
+		}
+		atualizados = append(atualizados, pm)
+	}
+
+	return atualizados, nil
+}
+
+// incrementarVersao avança a versão da prescrição na mesma transação do comando
+// No CDC o UPDATE em Prescricoes também é capturado pelo Debezium, o que permite ao event
+// handler registrar a versão refletida nas views mesmo quando só os medicamentos mudaram
+func incrementarVersao(ctx context.Context, tx *sql.Tx, idPrescricao int) (int, error) {
+	var versao int
+	query := `UPDATE Prescricoes SET versao = versao + 1 WHERE id = $1 RETURNING versao`
+	if err := tx.QueryRowContext(ctx, query, idPrescricao).Scan(&versao); err != nil {
+		return 0, fmt.Errorf("erro ao incrementar versão da prescrição: %w", err)
+	}
+	return versao, nil
+}
+
+// cancelarPrescricao grava o cancelamento no modelo de escrita
+func cancelarPrescricao(ctx context.Context, tx *sql.Tx, idPrescricao int, motivo string) (*domain.Prescricao, error) {
+	var prescricao domain.Prescricao
+	query := `
+		UPDATE Prescricoes
+		SET status = $1, motivo_cancelamento = $2, cancelada_em = NOW(), versao = versao + 1
+		WHERE id = $3
+		RETURNING id, id_medico, id_paciente, data_prescricao, status, motivo_cancelamento, cancelada_em, versao, created_at
+	`
+	err := tx.QueryRowContext(ctx, query, domain.StatusPrescricaoCancelada, motivo, idPrescricao).
+		Scan(&prescricao.ID, &prescricao.IDMedico, &prescricao.IDPaciente, &prescricao.DataPrescricao,
+			&prescricao.Status, &prescricao.MotivoCancelamento, &prescricao.CanceladaEm, &prescricao.Versao, &prescricao.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao cancelar prescrição: %w", err)
+	}
+	return &prescricao, nil
+}
+
+// GetMedicoByID busca um médico por ID
+func (r *PrescricaoRepository) GetMedicoByID(ctx context.Context, id int) (*domain.Medico, error) {
+	var medico domain.Medico
+	query := `SELECT id, nome, especialidade, crm FROM Medicos WHERE id = $1`
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&medico.ID, &medico.Nome, &medico.Especialidade, &medico.CRM)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar médico: %w", err)
+	}
+	return &medico, nil
+}
+
+// GetPacienteByID busca um paciente por ID
+func (r *PrescricaoRepository) GetPacienteByID(ctx context.Context, id int) (*domain.Paciente, error) {
+	var paciente domain.Paciente
+	query := `SELECT id, nome, data_nascimento, endereco FROM Pacientes WHERE id = $1`
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&paciente.ID, &paciente.Nome, &paciente.DataNascimento, &paciente.Endereco)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar paciente: %w", err)
+	}
+	return &paciente, nil
+}
+
+// GetMedicamentoByID busca um medicamento por ID
+func (r *PrescricaoRepository) GetMedicamentoByID(ctx context.Context, id int) (*domain.Medicamento, error) {
+	var medicamento domain.Medicamento
+	query := `SELECT id, nome, descricao FROM Medicamentos WHERE id = $1`
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&medicamento.ID, &medicamento.Nome, &medicamento.Descricao)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar medicamento: %w", err)
+	}
+	return &medicamento, nil
+}
+
+// ListMedicos lista todos os médicos
+func (r *PrescricaoRepository) ListMedicos(ctx context.Context) ([]domain.Medico, error) {
+	query := `SELECT id, nome, especialidade, crm FROM Medicos ORDER BY nome`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar médicos: %w", err)
+	}
+	defer rows.Close()
+
+	var medicos []domain.Medico
+	for rows.Next() {
+		var m domain.Medico
+		if err := rows.Scan(&m.ID, &m.Nome, &m.Especialidade, &m.CRM); err != nil {
+			return nil, fmt.Errorf("erro ao scanear médico: %w", err)
+		}
+		medicos = append(medicos, m)
+	}
+	return medicos, nil
+}
+
+// ListPacientes lista todos os pacientes
+func (r *PrescricaoRepository) ListPacientes(ctx context.Context) ([]domain.Paciente, error) {
+	query := `SELECT id, nome, data_nascimento, endereco FROM Pacientes ORDER BY nome`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar pacientes: %w", err)
+	}
+	defer rows.Close()
+
+	var pacientes []domain.Paciente
+	for rows.Next() {
+		var p domain.Paciente
+		if err := rows.Scan(&p.ID, &p.Nome, &p.DataNascimento, &p.Endereco); err != nil {
+			return nil, fmt.Errorf("erro ao scanear paciente: %w", err)
+		}
+		pacientes = append(pacientes, p)
+	}
+	return pacientes, nil
+}
+
+// ListMedicamentos lista todos os medicamentos
+func (r *PrescricaoRepository) ListMedicamentos(ctx context.Context) ([]domain.Medicamento, error) {
+	query := `SELECT id, nome, descricao FROM Medicamentos ORDER BY nome`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar medicamentos: %w", err)
+	}
+	defer rows.Close()
+
+	var medicamentos []domain.Medicamento
+	for rows.Next() {
+		var m domain.Medicamento
+		if err := rows.Scan(&m.ID, &m.Nome, &m.Descricao); err != nil {
+			return nil, fmt.Errorf("erro ao scanear medicamento: %w", err)
+		}
+		medicamentos = append(medicamentos, m)
+	}
+	return medicamentos, nil
+}
@@ -0,0 +1,341 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"hospital-cqrs/internal/domain"
+	"hospital-cqrs/internal/events"
+	"hospital-cqrs/internal/eventstore"
+)
+
+// RepositorioPrescricoes grava os comandos de prescrição e devolve o evento gerado
+// PrescricaoRepository guarda o estado em Prescricoes; PrescricaoEventStoreRepository só no event store
+// A criação avalia as regras clínicas (avaliar) na sua transação, com o paciente travado,
+// devolve também o evento de auditoria dos alertas ignorados e roda concluir (se houver)
+type RepositorioPrescricoes interface {
+	CriarPrescricao(ctx context.Context, dto domain.CriarPrescricaoDTO, avaliar avaliacaoRegras, concluir conclusaoTransacao) (*domain.Prescricao, []domain.PrescricaoMedicamento, []EventoComando, error)
+	AtualizarPrescricao(ctx context.Context, idPrescricao int, dto domain.AtualizarPrescricaoDTO) ([]domain.PrescricaoMedicamento, EventoComando, error)
+	CancelarPrescricao(ctx context.Context, idPrescricao int, motivo string) (*domain.Prescricao, EventoComando, error)
+	DispensarMedicamento(ctx context.Context, idPrescricao, idMedicamento int, farmaceutico string, dto domain.DispensarMedicamentoDTO, concluir conclusaoDispensacao) (*domain.Dispensacao, EventoComando, error)
+}
+
+// tentativasConflito é quantas vezes um comando é refeito (recarregando o agregado) após
+// um conflito de concorrência antes de devolver ErrConflitoConcorrencia
+const tentativasConflito = 3
+
+// PrescricaoEventStoreRepository persiste o agregado prescrição como stream de eventos
+// Cada comando carrega o agregado (snapshot + eventos seguintes), decide e anexa o evento
+// esperando a versão carregada; se outro comando anexou antes, o comando é refeito
+type PrescricaoEventStoreRepository struct {
+	db    *sql.DB
+	store *eventstore.Store
+}
+
+// NewPrescricaoEventStoreRepository cria o repositório event-sourced de prescrições
+func NewPrescricaoEventStoreRepository(db *sql.DB, store *eventstore.Store) *PrescricaoEventStoreRepository {
+	return &PrescricaoEventStoreRepository{db: db, store: store}
+}
+
+// CriarPrescricao inicia o stream da prescrição com o evento prescricao.criada
+// Com auditoria, prescricao.alertas_ignorados é anexado logo em seguida, no mesmo append
+// As regras são avaliadas na transação do append, depois de travar o paciente
+func (r *PrescricaoEventStoreRepository) CriarPrescricao(ctx context.Context, dto domain.CriarPrescricaoDTO, avaliar avaliacaoRegras, concluir conclusaoTransacao) (*domain.Prescricao, []domain.PrescricaoMedicamento, []EventoComando, error) {
+	idPrescricao, idsMedicamentos, err := r.reservarIDs(ctx, len(dto.Medicamentos))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := travarPaciente(ctx, tx, dto.IDPaciente); err != nil {
+		return nil, nil, nil, err
+	}
+	ativos, err := r.medicamentosAtivosDoPaciente(ctx, dto.IDPaciente)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	auditoria, err := avaliar(ativos)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	agregado := &PrescricaoAgregado{}
+	evento, err := agregado.Criar(idPrescricao, idsMedicamentos, dto)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("erro ao criar evento: %w", err)
+	}
+	eventos := []events.Event{evento}
+
+	if auditoria != nil {
+		prescricao := domain.Prescricao{ID: idPrescricao, IDMedico: dto.IDMedico, IDPaciente: dto.IDPaciente}
+		eventoAuditoria, err := auditoria.evento(&prescricao)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("erro ao criar evento de auditoria: %w", err)
+		}
+		eventos = append(eventos, eventoAuditoria)
+	}
+
+	evs, err := r.anexarNaTransacao(ctx, tx, idPrescricao, agregado, concluir, eventos...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, nil, fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+	return &agregado.Prescricao, agregado.Medicamentos, evs, nil
+}
+
+// AtualizarPrescricao anexa prescricao.atualizada se a prescrição estiver ativa
+func (r *PrescricaoEventStoreRepository) AtualizarPrescricao(ctx context.Context, idPrescricao int, dto domain.AtualizarPrescricaoDTO) ([]domain.PrescricaoMedicamento, EventoComando, error) {
+	agregado, ev, err := r.executar(ctx, idPrescricao, nil, func(a *PrescricaoAgregado) (events.Event, error) {
+		return a.Atualizar(dto)
+	})
+	if err != nil {
+		return nil, EventoComando{}, err
+	}
+	return agregado.medicamentosAtualizados(dto), ev, nil
+}
+
+// CancelarPrescricao anexa prescricao.cancelada se a prescrição estiver ativa
+func (r *PrescricaoEventStoreRepository) CancelarPrescricao(ctx context.Context, idPrescricao int, motivo string) (*domain.Prescricao, EventoComando, error) {
+	agregado, ev, err := r.executar(ctx, idPrescricao, nil, func(a *PrescricaoAgregado) (events.Event, error) {
+		return a.Cancelar(motivo)
+	})
+	if err != nil {
+		return nil, EventoComando{}, err
+	}
+	return &agregado.Prescricao, ev, nil
+}
+
+// DispensarMedicamento anexa o evento de dispensação se o item ainda puder ser dispensado
+func (r *PrescricaoEventStoreRepository) DispensarMedicamento(ctx context.Context, idPrescricao, idMedicamento int, farmaceutico string, dto domain.DispensarMedicamentoDTO, concluir conclusaoDispensacao) (*domain.Dispensacao, EventoComando, error) {
+	// Mesma sequência de Dispensacoes: o id identifica a dispensação nas views
+	var idDispensacao int
+	query := `SELECT nextval(pg_get_serial_sequence('dispensacoes', 'id'))`
+	if err := r.db.QueryRowContext(ctx, query).Scan(&idDispensacao); err != nil {
+		return nil, EventoComando{}, fmt.Errorf("erro ao reservar id da dispensação: %w", err)
+	}
+
+	var dispensacao *domain.Dispensacao
+	var naTransacao conclusaoTransacao
+	if concluir != nil {
+		naTransacao = func(ctx context.Context, tx *sql.Tx, _ *domain.Prescricao, evs []EventoComando) error {
+			return concluir(ctx, tx, dispensacao, evs[0])
+		}
+	}
+	_, ev, err := r.executar(ctx, idPrescricao, naTransacao, func(a *PrescricaoAgregado) (events.Event, error) {
+		d, evento, err := a.Dispensar(idDispensacao, idMedicamento, farmaceutico, dto)
+		dispensacao = d
+		return evento, err
+	})
+	if err != nil {
+		return nil, EventoComando{}, err
+	}
+	return dispensacao, ev, nil
+}
+
+// medicamentosAtivosDoPaciente retorna os itens das prescrições ativas do paciente
+// Sem tabela de prescrições, os streams do paciente vêm dos eventos prescricao.criada
+// (idx_event_store_paciente) e cada agregado é carregado para saber se segue ativo
+// Lê fora da transação do append: com o paciente travado, as criações anteriores já confirmaram
+func (r *PrescricaoEventStoreRepository) medicamentosAtivosDoPaciente(ctx context.Context, idPaciente int) ([]domain.PrescricaoMedicamento, error) {
+	query := `
+		SELECT (payload->'data'->>'id_prescricao')::int
+		FROM Event_Store
+		WHERE event_type = $1 AND (payload->'data'->>'id_paciente')::int = $2
+		ORDER BY posicao
+	`
+	rows, err := r.db.QueryContext(ctx, query, string(events.PrescricaoCriadaEvent), idPaciente)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar prescrições do paciente: %w", err)
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("erro ao scanear prescrição do paciente: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao buscar prescrições do paciente: %w", err)
+	}
+
+	var medicamentos []domain.PrescricaoMedicamento
+	for _, id := range ids {
+		agregado, err := r.Carregar(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if agregado.Prescricao.Status == domain.StatusPrescricaoAtiva {
+			medicamentos = append(medicamentos, agregado.Medicamentos...)
+		}
+	}
+	return medicamentos, nil
+}
+
+// executar carrega o agregado, decide o comando e anexa o evento
+// Em conflito de concorrência recarrega e decide de novo: as regras são reavaliadas sobre o
+// estado que venceu a corrida (ex.: atualizar uma prescrição cancelada no meio tempo falha)
+// concluir (se houver) roda na transação de cada tentativa
+func (r *PrescricaoEventStoreRepository) executar(ctx context.Context, idPrescricao int, concluir conclusaoTransacao, decidir func(*PrescricaoAgregado) (events.Event, error)) (*PrescricaoAgregado, EventoComando, error) {
+	for tentativa := 1; ; tentativa++ {
+		agregado, err := r.Carregar(ctx, idPrescricao)
+		if err != nil {
+			return nil, EventoComando{}, err
+		}
+
+		evento, err := decidir(agregado)
+		if err != nil {
+			return nil, EventoComando{}, err
+		}
+
+		evs, err := r.anexar(ctx, idPrescricao, agregado, concluir, evento)
+		if errors.Is(err, eventstore.ErrConflitoConcorrencia) && tentativa < tentativasConflito {
+			log.Printf("Conflito de concorrência na prescrição %d (tentativa %d), recarregando: %v", idPrescricao, tentativa, err)
+			continue
+		}
+		if err != nil {
+			return nil, EventoComando{}, err
+		}
+		return agregado, evs[0], nil
+	}
+}
+
+// Carregar reconstrói o agregado a partir do snapshot mais recente e dos eventos seguintes
+func (r *PrescricaoEventStoreRepository) Carregar(ctx context.Context, idPrescricao int) (*PrescricaoAgregado, error) {
+	stream := streamPrescricao(idPrescricao)
+	agregado := &PrescricaoAgregado{}
+
+	snapshot, err := r.store.CarregarSnapshot(ctx, stream)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot != nil && snapshot.SchemaVersion == versaoSnapshotPrescricao {
+		if err := json.Unmarshal(snapshot.Estado, agregado); err != nil {
+			// Snapshot é só cache: ignorar e reconstruir do início
+			log.Printf("AVISO: snapshot de %s ilegível, reconstruindo pelos eventos: %v", stream, err)
+			agregado = &PrescricaoAgregado{}
+		}
+	}
+
+	eventos, err := r.store.Carregar(ctx, stream, agregado.Versao())
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range eventos {
+		if e.Versao != agregado.Versao()+1 {
+			return nil, fmt.Errorf("stream %s inconsistente: versão %d após %d", stream, e.Versao, agregado.Versao())
+		}
+		if err := agregado.Aplicar(e.Evento); err != nil {
+			return nil, fmt.Errorf("erro ao aplicar evento %d do stream %s: %w", e.Versao, stream, err)
+		}
+	}
+	return agregado, nil
+}
+
+// anexar grava os eventos em uma transação própria (ver anexarNaTransacao)
+func (r *PrescricaoEventStoreRepository) anexar(ctx context.Context, idPrescricao int, agregado *PrescricaoAgregado, concluir conclusaoTransacao, eventos ...events.Event) ([]EventoComando, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	evs, err := r.anexarNaTransacao(ctx, tx, idPrescricao, agregado, concluir, eventos...)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+	return evs, nil
+}
+
+// anexarNaTransacao grava os eventos esperando a versão atual do agregado, aplica-os no estado
+// e, se o intervalo foi alcançado, salva um snapshot na mesma transação
+// Alertas ignorados também vão para Auditoria_Regras_Clinicas, como no repositório em tabela;
+// concluir (se houver) roda por último. O commit fica com o chamador
+func (r *PrescricaoEventStoreRepository) anexarNaTransacao(ctx context.Context, tx *sql.Tx, idPrescricao int, agregado *PrescricaoAgregado, concluir conclusaoTransacao, eventos ...events.Event) ([]EventoComando, error) {
+	stream := streamPrescricao(idPrescricao)
+	versaoAnterior := agregado.Versao()
+
+	versao, err := eventstore.Anexar(ctx, tx, stream, versaoAnterior, eventos...)
+	if err != nil {
+		return nil, err
+	}
+
+	evs := make([]EventoComando, len(eventos))
+	for i, evento := range eventos {
+		if err := agregado.Aplicar(evento); err != nil {
+			return nil, err
+		}
+		if evento.Type == events.PrescricaoAlertasIgnoradosEvent {
+			if err := registrarAuditoria(ctx, tx, evento); err != nil {
+				return nil, err
+			}
+		}
+		evs[i] = EventoComando{TipoAgregado: "prescricao", IDAgregado: idPrescricao, Versao: versaoAnterior + i + 1, Evento: evento}
+	}
+
+	if concluir != nil {
+		if err := concluir(ctx, tx, &agregado.Prescricao, evs); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.store.DeveGerarSnapshot(versaoAnterior, versao) {
+		estado, err := agregado.snapshot()
+		if err != nil {
+			return nil, err
+		}
+		snapshot := eventstore.Snapshot{StreamID: stream, Versao: versao, SchemaVersion: versaoSnapshotPrescricao, Estado: estado}
+		if err := eventstore.SalvarSnapshot(ctx, tx, snapshot); err != nil {
+			return nil, err
+		}
+	}
+
+	return evs, nil
+}
+
+// reservarIDs obtém o id da prescrição e os ids dos itens
+// Usa as mesmas sequências de Prescricoes e Prescricao_Medicamentos: os ids não colidem com
+// prescrições gravadas por outra estratégia no mesmo banco (e nas mesmas views)
+func (r *PrescricaoEventStoreRepository) reservarIDs(ctx context.Context, quantidadeMedicamentos int) (int, []int, error) {
+	var idPrescricao int
+	query := `SELECT nextval(pg_get_serial_sequence('prescricoes', 'id'))`
+	if err := r.db.QueryRowContext(ctx, query).Scan(&idPrescricao); err != nil {
+		return 0, nil, fmt.Errorf("erro ao reservar id da prescrição: %w", err)
+	}
+
+	queryItens := `SELECT nextval(pg_get_serial_sequence('prescricao_medicamentos', 'id')) FROM generate_series(1, $1)`
+	rows, err := r.db.QueryContext(ctx, queryItens, quantidadeMedicamentos)
+	if err != nil {
+		return 0, nil, fmt.Errorf("erro ao reservar ids dos medicamentos: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]int, 0, quantidadeMedicamentos)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return 0, nil, fmt.Errorf("erro ao reservar ids dos medicamentos: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, fmt.Errorf("erro ao reservar ids dos medicamentos: %w", err)
+	}
+	return idPrescricao, ids, nil
+}
@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"hospital-cqrs/internal/domain"
+)
+
+// validarReferencias confere se médico, paciente e medicamentos do comando existem
+// Referências inexistentes voltam juntas como *domain.ErroValidacao (ErrReferenciaInexistente);
+// falhas de banco voltam como erro comum
+func (h *PrescricaoHandler) validarReferencias(ctx context.Context, dto domain.CriarPrescricaoDTO) error {
+	v := domain.NovaValidacao(domain.ErrReferenciaInexistente)
+
+	if _, err := h.repo.GetMedicoByID(ctx, dto.IDMedico); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		v.Adicionar("id_medico", "médico %d não encontrado", dto.IDMedico)
+	}
+
+	if _, err := h.repo.GetPacienteByID(ctx, dto.IDPaciente); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		v.Adicionar("id_paciente", "paciente %d não encontrado", dto.IDPaciente)
+	}
+
+	for i, med := range dto.Medicamentos {
+		if _, err := h.repo.GetMedicamentoByID(ctx, med.IDMedicamento); err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+			v.Adicionar(fmt.Sprintf("medicamentos[%d].id_medicamento", i), "medicamento %d não encontrado", med.IDMedicamento)
+		}
+	}
+
+	return v.Erro()
+}
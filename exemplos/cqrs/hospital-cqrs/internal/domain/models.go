@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // =========================================
 // COMMAND MODELS (Write Side)
@@ -35,13 +38,45 @@ type Medicamento struct {
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// Status possíveis de uma prescrição
+const (
+	StatusPrescricaoAtiva     = "ATIVA"
+	StatusPrescricaoCancelada = "CANCELADA"
+)
+
 // Prescricao representa uma prescrição médica
 type Prescricao struct {
-	ID             int       `json:"id" db:"id"`
-	IDMedico       int       `json:"id_medico" db:"id_medico"`
-	IDPaciente     int       `json:"id_paciente" db:"id_paciente"`
-	DataPrescricao time.Time `json:"data_prescricao" db:"data_prescricao"`
-	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	ID                 int        `json:"id" db:"id"`
+	IDMedico           int        `json:"id_medico" db:"id_medico"`
+	IDPaciente         int        `json:"id_paciente" db:"id_paciente"`
+	DataPrescricao     time.Time  `json:"data_prescricao" db:"data_prescricao"`
+	Status             string     `json:"status" db:"status"`
+	MotivoCancelamento *string    `json:"motivo_cancelamento,omitempty" db:"motivo_cancelamento"`
+	CanceladaEm        *time.Time `json:"cancelada_em,omitempty" db:"cancelada_em"`
+	Versao             int        `json:"versao" db:"versao"`
+	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Status da dispensação de um medicamento prescrito
+// PENDENTE é o estado inicial (sem dispensação registrada); só DISPENSADO é definitivo
+const (
+	StatusDispensacaoPendente   = "PENDENTE"
+	StatusDispensacaoDispensado = "DISPENSADO"
+	StatusDispensacaoParcial    = "PARCIAL"
+	StatusDispensacaoRecusado   = "RECUSADO"
+)
+
+// Dispensacao registra a entrega (total ou parcial) ou a recusa de um medicamento pela farmácia
+type Dispensacao struct {
+	ID                      int       `json:"id" db:"id"`
+	IDPrescricao            int       `json:"id_prescricao" db:"id_prescricao"`
+	IDPrescricaoMedicamento int       `json:"id_prescricao_medicamento" db:"id_prescricao_medicamento"`
+	IDMedicamento           int       `json:"id_medicamento" db:"id_medicamento"`
+	Status                  string    `json:"status" db:"status"`
+	Quantidade              string    `json:"quantidade,omitempty" db:"quantidade"`
+	Motivo                  string    `json:"motivo,omitempty" db:"motivo"`
+	Farmaceutico            string    `json:"farmaceutico" db:"farmaceutico"`
+	DispensadoEm            time.Time `json:"dispensado_em" db:"dispensado_em"`
 }
 
 // PrescricaoMedicamento representa a relação entre prescrição e medicamento
@@ -52,6 +87,7 @@ type PrescricaoMedicamento struct {
 	Horario       string    `json:"horario" db:"horario"`
 	Dosagem       string    `json:"dosagem" db:"dosagem"`
 	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
 }
 
 // =========================================
@@ -70,6 +106,64 @@ type CriarPrescricaoDTO struct {
 	IDMedico     int                    `json:"id_medico" validate:"required"`
 	IDPaciente   int                    `json:"id_paciente" validate:"required"`
 	Medicamentos []MedicamentoPrescrito `json:"medicamentos" validate:"required,min=1"`
+	// JustificativaAlertas permite prescrever apesar dos alertas das regras clínicas
+	// (obrigatória com REGRAS_CLINICAS_MODO=bloquear); fica registrada no evento de auditoria
+	JustificativaAlertas string `json:"justificativa_alertas,omitempty"`
+}
+
+// Tipos de alerta das regras clínicas
+const (
+	AlertaInteracao   = "INTERACAO"
+	AlertaDuplicidade = "DUPLICIDADE"
+	AlertaDoseMaxima  = "DOSE_MAXIMA"
+)
+
+// AlertaClinico é uma regra clínica violada pela nova prescrição
+// IDPrescricaoAtiva é a prescrição ativa do paciente envolvida (0 se o conflito é só entre
+// os medicamentos da nova prescrição)
+type AlertaClinico struct {
+	Tipo              string `json:"tipo"`
+	Medicamentos      []int  `json:"medicamentos"`
+	IDPrescricaoAtiva int    `json:"id_prescricao_ativa,omitempty"`
+	Mensagem          string `json:"mensagem"`
+}
+
+// AtualizarPrescricaoDTO é o DTO para alterar dosagem e horário dos medicamentos de uma prescrição
+type AtualizarPrescricaoDTO struct {
+	Medicamentos []MedicamentoPrescrito `json:"medicamentos" validate:"required,min=1"`
+}
+
+// CancelarPrescricaoDTO é o DTO para cancelar uma prescrição
+type CancelarPrescricaoDTO struct {
+	Motivo string `json:"motivo" validate:"required"`
+}
+
+// DispensarMedicamentoDTO é o DTO para a farmácia registrar a dispensação de um medicamento
+// Status: DISPENSADO, PARCIAL (exige quantidade entregue) ou RECUSADO (exige motivo)
+type DispensarMedicamentoDTO struct {
+	Status     string `json:"status" validate:"required"`
+	Quantidade string `json:"quantidade"`
+	Motivo     string `json:"motivo"`
+}
+
+// AtualizarMedicoDTO é o DTO para alterar o cadastro de um médico
+type AtualizarMedicoDTO struct {
+	Nome          string `json:"nome" validate:"required"`
+	Especialidade string `json:"especialidade" validate:"required"`
+	CRM           string `json:"crm" validate:"required"`
+}
+
+// AtualizarPacienteDTO é o DTO para alterar o cadastro de um paciente
+type AtualizarPacienteDTO struct {
+	Nome           string `json:"nome" validate:"required"`
+	DataNascimento string `json:"data_nascimento" validate:"required"` // AAAA-MM-DD
+	Endereco       string `json:"endereco"`
+}
+
+// AtualizarMedicamentoDTO é o DTO para alterar o cadastro de um medicamento
+type AtualizarMedicamentoDTO struct {
+	Nome      string `json:"nome" validate:"required"`
+	Descricao string `json:"descricao"`
 }
 
 // =========================================
@@ -89,6 +183,7 @@ type ViewFarmacia struct {
 	MedicamentoDescricao   string    `json:"medicamento_descricao" db:"medicamento_descricao"`
 	Horario                string    `json:"horario" db:"horario"`
 	Dosagem                string    `json:"dosagem" db:"dosagem"`
+	StatusDispensacao      string    `json:"status_dispensacao" db:"status_dispensacao"`
 	CreatedAt              time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt              time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -106,6 +201,7 @@ type ViewProntuarioPaciente struct {
 	MedicoNome             string    `json:"medico_nome" db:"medico_nome"`
 	MedicoEspecialidade    string    `json:"medico_especialidade" db:"medico_especialidade"`
 	MedicoCRM              string    `json:"medico_crm" db:"medico_crm"`
+	Status                 string    `json:"status" db:"status"`
 	MedicamentoID          int       `json:"medicamento_id" db:"medicamento_id"`
 	MedicamentoNome        string    `json:"medicamento_nome" db:"medicamento_nome"`
 	MedicamentoDescricao   string    `json:"medicamento_descricao" db:"medicamento_descricao"`
@@ -115,6 +211,16 @@ type ViewProntuarioPaciente struct {
 	UpdatedAt              time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// ConsistencyTokenHeader é o header em que o command service devolve o token de consistência
+// e em que o query service o recebe para esperar a projeção alcançar a escrita (read-your-writes)
+const ConsistencyTokenHeader = "X-Consistency-Token"
+
+// TokenConsistencia monta o token de uma escrita propagada por CDC: sem evento de domínio
+// com id próprio, o token identifica a versão da prescrição gravada pelo comando
+func TokenConsistencia(idPrescricao, versao int) string {
+	return fmt.Sprintf("prescricao:%d:v%d", idPrescricao, versao)
+}
+
 // =========================================
 // DTOs PARA QUERIES
 // =========================================
@@ -136,6 +242,23 @@ type MedicamentoFarmaciaDTO struct {
 	MedicamentoDescricao string `json:"medicamento_descricao"`
 	Horario              string `json:"horario"`
 	Dosagem              string `json:"dosagem"`
+	StatusDispensacao    string `json:"status_dispensacao"`
+}
+
+// PaginacaoDTO descreve a página retornada por uma listagem
+// ProximoCursor vazio indica a última página
+type PaginacaoDTO struct {
+	Total         int    `json:"total"`
+	Limite        int    `json:"limite"`
+	Ordenacao     string `json:"ordenacao"`
+	Direcao       string `json:"direcao"`
+	ProximoCursor string `json:"proximo_cursor,omitempty"`
+}
+
+// PaginaPrescricoesFarmaciaDTO é uma página da listagem da farmácia
+type PaginaPrescricoesFarmaciaDTO struct {
+	Prescricoes []PrescricaoFarmaciaDTO `json:"prescricoes"`
+	Paginacao   PaginacaoDTO            `json:"paginacao"`
 }
 
 // ProntuarioPacienteDTO agrupa prescrições do prontuário
@@ -145,6 +268,7 @@ type ProntuarioPacienteDTO struct {
 	PacienteDataNascimento time.Time                 `json:"paciente_data_nascimento"`
 	PacienteEndereco       string                    `json:"paciente_endereco"`
 	Prescricoes            []PrescricaoProntuarioDTO `json:"prescricoes"`
+	Paginacao              *PaginacaoDTO             `json:"paginacao,omitempty"`
 }
 
 // PrescricaoProntuarioDTO representa uma prescrição no prontuário
@@ -155,14 +279,36 @@ type PrescricaoProntuarioDTO struct {
 	MedicoNome          string                     `json:"medico_nome"`
 	MedicoEspecialidade string                     `json:"medico_especialidade"`
 	MedicoCRM           string                     `json:"medico_crm"`
+	Status              string                     `json:"status"`
 	Medicamentos        []MedicamentoProntuarioDTO `json:"medicamentos"`
 }
 
 // MedicamentoProntuarioDTO representa medicamento no prontuário
 type MedicamentoProntuarioDTO struct {
-	MedicamentoID        int    `json:"medicamento_id"`
-	MedicamentoNome      string `json:"medicamento_nome"`
-	MedicamentoDescricao string `json:"medicamento_descricao"`
-	Horario              string `json:"horario"`
-	Dosagem              string `json:"dosagem"`
+	MedicamentoID        int              `json:"medicamento_id"`
+	MedicamentoNome      string           `json:"medicamento_nome"`
+	MedicamentoDescricao string           `json:"medicamento_descricao"`
+	Horario              string           `json:"horario"`
+	Dosagem              string           `json:"dosagem"`
+	StatusDispensacao    string           `json:"status_dispensacao"`
+	Dispensacoes         []DispensacaoDTO `json:"dispensacoes,omitempty"`
+}
+
+// DispensacaoDTO é uma dispensação no histórico do medicamento no prontuário
+type DispensacaoDTO struct {
+	IDDispensacao int       `json:"id_dispensacao"`
+	Status        string    `json:"status"`
+	Quantidade    string    `json:"quantidade,omitempty"`
+	Motivo        string    `json:"motivo,omitempty"`
+	Farmaceutico  string    `json:"farmaceutico"`
+	DispensadoEm  time.Time `json:"dispensado_em"`
+}
+
+// StatusDispensacaoAtual é o status da dispensação mais recente (PENDENTE se não houver)
+// dispensacoes deve estar em ordem cronológica
+func StatusDispensacaoAtual(dispensacoes []DispensacaoDTO) string {
+	if len(dispensacoes) == 0 {
+		return StatusDispensacaoPendente
+	}
+	return dispensacoes[len(dispensacoes)-1].Status
 }
@@ -0,0 +1,193 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// =========================================
+// PROJEÇÃO DE DADOS DE REFERÊNCIA
+// =========================================
+// As views copiam nome do médico, do paciente e do medicamento quando a prescrição é
+// projetada. Quando um cadastro muda, todas as linhas afetadas são atualizadas de uma vez:
+// um UPDATE por view, restrito às linhas que ainda têm o valor antigo.
+
+// Tipos de cadastro controlados em Cadastros_Projetados
+const (
+	cadastroMedico      = "medico"
+	cadastroPaciente    = "paciente"
+	cadastroMedicamento = "medicamento"
+)
+
+// HandleMedicoAtualizado reflete o novo cadastro do médico em todas as linhas das views
+func (h *PrescricaoEventHandler) HandleMedicoAtualizado(ctx context.Context, event Event) error {
+	data, err := DecodeData[MedicoAtualizadoEventData](event, MedicoAtualizadoEvent)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Processando evento: Médico %d atualizado", data.IDMedico)
+
+	var linhas int64
+	err = h.views.Projetar(ctx, event.ID, string(event.Type), func(views EscritaViews) error {
+		linhas, err = views.AtualizarMedico(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Evento processado: %d linha(s) das views com o cadastro do médico %d", linhas, data.IDMedico)
+	return nil
+}
+
+// HandlePacienteAtualizado reflete o novo cadastro do paciente em todas as linhas das views
+func (h *PrescricaoEventHandler) HandlePacienteAtualizado(ctx context.Context, event Event) error {
+	data, err := DecodeData[PacienteAtualizadoEventData](event, PacienteAtualizadoEvent)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Processando evento: Paciente %d atualizado", data.IDPaciente)
+
+	var linhas int64
+	err = h.views.Projetar(ctx, event.ID, string(event.Type), func(views EscritaViews) error {
+		linhas, err = views.AtualizarPaciente(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Evento processado: %d linha(s) das views com o cadastro do paciente %d", linhas, data.IDPaciente)
+	return nil
+}
+
+// HandleMedicamentoAtualizado reflete o novo cadastro do medicamento em todas as linhas das views
+func (h *PrescricaoEventHandler) HandleMedicamentoAtualizado(ctx context.Context, event Event) error {
+	data, err := DecodeData[MedicamentoAtualizadoEventData](event, MedicamentoAtualizadoEvent)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Processando evento: Medicamento %d atualizado", data.IDMedicamento)
+
+	var linhas int64
+	err = h.views.Projetar(ctx, event.ID, string(event.Type), func(views EscritaViews) error {
+		linhas, err = views.AtualizarMedicamento(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Evento processado: %d linha(s) das views com o cadastro do medicamento %d", linhas, data.IDMedicamento)
+	return nil
+}
+
+// projetarCadastroMedico atualiza os dados do médico no prontuário (a farmácia não os exibe)
+func projetarCadastroMedico(ctx context.Context, tx *sql.Tx, data MedicoAtualizadoEventData) (int64, error) {
+	if atual, err := registrarVersaoCadastro(ctx, tx, cadastroMedico, data.IDMedico, data.AtualizadoEm); err != nil || !atual {
+		return 0, err
+	}
+
+	return atualizarEmLote(ctx, tx, `
+		UPDATE View_Prontuario_Paciente
+		SET medico_nome = $2, medico_especialidade = $3, medico_crm = $4, updated_at = NOW()
+		WHERE medico_id = $1
+		  AND (medico_nome, medico_especialidade, medico_crm) IS DISTINCT FROM ($2, $3, $4)
+	`, data.IDMedico, data.Nome, data.Especialidade, data.CRM)
+}
+
+// projetarCadastroPaciente atualiza os dados do paciente nas duas views
+func projetarCadastroPaciente(ctx context.Context, tx *sql.Tx, data PacienteAtualizadoEventData) (int64, error) {
+	if atual, err := registrarVersaoCadastro(ctx, tx, cadastroPaciente, data.IDPaciente, data.AtualizadoEm); err != nil || !atual {
+		return 0, err
+	}
+
+	farmacia, err := atualizarEmLote(ctx, tx, `
+		UPDATE View_Farmacia
+		SET paciente_nome = $2, paciente_data_nascimento = $3, updated_at = NOW()
+		WHERE paciente_id = $1
+		  AND (paciente_nome, paciente_data_nascimento) IS DISTINCT FROM ($2, $3::date)
+	`, data.IDPaciente, data.Nome, data.DataNascimento)
+	if err != nil {
+		return 0, err
+	}
+
+	prontuario, err := atualizarEmLote(ctx, tx, `
+		UPDATE View_Prontuario_Paciente
+		SET paciente_nome = $2, paciente_data_nascimento = $3, paciente_endereco = $4, updated_at = NOW()
+		WHERE paciente_id = $1
+		  AND (paciente_nome, paciente_data_nascimento, paciente_endereco) IS DISTINCT FROM ($2, $3::date, $4)
+	`, data.IDPaciente, data.Nome, data.DataNascimento, data.Endereco)
+	if err != nil {
+		return 0, err
+	}
+
+	return farmacia + prontuario, nil
+}
+
+// projetarCadastroMedicamento atualiza nome e descrição do medicamento nas duas views
+func projetarCadastroMedicamento(ctx context.Context, tx *sql.Tx, data MedicamentoAtualizadoEventData) (int64, error) {
+	if atual, err := registrarVersaoCadastro(ctx, tx, cadastroMedicamento, data.IDMedicamento, data.AtualizadoEm); err != nil || !atual {
+		return 0, err
+	}
+
+	var total int64
+	for _, tabela := range []string{"View_Farmacia", "View_Prontuario_Paciente"} {
+		linhas, err := atualizarEmLote(ctx, tx, fmt.Sprintf(`
+			UPDATE %s
+			SET medicamento_nome = $2, medicamento_descricao = $3, updated_at = NOW()
+			WHERE medicamento_id = $1
+			  AND (medicamento_nome, medicamento_descricao) IS DISTINCT FROM ($2, $3)
+		`, tabela), data.IDMedicamento, data.Nome, data.Descricao)
+		if err != nil {
+			return 0, err
+		}
+		total += linhas
+	}
+	return total, nil
+}
+
+// registrarVersaoCadastro guarda a versão (atualizado_em) do cadastro já refletida nas views
+// e informa se este evento é mais novo. Uma versão antiga reentregue fora de ordem não
+// sobrescreve a mais recente. Eventos sem atualizado_em são sempre aplicados.
+func registrarVersaoCadastro(ctx context.Context, tx *sql.Tx, tipo string, id int, atualizadoEm time.Time) (bool, error) {
+	if atualizadoEm.IsZero() {
+		return true, nil
+	}
+
+	query := `
+		INSERT INTO Cadastros_Projetados (tipo, id, atualizado_em)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tipo, id) DO UPDATE SET atualizado_em = EXCLUDED.atualizado_em
+		WHERE Cadastros_Projetados.atualizado_em < EXCLUDED.atualizado_em
+	`
+	result, err := tx.ExecContext(ctx, query, tipo, id, atualizadoEm)
+	if err != nil {
+		return false, fmt.Errorf("erro ao registrar versão do cadastro %s %d: %w", tipo, id, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("erro ao registrar versão do cadastro %s %d: %w", tipo, id, err)
+	}
+	if rows == 0 {
+		log.Printf("Cadastro %s %d: versão de %s já superada - ignorando", tipo, id, atualizadoEm.Format(time.RFC3339))
+		return false, nil
+	}
+	return true, nil
+}
+
+// atualizarEmLote executa o UPDATE de todas as linhas afetadas e retorna quantas mudaram
+func atualizarEmLote(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (int64, error) {
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao propagar cadastro para as views: %w", err)
+	}
+	return result.RowsAffected()
+}
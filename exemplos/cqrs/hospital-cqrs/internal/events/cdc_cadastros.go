@@ -0,0 +1,113 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// aplicarCadastro projeta uma mudança em Medicos, Pacientes ou Medicamentos
+//
+// Só UPDATE (e o snapshot, que reconcilia as views após recriar o connector) interessa:
+// um cadastro novo ainda não aparece em nenhuma prescrição, e as FKs impedem remover
+// um cadastro já referenciado. A propagação usa as mesmas funções dos eventos de domínio.
+func (h *CDCEventHandler) aplicarCadastro(ctx context.Context, tx *sql.Tx, m MudancaCDC) error {
+	event := m.Evento
+	if event.removido() || event.Op == "c" {
+		return nil
+	}
+	if event.Op != "u" && event.Op != "r" {
+		log.Printf("Ignorando operação %s desconhecida", event.Op)
+		return nil
+	}
+
+	id, err := event.campoInt("id")
+	if err != nil {
+		return err
+	}
+	nome, err := event.campoString("nome")
+	if err != nil {
+		return err
+	}
+	// updated_at ordena as versões do cadastro; sem ele a mudança é aplicada sempre
+	atualizadoEm, _ := event.campoTimestamp("updated_at")
+	if !atualizadoEm.IsZero() {
+		atualizadoEm = atualizadoEm.UTC()
+	}
+
+	var linhas int64
+	switch m.Tabela {
+	case TabelaMedicos:
+		especialidade, err := event.campoString("especialidade")
+		if err != nil {
+			return err
+		}
+		crm, err := event.campoString("crm")
+		if err != nil {
+			return err
+		}
+		linhas, err = projetarCadastroMedico(ctx, tx, MedicoAtualizadoEventData{
+			IDMedico:      id,
+			Nome:          nome,
+			Especialidade: especialidade,
+			CRM:           crm,
+			AtualizadoEm:  atualizadoEm,
+		})
+		if err != nil {
+			return err
+		}
+
+	case TabelaPacientes:
+		dataNascimento, err := event.campoData("data_nascimento")
+		if err != nil {
+			return err
+		}
+		endereco, _ := event.Data["endereco"].(string)
+		linhas, err = projetarCadastroPaciente(ctx, tx, PacienteAtualizadoEventData{
+			IDPaciente:     id,
+			Nome:           nome,
+			DataNascimento: dataNascimento,
+			Endereco:       endereco,
+			AtualizadoEm:   atualizadoEm,
+		})
+		if err != nil {
+			return err
+		}
+
+	case TabelaMedicamentos:
+		descricao, _ := event.Data["descricao"].(string)
+		linhas, err = projetarCadastroMedicamento(ctx, tx, MedicamentoAtualizadoEventData{
+			IDMedicamento: id,
+			Nome:          nome,
+			Descricao:     descricao,
+			AtualizadoEm:  atualizadoEm,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if linhas > 0 {
+		log.Printf("Cadastro CDC %s %d propagado para %d linha(s) das views", m.Tabela, id, linhas)
+	}
+	return nil
+}
+
+// campoData lê um DATE, que vem como dias desde 1970-01-01 (io.debezium.time.Date)
+// ou string AAAA-MM-DD, conforme o time.precision.mode do connector
+func (e DebeziumEvent) campoData(nome string) (time.Time, error) {
+	switch v := e.Data[nome].(type) {
+	case float64:
+		return time.Unix(0, 0).UTC().AddDate(0, 0, int(v)), nil
+	case string:
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: erro ao parsear %s: %v", ErrEventoInvalido, nome, err)
+		}
+		return t, nil
+	default:
+		return time.Time{}, fmt.Errorf("%w: formato desconhecido para %s: %T", ErrEventoInvalido, nome, v)
+	}
+}
@@ -0,0 +1,717 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"hospital-cqrs/internal/domain"
+	"hospital-cqrs/internal/readmodel"
+)
+
+// Tabelas capturadas pelo Debezium (sufixo do tópico hospital_db.public.<tabela>)
+const (
+	TabelaPrescricoes            = "prescricoes"
+	TabelaPrescricaoMedicamentos = "prescricao_medicamentos"
+	TabelaDispensacoes           = "dispensacoes"
+	TabelaMedicos                = "medicos"
+	TabelaPacientes              = "pacientes"
+	TabelaMedicamentos           = "medicamentos"
+)
+
+// DebeziumEvent representa um evento CDC do Debezium já "unwrapped" (ExtractNewRecordState)
+// O payload vem diretamente com os dados, sem envelope before/after
+type DebeziumEvent struct {
+	// Campos do payload da tabela
+	Data map[string]interface{}
+
+	// Metadados do Debezium (injetados com prefixo __)
+	Op         string `json:"__op"`      // c=create, u=update, d=delete, r=read
+	Deleted    string `json:"__deleted"` // "true" ou "false"
+	SourceMs   int64  `json:"__source_ts_ms"`
+	LSN        int64  `json:"__source_lsn"`              // posição da mudança no WAL
+	TxID       string `json:"__transaction_id"`          // transação de origem (vazio no snapshot)
+	TotalOrder int64  `json:"__transaction_total_order"` // posição do evento dentro da transação
+}
+
+// UnmarshalJSON customizado para capturar todos os campos
+func (e *DebeziumEvent) UnmarshalJSON(data []byte) error {
+	// Primeiro, deserializar para um map genérico
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	// Extrair metadados do Debezium
+	if op, ok := raw["__op"].(string); ok {
+		e.Op = op
+	}
+	if deleted, ok := raw["__deleted"].(string); ok {
+		e.Deleted = deleted
+	}
+	if sourceMs, ok := raw["__source_ts_ms"].(float64); ok {
+		e.SourceMs = int64(sourceMs)
+	}
+	if lsn, ok := raw["__source_lsn"].(float64); ok {
+		e.LSN = int64(lsn)
+	}
+	if txID, ok := raw["__transaction_id"].(string); ok {
+		e.TxID = txID
+	}
+	if totalOrder, ok := raw["__transaction_total_order"].(float64); ok {
+		e.TotalOrder = int64(totalOrder)
+	}
+
+	// Todos os campos (incluindo metadados) vão para Data
+	e.Data = raw
+
+	return nil
+}
+
+// decodificarCDC deserializa um evento de dados; ok=false para tombstones (valor nulo),
+// que apenas seguem o DELETE para a compactação do tópico
+func decodificarCDC(eventData []byte) (DebeziumEvent, bool, error) {
+	var event DebeziumEvent
+	if len(eventData) == 0 {
+		return event, false, nil
+	}
+	if err := json.Unmarshal(eventData, &event); err != nil {
+		return event, false, fmt.Errorf("%w: erro ao deserializar evento CDC: %v", ErrEventoInvalido, err)
+	}
+	return event, true, nil
+}
+
+// chaveIdempotencia identifica a mudança capturada para o controle de Processed_Events.
+// O LSN sozinho não basta: no snapshot inicial todas as linhas compartilham o mesmo LSN,
+// por isso a chave combina tabela, LSN e id da linha.
+func (e DebeziumEvent) chaveIdempotencia(tabela string, id int) (string, error) {
+	if e.LSN == 0 {
+		return "", fmt.Errorf("evento CDC sem __source_lsn (verifique transforms.unwrap.add.fields do connector)")
+	}
+	return fmt.Sprintf("cdc:%s:%d:%d", tabela, e.LSN, id), nil
+}
+
+// campoInt lê um campo numérico obrigatório do payload sem entrar em pânico
+func (e DebeziumEvent) campoInt(nome string) (int, error) {
+	v, ok := e.Data[nome].(float64)
+	if !ok {
+		return 0, fmt.Errorf("%w: campo %s ausente ou não numérico no evento CDC", ErrEventoInvalido, nome)
+	}
+	return int(v), nil
+}
+
+// campoString lê um campo texto obrigatório do payload sem entrar em pânico
+func (e DebeziumEvent) campoString(nome string) (string, error) {
+	v, ok := e.Data[nome].(string)
+	if !ok {
+		return "", fmt.Errorf("%w: campo %s ausente ou não textual no evento CDC", ErrEventoInvalido, nome)
+	}
+	return v, nil
+}
+
+// campoTimestamp lê um TIMESTAMP, que pode vir como microssegundos ou string ISO 8601
+func (e DebeziumEvent) campoTimestamp(nome string) (time.Time, error) {
+	switch v := e.Data[nome].(type) {
+	case float64:
+		// Timestamp em microsegundos
+		return time.Unix(0, int64(v)*1000), nil
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			t, err = time.Parse("2006-01-02T15:04:05.999999Z07:00", v)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("%w: erro ao parsear %s: %v", ErrEventoInvalido, nome, err)
+			}
+		}
+		return t, nil
+	default:
+		return time.Time{}, fmt.Errorf("%w: formato desconhecido para %s: %T", ErrEventoInvalido, nome, v)
+	}
+}
+
+// instante retorna quando a mudança aconteceu no modelo de escrita: a coluna informada
+// (cancelada_em, updated_at) ou, sem ela, o horário do commit na origem (__source_ts_ms)
+// Usado como início das vigências no histórico do prontuário
+func (e DebeziumEvent) instante(campo string) time.Time {
+	if t, err := e.campoTimestamp(campo); err == nil {
+		return t
+	}
+	return time.UnixMilli(e.SourceMs)
+}
+
+// removido indica um DELETE: op "d" ou o registro reescrito pelo unwrap
+// (delete.handling.mode=rewrite) com __deleted=true
+func (e DebeziumEvent) removido() bool {
+	return e.Op == "d" || e.Deleted == "true"
+}
+
+// versao lê a versão da prescrição capturada; 0 quando o registro não traz a coluna
+func (e DebeziumEvent) versao() int {
+	v, _ := e.Data["versao"].(float64)
+	return int(v)
+}
+
+// MudancaCDC é um evento de dados junto com a tabela de origem
+type MudancaCDC struct {
+	Tabela string
+	Evento DebeziumEvent
+}
+
+// chave retorna a chave de idempotência da mudança
+func (m MudancaCDC) chave() (string, error) {
+	id, err := m.Evento.campoInt("id")
+	if err != nil {
+		return "", err
+	}
+	return m.Evento.chaveIdempotencia(m.Tabela, id)
+}
+
+// unidadeCDC reúne as mudanças de uma mesma transação de origem já em memória
+// É nil quando o evento é aplicado isoladamente (snapshot ou sem metadados de transação)
+type unidadeCDC struct {
+	prescricoes map[int]DebeziumEvent // linhas de Prescricoes gravadas na transação, por id
+}
+
+// prescricao devolve a linha de Prescricoes gravada na mesma transação, se houver
+func (u *unidadeCDC) prescricao(id int) (DebeziumEvent, bool) {
+	if u == nil {
+		return DebeziumEvent{}, false
+	}
+	event, ok := u.prescricoes[id]
+	return event, ok
+}
+
+// CDCEventHandler processa eventos CDC do Debezium
+type CDCEventHandler struct {
+	db *sql.DB
+}
+
+// NewCDCEventHandler cria um novo handler de eventos CDC
+func NewCDCEventHandler(db *sql.DB) *CDCEventHandler {
+	return &CDCEventHandler{db: db}
+}
+
+// aplicarIsolado aplica uma única mudança em sua própria transação
+// Sem a transação de origem em memória, dados relacionados são buscados no modelo de escrita
+func (h *CDCEventHandler) aplicarIsolado(ctx context.Context, m MudancaCDC) error {
+	log.Printf("Evento CDC recebido: tabela=%s operação=%s", m.Tabela, m.Evento.Op)
+
+	eventID, err := m.chave()
+	if err != nil {
+		return err
+	}
+
+	return processarUmaVez(ctx, h.db, eventID, "cdc."+m.Tabela+"."+m.Evento.Op, func(tx *sql.Tx) error {
+		return h.aplicarMudanca(ctx, tx, m, nil)
+	})
+}
+
+// AplicarTransacao aplica nas views, em uma única transação, todas as mudanças de uma
+// transação de origem (agrupadas pelo AgregadorTransacoes), na ordem em que foram gravadas
+//
+// A prescrição e seus medicamentos vêm dos próprios eventos: não é preciso voltar ao banco
+// atrás de linhas filhas que podem ainda não ter chegado pelo outro tópico.
+// Mudanças malformadas são descartadas com log sem impedir o restante da transação.
+func (h *CDCEventHandler) AplicarTransacao(ctx context.Context, txID string, mudancas []MudancaCDC) error {
+	sort.SliceStable(mudancas, func(i, j int) bool {
+		return mudancas[i].Evento.TotalOrder < mudancas[j].Evento.TotalOrder
+	})
+
+	unidade := &unidadeCDC{prescricoes: make(map[int]DebeziumEvent)}
+	for _, m := range mudancas {
+		if m.Tabela != TabelaPrescricoes || m.Evento.removido() {
+			continue
+		}
+		if id, err := m.Evento.campoInt("id"); err == nil {
+			unidade.prescricoes[id] = m.Evento
+		}
+	}
+
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	aplicadas := 0
+	for _, m := range mudancas {
+		eventID, err := m.chave()
+		if errors.Is(err, ErrEventoInvalido) {
+			log.Printf("❌ Descartando mudança inválida da transação %s: %v", txID, err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		novo, err := registrarEventoProcessado(ctx, tx, eventID, "cdc."+m.Tabela+"."+m.Evento.Op)
+		if err != nil {
+			return err
+		}
+		if !novo {
+			continue
+		}
+
+		// Validação dos campos acontece antes de qualquer SQL da mudança,
+		// então descartar uma mudança inválida não deixa a transação pela metade
+		if err := h.aplicarMudanca(ctx, tx, m, unidade); err != nil {
+			if errors.Is(err, ErrEventoInvalido) {
+				log.Printf("❌ Descartando mudança inválida da transação %s: %v", txID, err)
+				continue
+			}
+			return err
+		}
+		aplicadas++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+
+	log.Printf("Transação CDC %s aplicada nas views: %d de %d mudança(s)", txID, aplicadas, len(mudancas))
+	return nil
+}
+
+// aplicarMudanca roteia a mudança para a tabela de origem
+func (h *CDCEventHandler) aplicarMudanca(ctx context.Context, tx *sql.Tx, m MudancaCDC, unidade *unidadeCDC) error {
+	switch m.Tabela {
+	case TabelaPrescricoes:
+		return h.aplicarPrescricao(ctx, tx, m.Evento, unidade)
+	case TabelaPrescricaoMedicamentos:
+		return h.aplicarMedicamento(ctx, tx, m.Evento, unidade)
+	case TabelaDispensacoes:
+		return h.aplicarDispensacao(ctx, tx, m.Evento)
+	case TabelaMedicos, TabelaPacientes, TabelaMedicamentos:
+		return h.aplicarCadastro(ctx, tx, m)
+	default:
+		log.Printf("Tabela CDC desconhecida: %s", m.Tabela)
+		return nil
+	}
+}
+
+// aplicarPrescricao projeta uma mudança em Prescricoes
+func (h *CDCEventHandler) aplicarPrescricao(ctx context.Context, tx *sql.Tx, event DebeziumEvent, unidade *unidadeCDC) error {
+	idPrescricao, err := event.campoInt("id")
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case event.removido():
+		// Diferente do cancelamento, não há histórico a manter no prontuário
+		if err := removerPrescricaoViews(ctx, tx, idPrescricao); err != nil {
+			return err
+		}
+		log.Printf("Prescrição %d removida das views", idPrescricao)
+		return nil
+
+	case event.Op == "u":
+		// Todo comando incrementa Prescricoes.versao: cancelamento ou alteração de medicamentos
+		status, _ := event.Data["status"].(string)
+		if status == domain.StatusPrescricaoCancelada {
+			if err := cancelarViews(ctx, tx, idPrescricao, event.instante("cancelada_em")); err != nil {
+				return err
+			}
+			log.Printf("Prescrição %d cancelada nas views", idPrescricao)
+		} else if unidade == nil {
+			// Isolado, os UPDATEs de Prescricao_Medicamentos do mesmo comando podem ainda não ter
+			// chegado pelo outro tópico: ressincronizar a partir do modelo de escrita. Na unidade
+			// eles já foram aplicados antes deste evento (mesma transação, total_order menor)
+			if err := h.sincronizarMedicamentos(ctx, tx, idPrescricao, event.instante("")); err != nil {
+				return err
+			}
+		}
+		// Quando a versão aparece em View_Versao_Prescricao, as views já refletem aquela escrita
+		return registrarVersao(ctx, tx, idPrescricao, event.versao())
+
+	case event.Op == "c" || event.Op == "r":
+		// Na unidade, cada INSERT de Prescricao_Medicamentos da transação projeta a própria linha;
+		// isolado (snapshot), os medicamentos vêm do modelo de escrita
+		if unidade == nil {
+			if err := h.projetarMedicamentosDoBanco(ctx, tx, event, idPrescricao); err != nil {
+				return err
+			}
+		}
+
+		// Snapshot de prescrição já cancelada: manter fora da farmácia
+		if status, _ := event.Data["status"].(string); status == domain.StatusPrescricaoCancelada {
+			if err := cancelarViews(ctx, tx, idPrescricao, event.instante("cancelada_em")); err != nil {
+				return err
+			}
+		}
+		log.Printf("Prescrição %d projetada nas views", idPrescricao)
+		return registrarVersao(ctx, tx, idPrescricao, event.versao())
+
+	default:
+		log.Printf("Ignorando operação %s desconhecida", event.Op)
+		return nil
+	}
+}
+
+// projetarMedicamentosDoBanco popula as views com todos os medicamentos já gravados da prescrição
+func (h *CDCEventHandler) projetarMedicamentosDoBanco(ctx context.Context, tx *sql.Tx, event DebeziumEvent, idPrescricao int) error {
+	idMedico, err := event.campoInt("id_medico")
+	if err != nil {
+		return err
+	}
+	idPaciente, err := event.campoInt("id_paciente")
+	if err != nil {
+		return err
+	}
+	dataPrescricao, err := event.campoTimestamp("data_prescricao")
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Processando prescrição CDC: ID=%d Médico=%d Paciente=%d Data=%s",
+		idPrescricao, idMedico, idPaciente, dataPrescricao.Format(time.RFC3339))
+
+	// Buscar dados completos para popular as views
+	medico, err := buscarMedico(ctx, h.db, idMedico)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar médico: %w", err)
+	}
+
+	paciente, err := buscarPaciente(ctx, h.db, idPaciente)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar paciente: %w", err)
+	}
+
+	medicamentos, err := h.getMedicamentosPrescricao(ctx, idPrescricao)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar medicamentos: %w", err)
+	}
+
+	for _, med := range medicamentos {
+		item := ItemPrescricao{
+			IDPrescricao:   idPrescricao,
+			DataPrescricao: dataPrescricao,
+			Medico:         medico,
+			Paciente:       paciente,
+			Medicamento:    med,
+		}
+		if err := atualizarViewFarmacia(ctx, tx, item); err != nil {
+			return err
+		}
+		if err := atualizarViewProntuario(ctx, tx, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sincronizarMedicamentos reaplica dosagem/horário atuais de todos os medicamentos da prescrição
+func (h *CDCEventHandler) sincronizarMedicamentos(ctx context.Context, tx *sql.Tx, idPrescricao int, desde time.Time) error {
+	medicamentos, err := h.getMedicamentosPrescricao(ctx, idPrescricao)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar medicamentos: %w", err)
+	}
+
+	for _, med := range medicamentos {
+		if err := atualizarMedicamentoViews(ctx, tx, idPrescricao, med.ID, med.Horario, med.Dosagem, desde); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// aplicarMedicamento projeta uma mudança em Prescricao_Medicamentos
+func (h *CDCEventHandler) aplicarMedicamento(ctx context.Context, tx *sql.Tx, event DebeziumEvent, unidade *unidadeCDC) error {
+	// No DELETE o payload é a imagem anterior da linha (REPLICA IDENTITY FULL)
+	idPrescricao, err := event.campoInt("id_prescricao")
+	if err != nil {
+		return err
+	}
+	idMedicamento, err := event.campoInt("id_medicamento")
+	if err != nil {
+		return err
+	}
+
+	// Medicamento removido da prescrição: tirar a linha das duas views
+	if event.removido() {
+		if err := removerMedicamentoViews(ctx, tx, idPrescricao, idMedicamento); err != nil {
+			return err
+		}
+		log.Printf("Medicamento CDC removido: Prescrição=%d Medicamento=%d", idPrescricao, idMedicamento)
+		return nil
+	}
+
+	if event.Op != "c" && event.Op != "r" && event.Op != "u" {
+		log.Printf("Ignorando operação %s desconhecida", event.Op)
+		return nil
+	}
+
+	horario, err := event.campoString("horario")
+	if err != nil {
+		return err
+	}
+	dosagem, err := event.campoString("dosagem")
+	if err != nil {
+		return err
+	}
+
+	// Atualização de dosagem/horário: aplicar direto nas linhas existentes das views
+	if event.Op == "u" {
+		if err := atualizarMedicamentoViews(ctx, tx, idPrescricao, idMedicamento, horario, dosagem, event.instante("updated_at")); err != nil {
+			return err
+		}
+		log.Printf("Medicamento CDC atualizado: Prescrição=%d Medicamento=%d", idPrescricao, idMedicamento)
+		return nil
+	}
+
+	log.Printf("Processando medicamento CDC: Prescrição=%d Medicamento=%d", idPrescricao, idMedicamento)
+
+	prescricao, err := h.cabecalhoPrescricao(ctx, idPrescricao, unidade)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Prescrição já removida do modelo de escrita: o DELETE (em cascata) chega depois
+		// e não há o que projetar; falhar aqui travaria a partição em retry
+		log.Printf("Prescrição %d não existe mais - ignorando medicamento %d", idPrescricao, idMedicamento)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Buscar dados completos
+	medico, err := buscarMedico(ctx, h.db, prescricao["id_medico"].(int))
+	if err != nil {
+		return fmt.Errorf("erro ao buscar médico: %w", err)
+	}
+
+	paciente, err := buscarPaciente(ctx, h.db, prescricao["id_paciente"].(int))
+	if err != nil {
+		return fmt.Errorf("erro ao buscar paciente: %w", err)
+	}
+
+	medicamento, err := buscarMedicamento(ctx, h.db, idMedicamento)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar medicamento: %w", err)
+	}
+
+	medicamento.Horario = horario
+	medicamento.Dosagem = dosagem
+	item := ItemPrescricao{
+		IDPrescricao:   idPrescricao,
+		DataPrescricao: prescricao["data_prescricao"].(time.Time),
+		Medico:         medico,
+		Paciente:       paciente,
+		Medicamento:    medicamento,
+	}
+
+	// Upsert: a prescrição pode já ter populado esta linha
+	if err := atualizarViewFarmacia(ctx, tx, item); err != nil {
+		return err
+	}
+	if err := atualizarViewProntuario(ctx, tx, item); err != nil {
+		return err
+	}
+
+	// Snapshot de medicamento de prescrição já cancelada: manter fora da farmácia
+	if prescricao["status"] == domain.StatusPrescricaoCancelada {
+		canceladaEm, _ := prescricao["cancelada_em"].(time.Time)
+		return cancelarViews(ctx, tx, idPrescricao, canceladaEm)
+	}
+
+	log.Printf("Medicamento CDC processado e views atualizadas")
+	return nil
+}
+
+// aplicarDispensacao projeta uma mudança em Dispensacoes
+// A tabela é append-only: só INSERTs chegam no fluxo normal; o DELETE vem da remoção em
+// cascata da prescrição ou do item, que já limpa as views (aqui só o registro do histórico)
+func (h *CDCEventHandler) aplicarDispensacao(ctx context.Context, tx *sql.Tx, event DebeziumEvent) error {
+	id, err := event.campoInt("id")
+	if err != nil {
+		return err
+	}
+
+	if event.removido() {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM View_Prontuario_Dispensacoes WHERE id_dispensacao = $1`, id); err != nil {
+			return fmt.Errorf("erro ao remover dispensação de View_Prontuario_Dispensacoes: %w", err)
+		}
+		return nil
+	}
+
+	if event.Op != "c" && event.Op != "r" {
+		log.Printf("Ignorando operação %s em dispensação %d (registros não são alterados)", event.Op, id)
+		return nil
+	}
+
+	idPrescricao, err := event.campoInt("id_prescricao")
+	if err != nil {
+		return err
+	}
+	idMedicamento, err := event.campoInt("id_medicamento")
+	if err != nil {
+		return err
+	}
+	status, err := event.campoString("status")
+	if err != nil {
+		return err
+	}
+	farmaceutico, err := event.campoString("farmaceutico")
+	if err != nil {
+		return err
+	}
+	dispensadoEm, err := event.campoTimestamp("dispensado_em")
+	if err != nil {
+		return err
+	}
+	// Colunas opcionais chegam como null
+	quantidade, _ := event.Data["quantidade"].(string)
+	motivo, _ := event.Data["motivo"].(string)
+
+	d := readmodel.Dispensacao{
+		ID:           id,
+		Status:       status,
+		Quantidade:   quantidade,
+		Motivo:       motivo,
+		Farmaceutico: farmaceutico,
+		DispensadoEm: dispensadoEm,
+	}
+	if err := registrarDispensacaoViews(ctx, tx, idPrescricao, idMedicamento, d); err != nil {
+		return err
+	}
+
+	log.Printf("Dispensação CDC processada: Prescrição=%d Medicamento=%d Status=%s", idPrescricao, idMedicamento, status)
+	return nil
+}
+
+// cabecalhoPrescricao obtém médico, paciente, data e status da prescrição do medicamento:
+// da própria transação de origem quando disponível, senão do modelo de escrita
+func (h *CDCEventHandler) cabecalhoPrescricao(ctx context.Context, idPrescricao int, unidade *unidadeCDC) (map[string]interface{}, error) {
+	event, ok := unidade.prescricao(idPrescricao)
+	if !ok {
+		prescricao, err := h.getPrescricao(ctx, idPrescricao)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("erro ao buscar prescrição: %w", err)
+		}
+		return prescricao, err
+	}
+
+	idMedico, err := event.campoInt("id_medico")
+	if err != nil {
+		return nil, err
+	}
+	idPaciente, err := event.campoInt("id_paciente")
+	if err != nil {
+		return nil, err
+	}
+	dataPrescricao, err := event.campoTimestamp("data_prescricao")
+	if err != nil {
+		return nil, err
+	}
+	status, _ := event.Data["status"].(string)
+
+	return map[string]interface{}{
+		"id":              idPrescricao,
+		"id_medico":       idMedico,
+		"id_paciente":     idPaciente,
+		"data_prescricao": dataPrescricao,
+		"status":          status,
+		"cancelada_em":    event.instante("cancelada_em"),
+	}, nil
+}
+
+// registrarVersao grava a versão da prescrição já refletida nas views
+// GREATEST mantém o registro monotônico mesmo se um evento antigo for reentregue fora de ordem
+func registrarVersao(ctx context.Context, tx *sql.Tx, idPrescricao, versao int) error {
+	if versao == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO View_Versao_Prescricao (id_prescricao, versao, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (id_prescricao) DO UPDATE SET
+			versao = GREATEST(View_Versao_Prescricao.versao, EXCLUDED.versao),
+			updated_at = NOW()
+	`
+	if _, err := tx.ExecContext(ctx, query, idPrescricao, versao); err != nil {
+		return fmt.Errorf("erro ao registrar versão da prescrição: %w", err)
+	}
+	return nil
+}
+
+// removerPrescricaoViews apaga todas as linhas da prescrição nas views, os históricos e o registro de versão
+func removerPrescricaoViews(ctx context.Context, tx *sql.Tx, idPrescricao int) error {
+	for _, tabela := range []string{"View_Farmacia", "View_Prontuario_Paciente", "View_Prontuario_Historico", "View_Prontuario_Dispensacoes", "View_Versao_Prescricao"} {
+		query := fmt.Sprintf("DELETE FROM %s WHERE id_prescricao = $1", tabela)
+		if _, err := tx.ExecContext(ctx, query, idPrescricao); err != nil {
+			return fmt.Errorf("erro ao remover prescrição de %s: %w", tabela, err)
+		}
+	}
+	return nil
+}
+
+// removerMedicamentoViews apaga a linha do medicamento da prescrição nas duas views e os históricos dela
+func removerMedicamentoViews(ctx context.Context, tx *sql.Tx, idPrescricao, idMedicamento int) error {
+	for _, tabela := range []string{"View_Farmacia", "View_Prontuario_Paciente", "View_Prontuario_Historico", "View_Prontuario_Dispensacoes"} {
+		query := fmt.Sprintf("DELETE FROM %s WHERE id_prescricao = $1 AND medicamento_id = $2", tabela)
+		if _, err := tx.ExecContext(ctx, query, idPrescricao, idMedicamento); err != nil {
+			return fmt.Errorf("erro ao remover medicamento de %s: %w", tabela, err)
+		}
+	}
+	return nil
+}
+
+// getMedicamentosPrescricao busca todos os medicamentos de uma prescrição
+func (h *CDCEventHandler) getMedicamentosPrescricao(ctx context.Context, idPrescricao int) ([]readmodel.Medicamento, error) {
+	query := `
+		SELECT 
+			m.id, m.nome, m.descricao,
+			pm.horario, pm.dosagem
+		FROM Prescricao_Medicamentos pm
+		JOIN Medicamentos m ON m.id = pm.id_medicamento
+		WHERE pm.id_prescricao = $1
+	`
+
+	rows, err := h.db.QueryContext(ctx, query, idPrescricao)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var medicamentos []readmodel.Medicamento
+	for rows.Next() {
+		var m readmodel.Medicamento
+		if err := rows.Scan(&m.ID, &m.Nome, &m.Descricao, &m.Horario, &m.Dosagem); err != nil {
+			return nil, err
+		}
+		medicamentos = append(medicamentos, m)
+	}
+
+	return medicamentos, rows.Err()
+}
+
+// getPrescricao busca uma prescrição por ID
+func (h *CDCEventHandler) getPrescricao(ctx context.Context, id int) (map[string]interface{}, error) {
+	var (
+		prescricaoID   int
+		idMedico       int
+		idPaciente     int
+		dataPrescricao time.Time
+		status         string
+		canceladaEm    sql.NullTime
+	)
+
+	query := `SELECT id, id_medico, id_paciente, data_prescricao, status, cancelada_em FROM Prescricoes WHERE id = $1`
+	err := h.db.QueryRowContext(ctx, query, id).Scan(&prescricaoID, &idMedico, &idPaciente, &dataPrescricao, &status, &canceladaEm)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"id":              prescricaoID,
+		"id_medico":       idMedico,
+		"id_paciente":     idPaciente,
+		"data_prescricao": dataPrescricao,
+		"status":          status,
+		"cancelada_em":    canceladaEm.Time,
+	}, nil
+}
@@ -0,0 +1,281 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// =========================================
+// AGRUPAMENTO POR TRANSAÇÃO DE ORIGEM
+// =========================================
+
+// colecoesMonitoradas são as data_collections do END que interessam às views
+var colecoesMonitoradas = map[string]bool{
+	"public." + TabelaPrescricoes:            true,
+	"public." + TabelaPrescricaoMedicamentos: true,
+	"public." + TabelaDispensacoes:           true,
+	"public." + TabelaMedicos:                true,
+	"public." + TabelaPacientes:              true,
+	"public." + TabelaMedicamentos:           true,
+}
+
+// marcadorTransacao é a mensagem do tópico de metadados (<prefixo>.transaction)
+type marcadorTransacao struct {
+	Status          string `json:"status"` // BEGIN | END
+	ID              string `json:"id"`
+	EventCount      int    `json:"event_count"`
+	DataCollections []struct {
+		DataCollection string `json:"data_collection"`
+		EventCount     int    `json:"event_count"`
+	} `json:"data_collections"`
+}
+
+// eventosMonitorados conta quantos eventos das tabelas das views a transação gerou
+func (m marcadorTransacao) eventosMonitorados() int {
+	if len(m.DataCollections) == 0 {
+		return m.EventCount
+	}
+	total := 0
+	for _, dc := range m.DataCollections {
+		if colecoesMonitoradas[dc.DataCollection] {
+			total += dc.EventCount
+		}
+	}
+	return total
+}
+
+// transacaoAberta acumula os eventos de uma transação até ela estar completa
+type transacaoAberta struct {
+	mudancas     []MudancaCDC
+	confirmacoes []func() // marcam os offsets das mensagens (dados, BEGIN e END)
+	esperado     int      // eventos informados pelo END; -1 enquanto o END não chegou
+	desde        time.Time
+}
+
+func (t *transacaoAberta) completa() bool {
+	return t.esperado >= 0 && len(t.mudancas) >= t.esperado
+}
+
+// AgregadorTransacoes agrupa os eventos CDC pela transação de origem
+//
+// Com provide.transaction.metadata o Debezium publica BEGIN/END em hospital_db.transaction
+// e marca cada evento de dados com __transaction_id. Prescricoes e Prescricao_Medicamentos
+// chegam por tópicos diferentes, sem ordem entre si: os eventos ficam em memória até o END
+// informar quantos eram e todos terem chegado; então a transação inteira é aplicada nas views
+// de uma vez (CDCEventHandler.AplicarTransacao).
+//
+// Os offsets só são confirmados depois da aplicação. Se o processo cair com uma transação
+// pela metade, o Kafka reentrega as mensagens e Processed_Events descarta o que já foi aplicado.
+// Eventos sem __transaction_id (snapshot) e transações cujo END não chega dentro do timeout
+// são aplicados um a um, como antes.
+type AgregadorTransacoes struct {
+	handler *CDCEventHandler
+	timeout time.Duration
+
+	mu      sync.Mutex
+	abertas map[string]*transacaoAberta
+}
+
+// NewAgregadorTransacoes cria o agregador; timeout limita quanto uma transação incompleta
+// pode ficar em memória antes de ser aplicada evento a evento
+func NewAgregadorTransacoes(handler *CDCEventHandler, timeout time.Duration) *AgregadorTransacoes {
+	return &AgregadorTransacoes{
+		handler: handler,
+		timeout: timeout,
+		abertas: make(map[string]*transacaoAberta),
+	}
+}
+
+// ReceberMudanca recebe um evento de dados da tabela; confirmar marca o offset da mensagem
+func (a *AgregadorTransacoes) ReceberMudanca(ctx context.Context, tabela string, eventData []byte, confirmar func()) error {
+	event, ok, err := decodificarCDC(eventData)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		log.Printf("Tombstone de %s recebido - nada a aplicar", tabela)
+		confirmar()
+		return nil
+	}
+
+	mudanca := MudancaCDC{Tabela: tabela, Evento: event}
+
+	// Snapshot ou connector sem metadados de transação: aplicar isoladamente
+	if event.TxID == "" {
+		if err := a.handler.aplicarIsolado(ctx, mudanca); err != nil {
+			return err
+		}
+		confirmar()
+		return nil
+	}
+
+	a.mu.Lock()
+	t := a.abrir(event.TxID)
+	t.mudancas = append(t.mudancas, mudanca)
+	t.confirmacoes = append(t.confirmacoes, confirmar)
+	pronta := a.retirarSeCompleta(event.TxID)
+	a.mu.Unlock()
+
+	if pronta != nil {
+		return a.aplicar(ctx, event.TxID, pronta)
+	}
+	return nil
+}
+
+// ReceberMarcador recebe um BEGIN/END do tópico de metadados de transação
+func (a *AgregadorTransacoes) ReceberMarcador(ctx context.Context, eventData []byte, confirmar func()) error {
+	if len(eventData) == 0 {
+		confirmar()
+		return nil
+	}
+
+	var marcador marcadorTransacao
+	if err := json.Unmarshal(eventData, &marcador); err != nil {
+		return fmt.Errorf("%w: erro ao deserializar marcador de transação: %v", ErrEventoInvalido, err)
+	}
+	if marcador.ID == "" {
+		return fmt.Errorf("%w: marcador de transação sem id", ErrEventoInvalido)
+	}
+
+	a.mu.Lock()
+	t := a.abrir(marcador.ID)
+	t.confirmacoes = append(t.confirmacoes, confirmar)
+	var pronta *transacaoAberta
+	if marcador.Status == "END" {
+		t.esperado = marcador.eventosMonitorados()
+		pronta = a.retirarSeCompleta(marcador.ID)
+	}
+	a.mu.Unlock()
+
+	if pronta != nil {
+		return a.aplicar(ctx, marcador.ID, pronta)
+	}
+	return nil
+}
+
+// Iniciar aplica periodicamente as transações que passaram do timeout sem completar
+func (a *AgregadorTransacoes) Iniciar(ctx context.Context) {
+	ticker := time.NewTicker(a.timeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.expirar(ctx)
+		}
+	}
+}
+
+// Descartar limpa o que está em memória (rebalance): as mensagens não confirmadas
+// serão reentregues ao consumidor que assumir as partições
+func (a *AgregadorTransacoes) Descartar() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.abertas) > 0 {
+		log.Printf("Descartando %d transação(ões) CDC incompleta(s) - serão reentregues", len(a.abertas))
+	}
+	a.abertas = make(map[string]*transacaoAberta)
+}
+
+// abrir devolve a transação em memória, criando se for o primeiro evento (chamar com mu)
+func (a *AgregadorTransacoes) abrir(txID string) *transacaoAberta {
+	t, ok := a.abertas[txID]
+	if !ok {
+		t = &transacaoAberta{esperado: -1, desde: time.Now()}
+		a.abertas[txID] = t
+	}
+	return t
+}
+
+// retirarSeCompleta remove e devolve a transação se todos os eventos chegaram (chamar com mu)
+func (a *AgregadorTransacoes) retirarSeCompleta(txID string) *transacaoAberta {
+	t := a.abertas[txID]
+	if t == nil || !t.completa() {
+		return nil
+	}
+	delete(a.abertas, txID)
+	return t
+}
+
+// aplicar grava a transação nas views, tentando de novo até conseguir ou o ctx ser cancelado
+// Só então confirma os offsets de todas as mensagens que a compõem
+func (a *AgregadorTransacoes) aplicar(ctx context.Context, txID string, t *transacaoAberta) error {
+	espera := 500 * time.Millisecond
+	for len(t.mudancas) > 0 {
+		err := a.handler.AplicarTransacao(ctx, txID, t.mudancas)
+		if err == nil {
+			break
+		}
+
+		log.Printf("Erro ao aplicar transação CDC %s (nova tentativa em %s): %v", txID, espera, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(espera):
+		}
+		if espera < 30*time.Second {
+			espera *= 2
+		}
+	}
+
+	for _, confirmar := range t.confirmacoes {
+		confirmar()
+	}
+	return nil
+}
+
+// expirar aplica evento a evento as transações incompletas há mais que o timeout
+// (END perdido ou connector sem provide.transaction.metadata para alguma tabela)
+func (a *AgregadorTransacoes) expirar(ctx context.Context) {
+	a.mu.Lock()
+	expiradas := make(map[string]*transacaoAberta)
+	for txID, t := range a.abertas {
+		if time.Since(t.desde) > a.timeout {
+			expiradas[txID] = t
+			delete(a.abertas, txID)
+		}
+	}
+	a.mu.Unlock()
+
+	for txID, t := range expiradas {
+		log.Printf("AVISO: transação CDC %s incompleta após %s (%d evento(s), esperado %d) - aplicando evento a evento",
+			txID, a.timeout, len(t.mudancas), t.esperado)
+
+		if err := a.aplicarIsoladas(ctx, t); err != nil {
+			// Volta para a fila e é tentada de novo no próximo ciclo; Processed_Events
+			// descarta os eventos que já tinham sido aplicados
+			log.Printf("❌ Erro ao aplicar eventos da transação %s: %v", txID, err)
+			a.mu.Lock()
+			t.desde = time.Now()
+			a.abertas[txID] = t
+			a.mu.Unlock()
+			continue
+		}
+		for _, confirmar := range t.confirmacoes {
+			confirmar()
+		}
+	}
+}
+
+// aplicarIsoladas aplica cada mudança em sua própria transação, descartando as malformadas
+func (a *AgregadorTransacoes) aplicarIsoladas(ctx context.Context, t *transacaoAberta) error {
+	for _, m := range t.mudancas {
+		err := a.handler.aplicarIsolado(ctx, m)
+		if errors.Is(err, ErrEventoInvalido) {
+			log.Printf("❌ Descartando evento CDC inválido: %v", err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
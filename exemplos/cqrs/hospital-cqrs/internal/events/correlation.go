@@ -0,0 +1,20 @@
+package events
+
+import "context"
+
+// CorrelationIDHeader é o header HTTP (e Kafka) que carrega o correlation id de uma requisição
+const CorrelationIDHeader = "X-Correlation-ID"
+
+type correlationIDKey struct{}
+
+// ComCorrelationID retorna um contexto que carrega o correlation id da requisição
+// Os comandos gravam esse valor na outbox para o relay repassá-lo nos headers Kafka
+func ComCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationIDFromContext retorna o correlation id do contexto ("" se ausente)
+func CorrelationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,340 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"hospital-cqrs/internal/domain"
+)
+
+// =========================================
+// DOMAIN EVENTS
+// =========================================
+
+// EventType representa o tipo de evento
+type EventType string
+
+const (
+	// PrescricaoCriadaEvent é disparado quando uma prescrição é criada
+	PrescricaoCriadaEvent EventType = "prescricao.criada"
+	// PrescricaoAtualizadaEvent é disparado quando dosagem/horário de medicamentos são alterados
+	PrescricaoAtualizadaEvent EventType = "prescricao.atualizada"
+	// PrescricaoCanceladaEvent é disparado quando uma prescrição é cancelada
+	PrescricaoCanceladaEvent EventType = "prescricao.cancelada"
+	// PrescricaoAlertasIgnoradosEvent audita a criação de uma prescrição apesar dos alertas
+	// das regras clínicas (ver regras.Motor)
+	PrescricaoAlertasIgnoradosEvent EventType = "prescricao.alertas_ignorados"
+	// MedicoAtualizadoEvent é disparado quando o cadastro de um médico é alterado
+	MedicoAtualizadoEvent EventType = "medico.atualizado"
+	// PacienteAtualizadoEvent é disparado quando o cadastro de um paciente é alterado
+	PacienteAtualizadoEvent EventType = "paciente.atualizado"
+	// MedicamentoAtualizadoEvent é disparado quando o cadastro de um medicamento é alterado
+	MedicamentoAtualizadoEvent EventType = "medicamento.atualizado"
+	// DispensacaoRealizadaEvent é disparado quando a farmácia entrega o medicamento prescrito
+	DispensacaoRealizadaEvent EventType = "dispensacao.realizada"
+	// DispensacaoParcialEvent é disparado quando a farmácia entrega só parte do medicamento
+	DispensacaoParcialEvent EventType = "dispensacao.parcial"
+	// DispensacaoRecusadaEvent é disparado quando a farmácia recusa a dispensação
+	DispensacaoRecusadaEvent EventType = "dispensacao.recusada"
+)
+
+// Versões atuais do schema de cada evento
+// Ao mudar um payload: incremente a versão aqui e registre o upcaster da versão anterior
+const (
+	PrescricaoCriadaSchemaVersion      = 2
+	PrescricaoAtualizadaSchemaVersion  = 1
+	PrescricaoCanceladaSchemaVersion   = 1
+	AlertasIgnoradosSchemaVersion      = 1
+	MedicoAtualizadoSchemaVersion      = 1
+	PacienteAtualizadoSchemaVersion    = 1
+	MedicamentoAtualizadoSchemaVersion = 1
+	DispensacaoSchemaVersion           = 1 // os três eventos de dispensação
+)
+
+// Event representa um evento do domínio (envelope)
+// Data carrega o payload tipado serializado; use DecodeData para obtê-lo
+type Event struct {
+	ID            string          `json:"id"`
+	Type          EventType       `json:"type"`
+	SchemaVersion int             `json:"schema_version"`
+	Timestamp     time.Time       `json:"timestamp"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// newEvent monta o envelope serializando o payload tipado
+func newEvent(eventType EventType, version int, data interface{}) (Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, fmt.Errorf("erro ao serializar dados do evento %s: %w", eventType, err)
+	}
+
+	return Event{
+		ID:            generateEventID(),
+		Type:          eventType,
+		SchemaVersion: version,
+		Timestamp:     time.Now(),
+		Data:          raw,
+	}, nil
+}
+
+// =========================================
+// PRESCRICAO CRIADA EVENT
+// =========================================
+
+// MedicamentoPrescritoEvent representa um medicamento em um evento
+type MedicamentoPrescritoEvent struct {
+	// IDPrescricaoMedicamento é o id da linha em Prescricao_Medicamentos (v2+; 0 em eventos v1)
+	IDPrescricaoMedicamento int    `json:"id_prescricao_medicamento"`
+	IDMedicamento           int    `json:"id_medicamento"`
+	Horario                 string `json:"horario"`
+	Dosagem                 string `json:"dosagem"`
+}
+
+// PrescricaoCriadaEventData contém os dados do evento de prescrição criada
+type PrescricaoCriadaEventData struct {
+	IDPrescricao   int                         `json:"id_prescricao"`
+	IDMedico       int                         `json:"id_medico"`
+	IDPaciente     int                         `json:"id_paciente"`
+	DataPrescricao time.Time                   `json:"data_prescricao"`
+	Medicamentos   []MedicamentoPrescritoEvent `json:"medicamentos"`
+}
+
+// Validate verifica os campos obrigatórios do payload
+func (d PrescricaoCriadaEventData) Validate() error {
+	if d.IDPrescricao <= 0 || d.IDMedico <= 0 || d.IDPaciente <= 0 {
+		return fmt.Errorf("id_prescricao, id_medico e id_paciente são obrigatórios")
+	}
+	if len(d.Medicamentos) == 0 {
+		return fmt.Errorf("prescrição %d sem medicamentos", d.IDPrescricao)
+	}
+	return validarMedicamentos(d.Medicamentos)
+}
+
+// NewPrescricaoCriadaEvent cria um novo evento de prescrição criada
+func NewPrescricaoCriadaEvent(data PrescricaoCriadaEventData) (Event, error) {
+	return newEvent(PrescricaoCriadaEvent, PrescricaoCriadaSchemaVersion, data)
+}
+
+// =========================================
+// PRESCRICAO ATUALIZADA EVENT
+// =========================================
+
+// PrescricaoAtualizadaEventData contém os dados do evento de prescrição atualizada
+type PrescricaoAtualizadaEventData struct {
+	IDPrescricao int                         `json:"id_prescricao"`
+	Medicamentos []MedicamentoPrescritoEvent `json:"medicamentos"`
+	AtualizadaEm time.Time                   `json:"atualizada_em"`
+}
+
+// Validate verifica os campos obrigatórios do payload
+func (d PrescricaoAtualizadaEventData) Validate() error {
+	if d.IDPrescricao <= 0 {
+		return fmt.Errorf("id_prescricao é obrigatório")
+	}
+	return validarMedicamentos(d.Medicamentos)
+}
+
+// NewPrescricaoAtualizadaEvent cria um novo evento de prescrição atualizada
+func NewPrescricaoAtualizadaEvent(data PrescricaoAtualizadaEventData) (Event, error) {
+	return newEvent(PrescricaoAtualizadaEvent, PrescricaoAtualizadaSchemaVersion, data)
+}
+
+// =========================================
+// PRESCRICAO CANCELADA EVENT
+// =========================================
+
+// PrescricaoCanceladaEventData contém os dados do evento de prescrição cancelada
+type PrescricaoCanceladaEventData struct {
+	IDPrescricao int       `json:"id_prescricao"`
+	Motivo       string    `json:"motivo"`
+	CanceladaEm  time.Time `json:"cancelada_em"`
+}
+
+// Validate verifica os campos obrigatórios do payload
+func (d PrescricaoCanceladaEventData) Validate() error {
+	if d.IDPrescricao <= 0 {
+		return fmt.Errorf("id_prescricao é obrigatório")
+	}
+	return nil
+}
+
+// NewPrescricaoCanceladaEvent cria um novo evento de prescrição cancelada
+func NewPrescricaoCanceladaEvent(data PrescricaoCanceladaEventData) (Event, error) {
+	return newEvent(PrescricaoCanceladaEvent, PrescricaoCanceladaSchemaVersion, data)
+}
+
+// =========================================
+// PRESCRICAO ALERTAS IGNORADOS EVENT
+// =========================================
+
+// AlertasIgnoradosEventData registra quem prescreveu apesar dos alertas, com qual
+// justificativa e em qual modo das regras clínicas
+type AlertasIgnoradosEventData struct {
+	IDPrescricao  int                    `json:"id_prescricao"`
+	IDMedico      int                    `json:"id_medico"`
+	IDPaciente    int                    `json:"id_paciente"`
+	Modo          string                 `json:"modo"` // bloquear | alertar
+	Justificativa string                 `json:"justificativa,omitempty"`
+	Alertas       []domain.AlertaClinico `json:"alertas"`
+	RegistradoEm  time.Time              `json:"registrado_em"`
+}
+
+// Validate verifica os campos obrigatórios do payload
+func (d AlertasIgnoradosEventData) Validate() error {
+	if d.IDPrescricao <= 0 {
+		return fmt.Errorf("id_prescricao é obrigatório")
+	}
+	if len(d.Alertas) == 0 {
+		return fmt.Errorf("prescrição %d: evento de auditoria sem alertas", d.IDPrescricao)
+	}
+	return nil
+}
+
+// NewAlertasIgnoradosEvent cria o evento de auditoria dos alertas ignorados
+func NewAlertasIgnoradosEvent(data AlertasIgnoradosEventData) (Event, error) {
+	return newEvent(PrescricaoAlertasIgnoradosEvent, AlertasIgnoradosSchemaVersion, data)
+}
+
+// =========================================
+// DISPENSAÇÃO EVENTS
+// =========================================
+// Os três resultados da dispensação têm o mesmo payload; o tipo do evento define o status
+// do medicamento (ver StatusDispensacao).
+
+// eventosDispensacao relaciona cada evento de dispensação ao status que ele registra
+var eventosDispensacao = map[EventType]string{
+	DispensacaoRealizadaEvent: domain.StatusDispensacaoDispensado,
+	DispensacaoParcialEvent:   domain.StatusDispensacaoParcial,
+	DispensacaoRecusadaEvent:  domain.StatusDispensacaoRecusado,
+}
+
+// DispensacaoEventData contém os dados de uma dispensação registrada pela farmácia
+type DispensacaoEventData struct {
+	IDDispensacao           int       `json:"id_dispensacao"`
+	IDPrescricao            int       `json:"id_prescricao"`
+	IDPrescricaoMedicamento int       `json:"id_prescricao_medicamento"`
+	IDMedicamento           int       `json:"id_medicamento"`
+	Quantidade              string    `json:"quantidade,omitempty"`
+	Motivo                  string    `json:"motivo,omitempty"`
+	Farmaceutico            string    `json:"farmaceutico"`
+	DispensadoEm            time.Time `json:"dispensado_em"`
+}
+
+// Validate verifica os campos obrigatórios do payload
+func (d DispensacaoEventData) Validate() error {
+	if d.IDDispensacao <= 0 || d.IDPrescricao <= 0 || d.IDMedicamento <= 0 {
+		return fmt.Errorf("id_dispensacao, id_prescricao e id_medicamento são obrigatórios")
+	}
+	if d.DispensadoEm.IsZero() {
+		return fmt.Errorf("dispensação %d sem dispensado_em", d.IDDispensacao)
+	}
+	return nil
+}
+
+// StatusDispensacao retorna o status registrado pelo evento (ok=false se não for de dispensação)
+func StatusDispensacao(eventType EventType) (string, bool) {
+	status, ok := eventosDispensacao[eventType]
+	return status, ok
+}
+
+// NewDispensacaoEvent cria o evento de dispensação do status informado
+func NewDispensacaoEvent(status string, data DispensacaoEventData) (Event, error) {
+	for eventType, s := range eventosDispensacao {
+		if s == status {
+			return newEvent(eventType, DispensacaoSchemaVersion, data)
+		}
+	}
+	return Event{}, fmt.Errorf("status de dispensação desconhecido: %q", status)
+}
+
+// =========================================
+// EVENTOS DE CADASTRO (DADOS DE REFERÊNCIA)
+// =========================================
+// Carregam o estado completo do cadastro, não só o que mudou: reaplicar o evento
+// produz o mesmo resultado. AtualizadoEm ordena as versões de um mesmo cadastro.
+
+// MedicoAtualizadoEventData contém o cadastro atual do médico
+type MedicoAtualizadoEventData struct {
+	IDMedico      int       `json:"id_medico"`
+	Nome          string    `json:"nome"`
+	Especialidade string    `json:"especialidade"`
+	CRM           string    `json:"crm"`
+	AtualizadoEm  time.Time `json:"atualizado_em"`
+}
+
+// Validate verifica os campos obrigatórios do payload
+func (d MedicoAtualizadoEventData) Validate() error {
+	if d.IDMedico <= 0 || d.Nome == "" || d.CRM == "" {
+		return fmt.Errorf("id_medico, nome e crm são obrigatórios")
+	}
+	return nil
+}
+
+// NewMedicoAtualizadoEvent cria um novo evento de médico atualizado
+func NewMedicoAtualizadoEvent(data MedicoAtualizadoEventData) (Event, error) {
+	return newEvent(MedicoAtualizadoEvent, MedicoAtualizadoSchemaVersion, data)
+}
+
+// PacienteAtualizadoEventData contém o cadastro atual do paciente
+type PacienteAtualizadoEventData struct {
+	IDPaciente     int       `json:"id_paciente"`
+	Nome           string    `json:"nome"`
+	DataNascimento time.Time `json:"data_nascimento"`
+	Endereco       string    `json:"endereco"`
+	AtualizadoEm   time.Time `json:"atualizado_em"`
+}
+
+// Validate verifica os campos obrigatórios do payload
+func (d PacienteAtualizadoEventData) Validate() error {
+	if d.IDPaciente <= 0 || d.Nome == "" || d.DataNascimento.IsZero() {
+		return fmt.Errorf("id_paciente, nome e data_nascimento são obrigatórios")
+	}
+	return nil
+}
+
+// NewPacienteAtualizadoEvent cria um novo evento de paciente atualizado
+func NewPacienteAtualizadoEvent(data PacienteAtualizadoEventData) (Event, error) {
+	return newEvent(PacienteAtualizadoEvent, PacienteAtualizadoSchemaVersion, data)
+}
+
+// MedicamentoAtualizadoEventData contém o cadastro atual do medicamento
+type MedicamentoAtualizadoEventData struct {
+	IDMedicamento int       `json:"id_medicamento"`
+	Nome          string    `json:"nome"`
+	Descricao     string    `json:"descricao"`
+	AtualizadoEm  time.Time `json:"atualizado_em"`
+}
+
+// Validate verifica os campos obrigatórios do payload
+func (d MedicamentoAtualizadoEventData) Validate() error {
+	if d.IDMedicamento <= 0 || d.Nome == "" {
+		return fmt.Errorf("id_medicamento e nome são obrigatórios")
+	}
+	return nil
+}
+
+// NewMedicamentoAtualizadoEvent cria um novo evento de medicamento atualizado
+func NewMedicamentoAtualizadoEvent(data MedicamentoAtualizadoEventData) (Event, error) {
+	return newEvent(MedicamentoAtualizadoEvent, MedicamentoAtualizadoSchemaVersion, data)
+}
+
+// validarMedicamentos verifica os itens de medicamento de um payload
+func validarMedicamentos(medicamentos []MedicamentoPrescritoEvent) error {
+	for _, med := range medicamentos {
+		if med.IDMedicamento <= 0 {
+			return fmt.Errorf("medicamento sem id_medicamento")
+		}
+		if med.Horario == "" || med.Dosagem == "" {
+			return fmt.Errorf("medicamento %d sem horário ou dosagem", med.IDMedicamento)
+		}
+	}
+	return nil
+}
+
+// generateEventID gera um ID único (UUID v4) para o evento
+func generateEventID() string {
+	return uuid.NewString()
+}
@@ -0,0 +1,450 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"hospital-cqrs/internal/readmodel"
+)
+
+// PrescricaoEventHandler processa eventos de prescrição
+// Os dados de referência vêm do modelo de escrita (db); as views são gravadas no modelo de leitura
+type PrescricaoEventHandler struct {
+	db    *sql.DB
+	views ModeloLeitura
+}
+
+// NewPrescricaoEventHandler cria um novo handler de eventos com as views no próprio Postgres
+func NewPrescricaoEventHandler(db *sql.DB) *PrescricaoEventHandler {
+	return NewPrescricaoEventHandlerComViews(db, NewModeloLeituraPostgres(db))
+}
+
+// NewPrescricaoEventHandlerComViews cria o handler gravando as views no modelo de leitura informado
+func NewPrescricaoEventHandlerComViews(db *sql.DB, views ModeloLeitura) *PrescricaoEventHandler {
+	return &PrescricaoEventHandler{db: db, views: views}
+}
+
+// HandleEvent decodifica o evento (aplicando upcasters) e roteia para o handler do seu tipo
+// Eventos malformados retornam erro envolvendo ErrEventoInvalido em vez de derrubar o consumidor
+func (h *PrescricaoEventHandler) HandleEvent(ctx context.Context, eventData []byte) error {
+	event, err := DecodeEvent(eventData)
+	if err != nil {
+		return err
+	}
+
+	switch event.Type {
+	case PrescricaoCriadaEvent:
+		return h.HandlePrescricaoCriada(ctx, event)
+	case PrescricaoAtualizadaEvent:
+		return h.HandlePrescricaoAtualizada(ctx, event)
+	case PrescricaoCanceladaEvent:
+		return h.HandlePrescricaoCancelada(ctx, event)
+	case PrescricaoAlertasIgnoradosEvent:
+		return h.HandleAlertasIgnorados(ctx, event)
+	case MedicoAtualizadoEvent:
+		return h.HandleMedicoAtualizado(ctx, event)
+	case PacienteAtualizadoEvent:
+		return h.HandlePacienteAtualizado(ctx, event)
+	case MedicamentoAtualizadoEvent:
+		return h.HandleMedicamentoAtualizado(ctx, event)
+	case DispensacaoRealizadaEvent, DispensacaoParcialEvent, DispensacaoRecusadaEvent:
+		return h.HandleDispensacao(ctx, event)
+	default:
+		log.Printf("Tipo de evento desconhecido: %s", event.Type)
+		return nil
+	}
+}
+
+// HandleAlertasIgnorados registra no log a auditoria dos alertas ignorados
+// O evento não muda as views: o registro é o próprio evento (e a tabela Auditoria_Regras_Clinicas)
+func (h *PrescricaoEventHandler) HandleAlertasIgnorados(ctx context.Context, event Event) error {
+	data, err := DecodeData[AlertasIgnoradosEventData](event, PrescricaoAlertasIgnoradosEvent)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Auditoria: prescrição %d criada pelo médico %d com %d alerta(s) clínico(s) ignorado(s) (modo %s)",
+		data.IDPrescricao, data.IDMedico, len(data.Alertas), data.Modo)
+	return nil
+}
+
+// HandlePrescricaoCriada processa o evento de prescrição criada
+func (h *PrescricaoEventHandler) HandlePrescricaoCriada(ctx context.Context, event Event) error {
+	data, err := DecodeData[PrescricaoCriadaEventData](event, PrescricaoCriadaEvent)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Processando evento: Prescrição %d criada", data.IDPrescricao)
+
+	// Buscar dados completos para popular as views
+	medico, err := buscarMedico(ctx, h.db, data.IDMedico)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar médico: %w", err)
+	}
+
+	paciente, err := buscarPaciente(ctx, h.db, data.IDPaciente)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar paciente: %w", err)
+	}
+
+	// Buscar medicamentos antes de abrir a transação de projeção
+	medicamentos := make([]readmodel.Medicamento, len(data.Medicamentos))
+	for i, med := range data.Medicamentos {
+		medicamentos[i], err = buscarMedicamento(ctx, h.db, med.IDMedicamento)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar medicamento: %w", err)
+		}
+	}
+
+	// Atualizar as duas views e registrar o evento na mesma transação
+	err = h.views.Projetar(ctx, event.ID, string(event.Type), func(views EscritaViews) error {
+		for i, med := range data.Medicamentos {
+			medicamentos[i].Horario = med.Horario
+			medicamentos[i].Dosagem = med.Dosagem
+			item := ItemPrescricao{
+				IDPrescricao:   data.IDPrescricao,
+				DataPrescricao: data.DataPrescricao,
+				Medico:         medico,
+				Paciente:       paciente,
+				Medicamento:    medicamentos[i],
+			}
+			if err := views.GravarItem(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Evento processado: Views atualizadas para prescrição %d", data.IDPrescricao)
+	return nil
+}
+
+// HandlePrescricaoAtualizada processa o evento de alteração de dosagem/horário
+func (h *PrescricaoEventHandler) HandlePrescricaoAtualizada(ctx context.Context, event Event) error {
+	data, err := DecodeData[PrescricaoAtualizadaEventData](event, PrescricaoAtualizadaEvent)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Processando evento: Prescrição %d atualizada", data.IDPrescricao)
+
+	err = h.views.Projetar(ctx, event.ID, string(event.Type), func(views EscritaViews) error {
+		for _, med := range data.Medicamentos {
+			if err := views.AtualizarItem(data.IDPrescricao, med.IDMedicamento, med.Horario, med.Dosagem, data.AtualizadaEm); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Evento processado: Views atualizadas para prescrição %d", data.IDPrescricao)
+	return nil
+}
+
+// HandlePrescricaoCancelada processa o evento de prescrição cancelada
+func (h *PrescricaoEventHandler) HandlePrescricaoCancelada(ctx context.Context, event Event) error {
+	data, err := DecodeData[PrescricaoCanceladaEventData](event, PrescricaoCanceladaEvent)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Processando evento: Prescrição %d cancelada (%s)", data.IDPrescricao, data.Motivo)
+
+	err = h.views.Projetar(ctx, event.ID, string(event.Type), func(views EscritaViews) error {
+		return views.Cancelar(data.IDPrescricao, data.CanceladaEm)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Evento processado: Prescrição %d removida da farmácia e marcada no prontuário", data.IDPrescricao)
+	return nil
+}
+
+// HandleDispensacao processa os eventos de dispensação (realizada, parcial ou recusada)
+func (h *PrescricaoEventHandler) HandleDispensacao(ctx context.Context, event Event) error {
+	status, ok := StatusDispensacao(event.Type)
+	if !ok {
+		return fmt.Errorf("%w: evento %s não é de dispensação", ErrEventoInvalido, event.Type)
+	}
+	data, err := DecodeData[DispensacaoEventData](event, event.Type)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Processando evento: Medicamento %d da prescrição %d com dispensação %s", data.IDMedicamento, data.IDPrescricao, status)
+
+	dispensacao := readmodel.Dispensacao{
+		ID:           data.IDDispensacao,
+		Status:       status,
+		Quantidade:   data.Quantidade,
+		Motivo:       data.Motivo,
+		Farmaceutico: data.Farmaceutico,
+		DispensadoEm: data.DispensadoEm,
+	}
+	err = h.views.Projetar(ctx, event.ID, string(event.Type), func(views EscritaViews) error {
+		return views.RegistrarDispensacao(data.IDPrescricao, data.IDMedicamento, dispensacao)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Evento processado: Dispensação %d registrada na farmácia e no prontuário", data.IDDispensacao)
+	return nil
+}
+
+// processarUmaVez executa fn na mesma transação que registra o evento em Processed_Events.
+// Um evento já registrado (reentrega do Kafka, republicação da outbox) é ignorado,
+// o que torna as projeções idempotentes.
+func processarUmaVez(ctx context.Context, db *sql.DB, eventID, eventType string, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	novo, err := registrarEventoProcessado(ctx, tx, eventID, eventType)
+	if err != nil {
+		return err
+	}
+	if !novo {
+		log.Printf("Evento %s (%s) já processado - ignorando reentrega", eventID, eventType)
+		return nil
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+	return nil
+}
+
+// registrarEventoProcessado grava o ID do evento e informa se ele ainda não havia sido processado
+func registrarEventoProcessado(ctx context.Context, tx *sql.Tx, eventID, eventType string) (bool, error) {
+	query := `
+		INSERT INTO Processed_Events (event_id, event_type)
+		VALUES ($1, $2)
+		ON CONFLICT (event_id) DO NOTHING
+	`
+	result, err := tx.ExecContext(ctx, query, eventID, eventType)
+	if err != nil {
+		return false, fmt.Errorf("erro ao registrar evento processado: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("erro ao registrar evento processado: %w", err)
+	}
+	return rows == 1, nil
+}
+
+// atualizarMedicamentoViews aplica a nova dosagem/horário de um medicamento nas duas views
+// desde é quando a alteração foi feita (início da nova vigência no histórico do prontuário)
+func atualizarMedicamentoViews(ctx context.Context, tx *sql.Tx, idPrescricao, idMedicamento int, horario, dosagem string, desde time.Time) error {
+	queryFarmacia := `
+		UPDATE View_Farmacia
+		SET horario = $1, dosagem = $2, updated_at = NOW()
+		WHERE id_prescricao = $3 AND medicamento_id = $4
+	`
+	if _, err := tx.ExecContext(ctx, queryFarmacia, horario, dosagem, idPrescricao, idMedicamento); err != nil {
+		return fmt.Errorf("erro ao atualizar View_Farmacia: %w", err)
+	}
+
+	queryProntuario := `
+		UPDATE View_Prontuario_Paciente
+		SET horario = $1, dosagem = $2, updated_at = NOW()
+		WHERE id_prescricao = $3 AND medicamento_id = $4
+	`
+	if _, err := tx.ExecContext(ctx, queryProntuario, horario, dosagem, idPrescricao, idMedicamento); err != nil {
+		return fmt.Errorf("erro ao atualizar View_Prontuario_Paciente: %w", err)
+	}
+
+	return SincronizarVigencias(ctx, tx, idPrescricao, desde)
+}
+
+// cancelarViews remove a prescrição da farmácia e marca como cancelada no prontuário
+// O prontuário mantém o histórico; a farmácia só enxerga o que ainda pode ser dispensado
+func cancelarViews(ctx context.Context, tx *sql.Tx, idPrescricao int, canceladaEm time.Time) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM View_Farmacia WHERE id_prescricao = $1`, idPrescricao); err != nil {
+		return fmt.Errorf("erro ao remover prescrição de View_Farmacia: %w", err)
+	}
+
+	queryProntuario := `
+		UPDATE View_Prontuario_Paciente
+		SET status = 'CANCELADA', updated_at = NOW()
+		WHERE id_prescricao = $1
+	`
+	if _, err := tx.ExecContext(ctx, queryProntuario, idPrescricao); err != nil {
+		return fmt.Errorf("erro ao atualizar View_Prontuario_Paciente: %w", err)
+	}
+
+	return SincronizarVigencias(ctx, tx, idPrescricao, canceladaEm)
+}
+
+// registrarDispensacaoViews grava a dispensação no histórico do prontuário e atualiza o status
+// do medicamento na farmácia
+func registrarDispensacaoViews(ctx context.Context, tx *sql.Tx, idPrescricao, idMedicamento int, d readmodel.Dispensacao) error {
+	queryHistorico := `
+		INSERT INTO View_Prontuario_Dispensacoes (
+			id_dispensacao, id_prescricao, medicamento_id, status,
+			quantidade, motivo, farmaceutico, dispensado_em
+		) VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, $8)
+		ON CONFLICT (id_dispensacao) DO NOTHING
+	`
+	_, err := tx.ExecContext(ctx, queryHistorico, d.ID, idPrescricao, idMedicamento, d.Status,
+		d.Quantidade, d.Motivo, d.Farmaceutico, d.DispensadoEm)
+	if err != nil {
+		return fmt.Errorf("erro ao gravar em View_Prontuario_Dispensacoes: %w", err)
+	}
+
+	return sincronizarStatusDispensacao(ctx, tx, idPrescricao, idMedicamento)
+}
+
+// sincronizarStatusDispensacao copia para View_Farmacia o status da dispensação mais recente
+// do item. O status vem do histórico, não do evento: dispensações aplicadas fora de ordem não
+// deixam um status antigo, e um item projetado depois das suas dispensações (CDC) já nasce certo
+func sincronizarStatusDispensacao(ctx context.Context, tx *sql.Tx, idPrescricao, idMedicamento int) error {
+	query := `
+		UPDATE View_Farmacia f
+		SET status_dispensacao = ultima.status, updated_at = NOW()
+		FROM (
+			SELECT status FROM View_Prontuario_Dispensacoes
+			WHERE id_prescricao = $1 AND medicamento_id = $2
+			ORDER BY dispensado_em DESC, id_dispensacao DESC
+			LIMIT 1
+		) ultima
+		WHERE f.id_prescricao = $1 AND f.medicamento_id = $2
+		  AND f.status_dispensacao <> ultima.status
+	`
+	if _, err := tx.ExecContext(ctx, query, idPrescricao, idMedicamento); err != nil {
+		return fmt.Errorf("erro ao atualizar dispensação em View_Farmacia: %w", err)
+	}
+	return nil
+}
+
+// atualizarViewFarmacia grava (upsert) a linha do medicamento no modelo de leitura da farmácia
+// A chave (id_prescricao, medicamento_id) garante que reprocessar o evento não duplica linhas;
+// o status de dispensação é o das dispensações já projetadas do item (PENDENTE sem nenhuma)
+func atualizarViewFarmacia(ctx context.Context, tx *sql.Tx, item ItemPrescricao) error {
+	query := `
+		INSERT INTO View_Farmacia (
+			id_prescricao, data_prescricao,
+			paciente_id, paciente_nome, paciente_data_nascimento,
+			medicamento_id, medicamento_nome, medicamento_descricao,
+			horario, dosagem
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (id_prescricao, medicamento_id) DO UPDATE SET
+			data_prescricao = EXCLUDED.data_prescricao,
+			paciente_id = EXCLUDED.paciente_id,
+			paciente_nome = EXCLUDED.paciente_nome,
+			paciente_data_nascimento = EXCLUDED.paciente_data_nascimento,
+			medicamento_nome = EXCLUDED.medicamento_nome,
+			medicamento_descricao = EXCLUDED.medicamento_descricao,
+			horario = EXCLUDED.horario,
+			dosagem = EXCLUDED.dosagem,
+			updated_at = NOW()
+	`
+
+	paciente, medicamento := item.Paciente, item.Medicamento
+	_, err := tx.ExecContext(ctx, query,
+		item.IDPrescricao, item.DataPrescricao,
+		paciente.ID, paciente.Nome, paciente.DataNascimento,
+		medicamento.ID, medicamento.Nome, medicamento.Descricao,
+		medicamento.Horario, medicamento.Dosagem,
+	)
+
+	if err != nil {
+		return fmt.Errorf("erro ao gravar em View_Farmacia: %w", err)
+	}
+
+	if err := sincronizarStatusDispensacao(ctx, tx, item.IDPrescricao, medicamento.ID); err != nil {
+		return err
+	}
+
+	log.Printf("View Farmácia atualizada para prescrição %d", item.IDPrescricao)
+	return nil
+}
+
+// atualizarViewProntuario grava (upsert) a linha do medicamento no modelo de leitura do prontuário
+// e abre a vigência do item no histórico (a partir da data da prescrição)
+func atualizarViewProntuario(ctx context.Context, tx *sql.Tx, item ItemPrescricao) error {
+	query := `
+		INSERT INTO View_Prontuario_Paciente (
+			id_prescricao, data_prescricao,
+			paciente_id, paciente_nome, paciente_data_nascimento, paciente_endereco,
+			medico_id, medico_nome, medico_especialidade, medico_crm,
+			medicamento_id, medicamento_nome, medicamento_descricao,
+			horario, dosagem
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		ON CONFLICT (id_prescricao, medicamento_id) DO UPDATE SET
+			data_prescricao = EXCLUDED.data_prescricao,
+			paciente_id = EXCLUDED.paciente_id,
+			paciente_nome = EXCLUDED.paciente_nome,
+			paciente_data_nascimento = EXCLUDED.paciente_data_nascimento,
+			paciente_endereco = EXCLUDED.paciente_endereco,
+			medico_id = EXCLUDED.medico_id,
+			medico_nome = EXCLUDED.medico_nome,
+			medico_especialidade = EXCLUDED.medico_especialidade,
+			medico_crm = EXCLUDED.medico_crm,
+			medicamento_nome = EXCLUDED.medicamento_nome,
+			medicamento_descricao = EXCLUDED.medicamento_descricao,
+			horario = EXCLUDED.horario,
+			dosagem = EXCLUDED.dosagem,
+			updated_at = NOW()
+	`
+
+	medico, paciente, medicamento := item.Medico, item.Paciente, item.Medicamento
+	_, err := tx.ExecContext(ctx, query,
+		item.IDPrescricao, item.DataPrescricao,
+		paciente.ID, paciente.Nome, paciente.DataNascimento, paciente.Endereco,
+		medico.ID, medico.Nome, medico.Especialidade, medico.CRM,
+		medicamento.ID, medicamento.Nome, medicamento.Descricao,
+		medicamento.Horario, medicamento.Dosagem,
+	)
+
+	if err != nil {
+		return fmt.Errorf("erro ao gravar em View_Prontuario_Paciente: %w", err)
+	}
+
+	if err := SincronizarVigencias(ctx, tx, item.IDPrescricao, item.DataPrescricao); err != nil {
+		return err
+	}
+
+	log.Printf("View Prontuário atualizada para prescrição %d", item.IDPrescricao)
+	return nil
+}
+
+// Funções auxiliares para buscar dados no modelo de escrita (usadas também pelo CDCEventHandler)
+func buscarMedico(ctx context.Context, db *sql.DB, id int) (readmodel.Medico, error) {
+	var medico readmodel.Medico
+	query := `SELECT id, nome, especialidade, crm FROM Medicos WHERE id = $1`
+	err := db.QueryRowContext(ctx, query, id).Scan(&medico.ID, &medico.Nome, &medico.Especialidade, &medico.CRM)
+	return medico, err
+}
+
+func buscarPaciente(ctx context.Context, db *sql.DB, id int) (readmodel.Paciente, error) {
+	var paciente readmodel.Paciente
+	query := `SELECT id, nome, data_nascimento, endereco FROM Pacientes WHERE id = $1`
+	err := db.QueryRowContext(ctx, query, id).Scan(&paciente.ID, &paciente.Nome, &paciente.DataNascimento, &paciente.Endereco)
+	return paciente, err
+}
+
+func buscarMedicamento(ctx context.Context, db *sql.DB, id int) (readmodel.Medicamento, error) {
+	var medicamento readmodel.Medicamento
+	query := `SELECT id, nome, descricao FROM Medicamentos WHERE id = $1`
+	err := db.QueryRowContext(ctx, query, id).Scan(&medicamento.ID, &medicamento.Nome, &medicamento.Descricao)
+	return medicamento, err
+}
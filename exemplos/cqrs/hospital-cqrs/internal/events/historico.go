@@ -0,0 +1,72 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// =========================================
+// HISTÓRICO DO PRONTUÁRIO (VALIDADE)
+// =========================================
+// View_Prontuario_Paciente guarda só o estado atual de cada medicamento prescrito.
+// View_Prontuario_Historico guarda os intervalos [valido_de, valido_ate) de cada combinação
+// de status, horário e dosagem, para consultar o prontuário como era em uma data.
+// As projeções gravam a view e depois chamam SincronizarVigencias, que compara o intervalo
+// vigente de cada item com a linha atual: igual, nada muda (reentregas são inofensivas);
+// diferente, o intervalo é fechado e outro é aberto no instante da mudança.
+
+// SincronizarVigencias alinha o histórico da prescrição com View_Prontuario_Paciente
+// desde é o instante da mudança no modelo de escrita (não o da projeção), para que um
+// replay produza o mesmo histórico. idPrescricao 0 sincroniza todas (rebuild/reparo).
+// O primeiro intervalo de um item começa na data da prescrição; os seguintes nunca começam
+// antes do fim do anterior, mesmo que desde venha atrasado.
+func SincronizarVigencias(ctx context.Context, tx *sql.Tx, idPrescricao int, desde time.Time) error {
+	// Itens que saíram da view (removidos do modelo de escrita) não têm histórico a manter
+	remover := `
+		DELETE FROM View_Prontuario_Historico h
+		WHERE ($1 = 0 OR h.id_prescricao = $1)
+		  AND NOT EXISTS (
+			SELECT 1 FROM View_Prontuario_Paciente v
+			WHERE v.id_prescricao = h.id_prescricao AND v.medicamento_id = h.medicamento_id)
+	`
+	if _, err := tx.ExecContext(ctx, remover, idPrescricao); err != nil {
+		return fmt.Errorf("erro ao limpar histórico do prontuário: %w", err)
+	}
+
+	fechar := `
+		UPDATE View_Prontuario_Historico h
+		SET valido_ate = GREATEST($2, h.valido_de)
+		FROM View_Prontuario_Paciente v
+		WHERE ($1 = 0 OR h.id_prescricao = $1)
+		  AND h.valido_ate IS NULL
+		  AND v.id_prescricao = h.id_prescricao AND v.medicamento_id = h.medicamento_id
+		  AND ROW(v.status, v.horario, v.dosagem) IS DISTINCT FROM ROW(h.status, h.horario, h.dosagem)
+	`
+	if _, err := tx.ExecContext(ctx, fechar, idPrescricao, desde); err != nil {
+		return fmt.Errorf("erro ao fechar vigências do prontuário: %w", err)
+	}
+
+	abrir := `
+		INSERT INTO View_Prontuario_Historico (
+			id_prescricao, medicamento_id, paciente_id, status, horario, dosagem, valido_de
+		)
+		SELECT v.id_prescricao, v.medicamento_id, v.paciente_id, v.status, v.horario, v.dosagem,
+			COALESCE(anterior.fim, v.data_prescricao)
+		FROM View_Prontuario_Paciente v
+		LEFT JOIN LATERAL (
+			SELECT MAX(h.valido_ate) AS fim FROM View_Prontuario_Historico h
+			WHERE h.id_prescricao = v.id_prescricao AND h.medicamento_id = v.medicamento_id
+		) anterior ON TRUE
+		WHERE ($1 = 0 OR v.id_prescricao = $1)
+		  AND NOT EXISTS (
+			SELECT 1 FROM View_Prontuario_Historico h
+			WHERE h.id_prescricao = v.id_prescricao AND h.medicamento_id = v.medicamento_id
+			  AND h.valido_ate IS NULL)
+	`
+	if _, err := tx.ExecContext(ctx, abrir, idPrescricao); err != nil {
+		return fmt.Errorf("erro ao abrir vigências do prontuário: %w", err)
+	}
+	return nil
+}
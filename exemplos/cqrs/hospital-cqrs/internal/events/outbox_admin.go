@@ -0,0 +1,147 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrEventoOutboxNaoEncontrado indica que o id não existe na outbox
+var ErrEventoOutboxNaoEncontrado = errors.New("evento da outbox não encontrado")
+
+// ErrEventoOutboxNaoMorto indica tentativa de requeue de evento que não está em DEAD
+var ErrEventoOutboxNaoMorto = errors.New("evento da outbox não está em DEAD")
+
+// OutboxAdmin expõe operações administrativas sobre a outbox (inspeção e requeue de eventos DEAD)
+type OutboxAdmin struct {
+	db *sql.DB
+}
+
+// NewOutboxAdmin cria o serviço administrativo da outbox
+func NewOutboxAdmin(db *sql.DB) *OutboxAdmin {
+	return &OutboxAdmin{db: db}
+}
+
+// EventoOutboxDetalhe é a visão administrativa de um evento da outbox
+type EventoOutboxDetalhe struct {
+	OutboxEvent
+	// EventosBloqueados conta os eventos do mesmo agregado que aguardam este ser publicado
+	EventosBloqueados int `json:"eventos_bloqueados"`
+}
+
+const colunasOutbox = `
+	id, aggregate_type, aggregate_id, event_type, payload,
+	event_id, schema_version, correlation_id, status,
+	created_at, processed_at, published_at, error_message, retry_count,
+	next_attempt_at, dead_at, locked_by, locked_until
+`
+
+func scanOutboxEvent(scanner interface{ Scan(...interface{}) error }) (OutboxEvent, error) {
+	var e OutboxEvent
+	err := scanner.Scan(
+		&e.ID, &e.AggregateType, &e.AggregateID, &e.EventType, &e.Payload,
+		&e.EventID, &e.SchemaVersion, &e.CorrelationID, &e.Status,
+		&e.CreatedAt, &e.ProcessedAt, &e.PublishedAt, &e.ErrorMessage, &e.RetryCount,
+		&e.NextAttemptAt, &e.DeadAt, &e.LockedBy, &e.LockedUntil,
+	)
+	return e, err
+}
+
+// ListarMortos retorna os eventos em DEAD, mais recentes primeiro
+func (a *OutboxAdmin) ListarMortos(ctx context.Context, limite int) ([]OutboxEvent, error) {
+	query := `SELECT ` + colunasOutbox + `
+		FROM Outbox_Events
+		WHERE status = 'DEAD'
+		ORDER BY dead_at DESC, id DESC
+		LIMIT $1
+	`
+
+	rows, err := a.db.QueryContext(ctx, query, limite)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar eventos DEAD: %w", err)
+	}
+	defer rows.Close()
+
+	eventos := []OutboxEvent{}
+	for rows.Next() {
+		e, err := scanOutboxEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler evento da outbox: %w", err)
+		}
+		eventos = append(eventos, e)
+	}
+
+	return eventos, rows.Err()
+}
+
+// BuscarEvento retorna um evento da outbox com o número de eventos que ele segura no agregado
+func (a *OutboxAdmin) BuscarEvento(ctx context.Context, id int64) (*EventoOutboxDetalhe, error) {
+	query := `SELECT ` + colunasOutbox + ` FROM Outbox_Events WHERE id = $1`
+
+	e, err := scanOutboxEvent(a.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrEventoOutboxNaoEncontrado
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar evento %d: %w", id, err)
+	}
+
+	detalhe := &EventoOutboxDetalhe{OutboxEvent: e}
+	if e.Status != OutboxStatusProcessed {
+		queryBloqueados := `
+			SELECT COUNT(*) FROM Outbox_Events
+			WHERE aggregate_type = $1 AND aggregate_id = $2
+			  AND status = 'PENDING' AND id > $3
+		`
+		err := a.db.QueryRowContext(ctx, queryBloqueados, e.AggregateType, e.AggregateID, e.ID).
+			Scan(&detalhe.EventosBloqueados)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao contar eventos bloqueados: %w", err)
+		}
+	}
+
+	return detalhe, nil
+}
+
+// Reenfileirar devolve um evento DEAD para PENDING com as tentativas zeradas
+// O relay é notificado na mesma transação para publicá-lo imediatamente
+func (a *OutboxAdmin) Reenfileirar(ctx context.Context, id int64) (*OutboxEvent, error) {
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE Outbox_Events
+		SET status = 'PENDING', retry_count = 0, next_attempt_at = NOW(),
+		    dead_at = NULL, locked_by = NULL, locked_until = NULL
+		WHERE id = $1 AND status = 'DEAD'
+		RETURNING ` + colunasOutbox
+
+	e, err := scanOutboxEvent(tx.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		var existe bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM Outbox_Events WHERE id = $1)`, id).Scan(&existe); err != nil {
+			return nil, fmt.Errorf("erro ao buscar evento %d: %w", id, err)
+		}
+		if !existe {
+			return nil, ErrEventoOutboxNaoEncontrado
+		}
+		return nil, ErrEventoOutboxNaoMorto
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao reenfileirar evento %d: %w", id, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_notify($1, $2)", OutboxNotifyChannel, e.AggregateID); err != nil {
+		return nil, fmt.Errorf("erro ao notificar relay da outbox: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+
+	return &e, nil
+}
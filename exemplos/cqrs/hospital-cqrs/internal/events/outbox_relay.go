@@ -0,0 +1,462 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	"hospital-cqrs/pkg/kafka"
+
+	"github.com/lib/pq"
+)
+
+// OutboxEvent representa um evento armazenado na tabela Outbox
+type OutboxEvent struct {
+	ID            int64           `json:"id"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	EventType     string          `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	EventID       string          `json:"event_id"`
+	SchemaVersion int             `json:"schema_version"`
+	CorrelationID *string         `json:"correlation_id,omitempty"`
+	Status        string          `json:"status"`
+	CreatedAt     time.Time       `json:"created_at"`
+	ProcessedAt   *time.Time      `json:"processed_at,omitempty"`
+	PublishedAt   *time.Time      `json:"published_at,omitempty"`
+	ErrorMessage  *string         `json:"error_message,omitempty"`
+	RetryCount    int             `json:"retry_count"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	DeadAt        *time.Time      `json:"dead_at,omitempty"`
+	LockedBy      *string         `json:"locked_by,omitempty"`
+	LockedUntil   *time.Time      `json:"locked_until,omitempty"`
+
+	// idade do evento no momento da reivindicação (calculada no banco, imune a diferença de fuso/relógio)
+	idadeNaReivindicacao time.Duration
+	reivindicadoEm       time.Time
+}
+
+// Status de um evento na outbox
+const (
+	OutboxStatusPending   = "PENDING"
+	OutboxStatusProcessed = "PROCESSED"
+	OutboxStatusDead      = "DEAD"
+)
+
+// OutboxNotifyChannel é o canal Postgres notificado pelas transações de comando que gravam na outbox
+const OutboxNotifyChannel = "outbox_events"
+
+// OutboxRelay é responsável por ler eventos da outbox e publicá-los no Kafka
+// As views são atualizadas pelo event handler, que consome os eventos publicados.
+// Várias instâncias podem rodar em paralelo: cada uma reivindica lotes com lease
+// (locked_by/locked_until) e nunca publica o mesmo evento que outra instância
+type OutboxRelay struct {
+	db             *sql.DB
+	producer       *kafka.Producer
+	instanceID     string
+	pollInterval   time.Duration
+	leaseDuration  time.Duration
+	publishTimeout time.Duration
+	batchSize      int
+	maxRetries     int
+	backoffBase    time.Duration
+	backoffMax     time.Duration
+	router         *TopicRouter
+	listener       *pq.Listener
+	metrics        *RelayMetrics
+}
+
+// NewOutboxRelay cria um novo relay de outbox
+// instanceID identifica a réplica nos leases; use um valor único por processo.
+// router decide o tópico de cada evento; nil publica tudo no tópico padrão do producer
+func NewOutboxRelay(db *sql.DB, producer *kafka.Producer, router *TopicRouter, instanceID string) *OutboxRelay {
+	if router == nil {
+		router = NewTopicRouter(producer.GetTopic())
+	}
+
+	return &OutboxRelay{
+		db:             db,
+		producer:       producer,
+		instanceID:     instanceID,
+		pollInterval:   2 * time.Second,  // Verifica outbox a cada 2 segundos
+		leaseDuration:  30 * time.Second, // Lease expira se a instância morrer no meio do lote
+		publishTimeout: 10 * time.Second, // Cada publicação cabe no lease renovado antes dela
+		batchSize:      20,               // Reivindica até 20 agregados por vez
+		maxRetries:     5,                // Máximo 5 tentativas por evento; depois vai para DEAD
+		backoffBase:    2 * time.Second,  // Espera antes da 2ª tentativa; dobra a cada falha
+		backoffMax:     5 * time.Minute,  // Teto do backoff
+		router:         router,
+		metrics:        &RelayMetrics{},
+	}
+}
+
+// EscutarNotificacoes faz o relay acordar via LISTEN assim que um comando grava na outbox
+// O polling continua como fallback (intervalo maior), cobrindo notificações perdidas em reconexões
+func (r *OutboxRelay) EscutarNotificacoes(databaseURL string, fallbackInterval time.Duration) error {
+	listener := pq.NewListener(databaseURL, 1*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Listener da outbox: %v", err)
+		}
+	})
+
+	if err := listener.Listen(OutboxNotifyChannel); err != nil {
+		listener.Close()
+		return fmt.Errorf("erro ao escutar canal %s: %w", OutboxNotifyChannel, err)
+	}
+
+	r.listener = listener
+	r.pollInterval = fallbackInterval
+	return nil
+}
+
+// Metrics retorna as métricas de atraso de publicação do relay
+func (r *OutboxRelay) Metrics() *RelayMetrics {
+	return r.metrics
+}
+
+// Start inicia o relay em loop contínuo
+// Com listener configurado, drena a outbox a cada NOTIFY; o ticker vira apenas fallback
+func (r *OutboxRelay) Start(ctx context.Context) error {
+	log.Println("Outbox Relay iniciado - monitorando eventos pendentes...")
+
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	var notificacoes <-chan *pq.Notification
+	if r.listener != nil {
+		defer r.listener.Close()
+		notificacoes = r.listener.NotificationChannel()
+	}
+
+	// Drenar o que ficou pendente enquanto o relay estava parado
+	r.drenar(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Outbox Relay encerrando...")
+			return ctx.Err()
+		case <-notificacoes:
+			// nil indica reconexão do listener: notificações podem ter sido perdidas, drenar do mesmo jeito
+			r.drenar(ctx)
+		case <-ticker.C:
+			r.drenar(ctx)
+		}
+	}
+}
+
+// drenar processa lotes até a outbox esvaziar (ou um lote ter falhas, que aguardam o próximo ciclo)
+func (r *OutboxRelay) drenar(ctx context.Context) {
+	for ctx.Err() == nil {
+		processados, falhas, err := r.processarEventosPendentes(ctx)
+		if err != nil {
+			log.Printf("Erro ao processar eventos pendentes: %v", err)
+			return
+		}
+		if processados == 0 || falhas > 0 {
+			return
+		}
+	}
+}
+
+// processarEventosPendentes reivindica e processa um lote de eventos
+// Retorna quantos eventos foram reivindicados e quantos falharam
+func (r *OutboxRelay) processarEventosPendentes(ctx context.Context) (int, int, error) {
+	eventos, err := r.reivindicarEventos(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("erro ao reivindicar eventos pendentes: %w", err)
+	}
+
+	if len(eventos) == 0 {
+		return 0, 0, nil // Nenhum evento pendente
+	}
+
+	log.Printf("[%s] Processando %d evento(s) da Outbox...", r.instanceID, len(eventos))
+
+	// Se um evento falhar, os seguintes do mesmo agregado não podem passar na frente
+	agregadosComFalha := make(map[string]bool)
+	falhas := 0
+
+	for _, evento := range eventos {
+		agregado := evento.AggregateType + "/" + evento.AggregateID
+		if agregadosComFalha[agregado] {
+			if err := r.liberarLease(ctx, evento.ID); err != nil {
+				log.Printf("Erro ao liberar lease do evento %d: %v", evento.ID, err)
+			}
+			continue
+		}
+
+		err := r.processarEvento(ctx, evento)
+		if errors.Is(err, errLeasePerdido) {
+			// Outra instância assumiu o agregado: nada foi publicado, não é falha do evento
+			log.Printf("[%s] Lease do evento %d perdido; agregado %s fica com a outra instância", r.instanceID, evento.ID, agregado)
+			agregadosComFalha[agregado] = true
+			continue
+		}
+		if err != nil {
+			log.Printf("Erro ao processar evento %d: %v", evento.ID, err)
+			agregadosComFalha[agregado] = true
+			falhas++
+
+			// Marcar erro na outbox
+			if err := r.marcarErro(ctx, evento.ID, err.Error()); err != nil {
+				log.Printf("Erro ao marcar falha do evento %d: %v", evento.ID, err)
+			}
+		}
+	}
+
+	return len(eventos), falhas, nil
+}
+
+// reivindicarEventos marca um lote de eventos pendentes com o lease desta instância
+//
+// A unidade de reivindicação é o agregado: a query trava (FOR UPDATE SKIP LOCKED) só o evento
+// mais antigo não processado de cada agregado e então faz lease dos pendentes daquele agregado.
+// Assim duas instâncias nunca dividem o mesmo agregado e a ordem por agregado é preservada;
+// agregados diferentes são distribuídos entre as réplicas. Um evento em backoff ou DEAD
+// segura os eventos seguintes do mesmo agregado até ser publicado ou reenfileirado.
+func (r *OutboxRelay) reivindicarEventos(ctx context.Context) ([]OutboxEvent, error) {
+	query := `
+		WITH cabecas AS (
+			SELECT e.aggregate_type, e.aggregate_id
+			FROM Outbox_Events e
+			WHERE e.status = 'PENDING'
+			  AND e.next_attempt_at <= NOW()
+			  AND (e.locked_until IS NULL OR e.locked_until < NOW())
+			  AND NOT EXISTS (
+			      SELECT 1 FROM Outbox_Events anterior
+			      WHERE anterior.aggregate_type = e.aggregate_type
+			        AND anterior.aggregate_id = e.aggregate_id
+			        AND anterior.status <> 'PROCESSED'
+			        AND anterior.id < e.id
+			  )
+			ORDER BY e.id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE Outbox_Events o
+		SET locked_by = $2,
+		    locked_until = NOW() + $3 * INTERVAL '1 millisecond'
+		FROM cabecas c
+		WHERE o.aggregate_type = c.aggregate_type
+		  AND o.aggregate_id = c.aggregate_id
+		  AND o.status = 'PENDING'
+		RETURNING o.id, o.aggregate_type, o.aggregate_id, o.event_type, o.payload,
+		          o.event_id, o.schema_version, o.correlation_id, o.status,
+		          o.created_at, o.processed_at, o.published_at, o.error_message, o.retry_count,
+		          o.next_attempt_at, o.dead_at, o.locked_by, o.locked_until,
+		          EXTRACT(EPOCH FROM (NOW() - o.created_at)) * 1000
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, r.batchSize, r.instanceID, r.leaseDuration.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reivindicadoEm := time.Now()
+
+	var eventos []OutboxEvent
+	for rows.Next() {
+		var e OutboxEvent
+		var idadeMs float64
+		err := rows.Scan(
+			&e.ID, &e.AggregateType, &e.AggregateID, &e.EventType, &e.Payload,
+			&e.EventID, &e.SchemaVersion, &e.CorrelationID, &e.Status,
+			&e.CreatedAt, &e.ProcessedAt, &e.PublishedAt, &e.ErrorMessage, &e.RetryCount,
+			&e.NextAttemptAt, &e.DeadAt, &e.LockedBy, &e.LockedUntil, &idadeMs,
+		)
+		if err != nil {
+			return nil, err
+		}
+		e.idadeNaReivindicacao = time.Duration(idadeMs * float64(time.Millisecond))
+		e.reivindicadoEm = reivindicadoEm
+		eventos = append(eventos, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING não garante ordem: publicar na ordem de inserção
+	sort.Slice(eventos, func(i, j int) bool { return eventos[i].ID < eventos[j].ID })
+
+	return eventos, nil
+}
+
+// errLeasePerdido indica que o lease do evento expirou ou passou para outra instância
+// antes da publicação; o evento não foi publicado por esta instância
+var errLeasePerdido = errors.New("lease do evento perdido")
+
+// processarEvento processa um único evento da outbox
+// O lease é renovado (e conferido) antes de publicar, e a publicação tem timeout menor que o
+// lease: um lote longo não deixa o lease vencer com o evento ainda em publicação
+func (r *OutboxRelay) processarEvento(ctx context.Context, evento OutboxEvent) error {
+	log.Printf("Processando evento Outbox: ID=%d Tipo=%s Agregado=%s/%s",
+		evento.ID, evento.EventType, evento.AggregateType, evento.AggregateID)
+
+	// 1. Renovar o lease; se outra instância já o assumiu, não publicar
+	if err := r.renovarLease(ctx, evento.ID); err != nil {
+		return err
+	}
+
+	// 2. Publicar evento no Kafka (tópico pela regra de roteamento, metadados nos headers)
+	eventKey := fmt.Sprintf("%s-%s", evento.AggregateType, evento.AggregateID)
+	topic := r.router.Topico(evento)
+	publishCtx, cancel := context.WithTimeout(ctx, r.publishTimeout)
+	defer cancel()
+	if err := r.producer.PublishMessage(publishCtx, topic, eventKey, evento.Payload, headersKafka(evento)); err != nil {
+		return fmt.Errorf("erro ao publicar no Kafka: %w", err)
+	}
+
+	publishedAt := time.Now()
+	atraso := evento.idadeNaReivindicacao + publishedAt.Sub(evento.reivindicadoEm)
+	r.metrics.registrar(atraso)
+	log.Printf("Evento %d publicado no Kafka (topic: %s, key: %s, atraso: %s)",
+		evento.ID, topic, eventKey, atraso.Round(time.Millisecond))
+
+	// 3. Marcar como processado na outbox (e liberar o lease)
+	if err := r.marcarProcessado(ctx, evento.ID, publishedAt); err != nil {
+		return fmt.Errorf("erro ao marcar evento como processado: %w", err)
+	}
+
+	return nil
+}
+
+// renovarLease estende o lease do evento se ele ainda for desta instância e não tiver vencido
+func (r *OutboxRelay) renovarLease(ctx context.Context, eventoID int64) error {
+	query := `
+		UPDATE Outbox_Events
+		SET locked_until = NOW() + $3 * INTERVAL '1 millisecond'
+		WHERE id = $1 AND locked_by = $2 AND locked_until > NOW() AND status = 'PENDING'
+	`
+	result, err := r.db.ExecContext(ctx, query, eventoID, r.instanceID, r.leaseDuration.Milliseconds())
+	if err != nil {
+		return fmt.Errorf("erro ao renovar lease: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return errLeasePerdido
+	}
+	return nil
+}
+
+// headersKafka monta os headers que permitem filtrar e rastrear sem abrir o payload
+func headersKafka(evento OutboxEvent) map[string]string {
+	headers := map[string]string{
+		"event_id":       evento.EventID,
+		"event_type":     evento.EventType,
+		"aggregate_type": evento.AggregateType,
+		"aggregate_id":   evento.AggregateID,
+		"schema_version": strconv.Itoa(evento.SchemaVersion),
+	}
+	if evento.CorrelationID != nil {
+		headers["correlation_id"] = *evento.CorrelationID
+	}
+	return headers
+}
+
+// marcarProcessado marca evento como processado
+// Só grava se o lease ainda for desta instância: se expirou, outra réplica assumiu o evento
+func (r *OutboxRelay) marcarProcessado(ctx context.Context, eventoID int64, publishedAt time.Time) error {
+	query := `
+		UPDATE Outbox_Events
+		SET status = 'PROCESSED', processed_at = $1, published_at = $2, error_message = NULL,
+		    locked_by = NULL, locked_until = NULL
+		WHERE id = $3 AND locked_by = $4
+	`
+	result, err := r.db.ExecContext(ctx, query, time.Now(), publishedAt, eventoID, r.instanceID)
+	if err != nil {
+		return err
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		log.Printf("AVISO: lease do evento %d expirou antes da confirmação; outra instância pode republicá-lo", eventoID)
+		return nil
+	}
+
+	log.Printf("Evento %d marcado como processado", eventoID)
+	return nil
+}
+
+// marcarErro registra a falha e agenda a próxima tentativa com backoff exponencial
+// (base * 2^tentativas, limitado a backoffMax). Esgotadas as tentativas, o evento vai para DEAD
+func (r *OutboxRelay) marcarErro(ctx context.Context, eventoID int64, errorMsg string) error {
+	query := `
+		UPDATE Outbox_Events
+		SET retry_count = retry_count + 1,
+		    error_message = $1,
+		    status = CASE WHEN retry_count + 1 >= $4 THEN 'DEAD' ELSE 'PENDING' END,
+		    dead_at = CASE WHEN retry_count + 1 >= $4 THEN NOW() ELSE NULL END,
+		    next_attempt_at = NOW() + LEAST($5 * POWER(2, retry_count), $6) * INTERVAL '1 millisecond',
+		    locked_by = NULL, locked_until = NULL
+		WHERE id = $2 AND locked_by = $3
+		RETURNING status, retry_count, next_attempt_at
+	`
+
+	var status string
+	var tentativas int
+	var proximaTentativa time.Time
+	err := r.db.QueryRowContext(ctx, query, errorMsg, eventoID, r.instanceID, r.maxRetries,
+		r.backoffBase.Milliseconds(), r.backoffMax.Milliseconds()).Scan(&status, &tentativas, &proximaTentativa)
+	if err == sql.ErrNoRows {
+		// Lease expirou: outra instância já assumiu o evento
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if status == OutboxStatusDead {
+		log.Printf("☠️  Evento %d movido para DEAD após %d tentativa(s); requeue via /admin/outbox/%d/requeue",
+			eventoID, tentativas, eventoID)
+	} else {
+		log.Printf("Evento %d falhou (tentativa %d/%d); próxima tentativa em %s",
+			eventoID, tentativas, r.maxRetries, proximaTentativa.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// liberarLease devolve um evento reivindicado sem processá-lo
+func (r *OutboxRelay) liberarLease(ctx context.Context, eventoID int64) error {
+	query := `
+		UPDATE Outbox_Events
+		SET locked_by = NULL, locked_until = NULL
+		WHERE id = $1 AND locked_by = $2
+	`
+	_, err := r.db.ExecContext(ctx, query, eventoID, r.instanceID)
+	return err
+}
+
+// LimparEventosProcessados remove eventos antigos já processados (housekeeping)
+// Eventos DEAD nunca são removidos aqui: ficam até alguém reenfileirá-los
+func (r *OutboxRelay) LimparEventosProcessados(ctx context.Context, retentionDays int) error {
+	query := `
+		DELETE FROM Outbox_Events
+		WHERE status = 'PROCESSED'
+		  AND processed_at < NOW() - INTERVAL '1 day' * $1
+	`
+	result, err := r.db.ExecContext(ctx, query, retentionDays)
+	if err != nil {
+		return err
+	}
+
+	rowsDeleted, _ := result.RowsAffected()
+	if rowsDeleted > 0 {
+		log.Printf("🧹 Limpeza: %d evento(s) antigo(s) removido(s) da Outbox", rowsDeleted)
+	}
+
+	return nil
+}
+
+// GetPendingCount retorna quantidade de eventos pendentes
+func (r *OutboxRelay) GetPendingCount(ctx context.Context) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM Outbox_Events WHERE status = 'PENDING'`
+	err := r.db.QueryRowContext(ctx, query).Scan(&count)
+	return count, err
+}
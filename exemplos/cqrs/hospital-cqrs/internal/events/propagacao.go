@@ -0,0 +1,44 @@
+package events
+
+import (
+	"fmt"
+	"os"
+)
+
+// =========================================
+// ESTRATÉGIA DE PROPAGAÇÃO
+// =========================================
+// Os eventos chegam às projeções de quatro formas, escolhidas por EVENT_PROPAGATION:
+//   - direto: o command service publica no Kafka logo após o commit (um evento pode se
+//     perder se o Kafka falhar entre o commit e a publicação)
+//   - outbox: o evento é gravado em Outbox_Events na transação do comando e o outbox-relay
+//     o publica no Kafka
+//   - cdc: o comando só grava as tabelas; o Debezium lê o WAL e o event handler projeta
+//     as mudanças de linha
+//   - eventstore: a prescrição é event-sourced; os eventos são anexados a Event_Store (a
+//     fonte da verdade) e o event handler assina o store, sem Kafka
+// Nas duas primeiras o event handler consome os mesmos eventos de domínio do tópico
+// prescricoes; no CDC consome os tópicos do Debezium.
+
+// Estratégias aceitas em EVENT_PROPAGATION
+const (
+	PropagacaoDireta     = "direto"
+	PropagacaoOutbox     = "outbox"
+	PropagacaoCDC        = "cdc"
+	PropagacaoEventStore = "eventstore"
+)
+
+// Propagacao retorna a estratégia configurada em EVENT_PROPAGATION (padrão direto)
+// Command service e event handler precisam usar o mesmo valor
+func Propagacao() (string, error) {
+	estrategia := os.Getenv("EVENT_PROPAGATION")
+	switch estrategia {
+	case "", PropagacaoDireta:
+		return PropagacaoDireta, nil
+	case PropagacaoOutbox, PropagacaoCDC, PropagacaoEventStore:
+		return estrategia, nil
+	default:
+		return "", fmt.Errorf("EVENT_PROPAGATION inválido: %q (use %s, %s, %s ou %s)",
+			estrategia, PropagacaoDireta, PropagacaoOutbox, PropagacaoCDC, PropagacaoEventStore)
+	}
+}
@@ -0,0 +1,57 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// RelayMetrics acumula o atraso entre a gravação do evento na outbox e a publicação no Kafka
+// É o principal indicador de latência das projeções nesta variante
+type RelayMetrics struct {
+	mu         sync.Mutex
+	publicados int64
+	ultimo     time.Duration
+	maximo     time.Duration
+	total      time.Duration
+}
+
+// PublishDelaySnapshot é uma leitura consistente das métricas do relay
+type PublishDelaySnapshot struct {
+	Publicados int64   `json:"publicados"`
+	UltimoMs   float64 `json:"ultimo_ms"`
+	MedioMs    float64 `json:"medio_ms"`
+	MaximoMs   float64 `json:"maximo_ms"`
+}
+
+// registrar contabiliza o atraso de um evento publicado
+func (m *RelayMetrics) registrar(atraso time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.publicados++
+	m.ultimo = atraso
+	m.total += atraso
+	if atraso > m.maximo {
+		m.maximo = atraso
+	}
+}
+
+// Snapshot retorna os valores acumulados desde o início do processo
+func (m *RelayMetrics) Snapshot() PublishDelaySnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := PublishDelaySnapshot{
+		Publicados: m.publicados,
+		UltimoMs:   milissegundos(m.ultimo),
+		MaximoMs:   milissegundos(m.maximo),
+	}
+	if m.publicados > 0 {
+		snapshot.MedioMs = milissegundos(m.total / time.Duration(m.publicados))
+	}
+	return snapshot
+}
+
+func milissegundos(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,111 @@
+package events
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// RotasTopicoPadrao é o roteamento usado sem OUTBOX_TOPIC_ROUTES: tudo de prescrição no
+// tópico prescricoes
+const RotasTopicoPadrao = "prescricao:*=prescricoes"
+
+// TopicRule mapeia agregado/tipo de evento para um tópico Kafka
+// "*" (ou vazio) casa com qualquer valor; prefixos como "prescricao.*" também são aceitos
+type TopicRule struct {
+	AggregateType string
+	EventType     string
+	Topic         string
+}
+
+// TopicRouter escolhe o tópico de cada evento da outbox; a primeira regra que casa vence
+type TopicRouter struct {
+	rules        []TopicRule
+	defaultTopic string
+}
+
+// NewTopicRouter cria um roteador com as regras na ordem de prioridade
+func NewTopicRouter(defaultTopic string, rules ...TopicRule) *TopicRouter {
+	return &TopicRouter{rules: rules, defaultTopic: defaultTopic}
+}
+
+// TopicRouterDoAmbiente monta o roteador de OUTBOX_TOPIC_ROUTES (padrão RotasTopicoPadrao)
+// O outbox-relay publica por ele e o event handler consome todos os seus tópicos (Topicos):
+// as duas pontas leem a mesma configuração, então nenhuma rota publica onde ninguém consome
+func TopicRouterDoAmbiente(defaultTopic string) (*TopicRouter, error) {
+	spec := os.Getenv("OUTBOX_TOPIC_ROUTES")
+	if spec == "" {
+		spec = RotasTopicoPadrao
+	}
+
+	rules, err := ParseTopicRules(spec)
+	if err != nil {
+		return nil, err
+	}
+	return NewTopicRouter(defaultTopic, rules...), nil
+}
+
+// ParseTopicRules lê regras no formato "aggregate_type:event_type=topico", separadas por vírgula
+// Ex: "prescricao:prescricao.cancelada=prescricoes-canceladas,prescricao:*=prescricoes"
+func ParseTopicRules(spec string) ([]TopicRule, error) {
+	var rules []TopicRule
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		origem, topico, ok := strings.Cut(item, "=")
+		if !ok || strings.TrimSpace(topico) == "" {
+			return nil, fmt.Errorf("regra de tópico inválida %q: esperado aggregate_type:event_type=topico", item)
+		}
+
+		aggregateType, eventType, _ := strings.Cut(origem, ":")
+		rules = append(rules, TopicRule{
+			AggregateType: strings.TrimSpace(aggregateType),
+			EventType:     strings.TrimSpace(eventType),
+			Topic:         strings.TrimSpace(topico),
+		})
+	}
+	return rules, nil
+}
+
+// Topico retorna o tópico de destino do evento
+func (r *TopicRouter) Topico(evento OutboxEvent) string {
+	for _, rule := range r.rules {
+		if casa(rule.AggregateType, evento.AggregateType) && casa(rule.EventType, evento.EventType) {
+			return rule.Topic
+		}
+	}
+	return r.defaultTopic
+}
+
+// Regras retorna as regras na ordem de prioridade
+func (r *TopicRouter) Regras() []TopicRule {
+	return r.rules
+}
+
+// Topicos retorna todos os tópicos para os quais o roteador pode publicar (padrão incluso)
+func (r *TopicRouter) Topicos() []string {
+	topicos := []string{r.defaultTopic}
+	vistos := map[string]bool{r.defaultTopic: true}
+	for _, rule := range r.rules {
+		if !vistos[rule.Topic] {
+			vistos[rule.Topic] = true
+			topicos = append(topicos, rule.Topic)
+		}
+	}
+	return topicos
+}
+
+// casa compara um padrão de regra com o valor ("*" e vazio casam tudo; "x.*" casa por prefixo)
+func casa(padrao, valor string) bool {
+	switch {
+	case padrao == "" || padrao == "*":
+		return true
+	case strings.HasSuffix(padrao, "*"):
+		return strings.HasPrefix(valor, strings.TrimSuffix(padrao, "*"))
+	default:
+		return padrao == valor
+	}
+}
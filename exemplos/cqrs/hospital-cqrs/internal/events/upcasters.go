@@ -0,0 +1,148 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// =========================================
+// DECODIFICAÇÃO E UPCASTING DE EVENTOS
+// =========================================
+
+// ErrEventoInvalido indica um evento malformado (envelope, versão ou payload)
+// Consumidores devem tratar como mensagem venenosa em vez de tentar de novo
+var ErrEventoInvalido = errors.New("evento inválido")
+
+// Payload é implementado pelos dados tipados de cada evento
+type Payload interface {
+	Validate() error
+}
+
+// Upcaster converte o payload de uma versão de schema para a versão seguinte
+type Upcaster func(data json.RawMessage) (json.RawMessage, error)
+
+type upcasterKey struct {
+	eventType EventType
+	version   int
+}
+
+// versoesAtuais é a versão de schema que os handlers entendem para cada tipo
+var versoesAtuais = map[EventType]int{
+	PrescricaoCriadaEvent:           PrescricaoCriadaSchemaVersion,
+	PrescricaoAtualizadaEvent:       PrescricaoAtualizadaSchemaVersion,
+	PrescricaoCanceladaEvent:        PrescricaoCanceladaSchemaVersion,
+	PrescricaoAlertasIgnoradosEvent: AlertasIgnoradosSchemaVersion,
+	MedicoAtualizadoEvent:           MedicoAtualizadoSchemaVersion,
+	PacienteAtualizadoEvent:         PacienteAtualizadoSchemaVersion,
+	MedicamentoAtualizadoEvent:      MedicamentoAtualizadoSchemaVersion,
+	DispensacaoRealizadaEvent:       DispensacaoSchemaVersion,
+	DispensacaoParcialEvent:         DispensacaoSchemaVersion,
+	DispensacaoRecusadaEvent:        DispensacaoSchemaVersion,
+}
+
+// upcasters indexados pela versão de origem: {tipo, N} converte de N para N+1
+var upcasters = map[upcasterKey]Upcaster{
+	{PrescricaoCriadaEvent, 1}: upcastPrescricaoCriadaV1,
+}
+
+// DecodeEvent lê o envelope, valida e aplica a cadeia de upcasters até a versão atual
+// Eventos antigos no Kafka ou na outbox continuam legíveis depois de mudanças de schema
+func DecodeEvent(raw []byte) (Event, error) {
+	var event Event
+	if err := json.Unmarshal(raw, &event); err != nil {
+		return Event{}, fmt.Errorf("%w: erro ao deserializar envelope: %v", ErrEventoInvalido, err)
+	}
+
+	if event.ID == "" || event.Type == "" {
+		return Event{}, fmt.Errorf("%w: envelope sem id ou tipo", ErrEventoInvalido)
+	}
+	if len(event.Data) == 0 || string(event.Data) == "null" {
+		return Event{}, fmt.Errorf("%w: evento %s sem dados", ErrEventoInvalido, event.ID)
+	}
+
+	// Eventos anteriores ao versionamento não tinham schema_version
+	if event.SchemaVersion == 0 {
+		event.SchemaVersion = 1
+	}
+
+	return upcast(event)
+}
+
+// DecodeData converte o payload do evento para o tipo esperado e valida os campos obrigatórios
+func DecodeData[T Payload](event Event, expected EventType) (T, error) {
+	var data T
+	if event.Type != expected {
+		return data, fmt.Errorf("%w: tipo %s, esperado %s", ErrEventoInvalido, event.Type, expected)
+	}
+
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		return data, fmt.Errorf("%w: dados do evento %s: %v", ErrEventoInvalido, event.ID, err)
+	}
+
+	if err := data.Validate(); err != nil {
+		return data, fmt.Errorf("%w: evento %s: %v", ErrEventoInvalido, event.ID, err)
+	}
+
+	return data, nil
+}
+
+// upcast aplica os upcasters registrados até alcançar a versão atual do tipo
+func upcast(event Event) (Event, error) {
+	atual, ok := versoesAtuais[event.Type]
+	if !ok {
+		// Tipo desconhecido: quem decide ignorar ou falhar é o dispatcher
+		return event, nil
+	}
+
+	if event.SchemaVersion > atual {
+		return Event{}, fmt.Errorf("%w: evento %s na versão %d, handler suporta até %d",
+			ErrEventoInvalido, event.ID, event.SchemaVersion, atual)
+	}
+
+	for event.SchemaVersion < atual {
+		upcaster, ok := upcasters[upcasterKey{event.Type, event.SchemaVersion}]
+		if !ok {
+			return Event{}, fmt.Errorf("%w: sem upcaster para %s v%d", ErrEventoInvalido, event.Type, event.SchemaVersion)
+		}
+
+		data, err := upcaster(event.Data)
+		if err != nil {
+			return Event{}, fmt.Errorf("%w: upcast de %s v%d: %v", ErrEventoInvalido, event.Type, event.SchemaVersion, err)
+		}
+
+		event.Data = data
+		event.SchemaVersion++
+	}
+
+	return event, nil
+}
+
+// upcastPrescricaoCriadaV1 converte prescricao.criada v1 → v2
+// v2 passou a levar o id da linha de Prescricao_Medicamentos em cada medicamento;
+// eventos v1 não têm essa informação e recebem 0 (não informado)
+func upcastPrescricaoCriadaV1(data json.RawMessage) (json.RawMessage, error) {
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, err
+	}
+
+	var medicamentos []map[string]json.RawMessage
+	if err := json.Unmarshal(payload["medicamentos"], &medicamentos); err != nil {
+		return nil, fmt.Errorf("medicamentos: %w", err)
+	}
+
+	for _, med := range medicamentos {
+		if _, ok := med["id_prescricao_medicamento"]; !ok {
+			med["id_prescricao_medicamento"] = json.RawMessage("0")
+		}
+	}
+
+	medicamentosRaw, err := json.Marshal(medicamentos)
+	if err != nil {
+		return nil, err
+	}
+	payload["medicamentos"] = medicamentosRaw
+
+	return json.Marshal(payload)
+}
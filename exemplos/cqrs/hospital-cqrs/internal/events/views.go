@@ -0,0 +1,258 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"hospital-cqrs/internal/domain"
+	"hospital-cqrs/internal/readmodel"
+)
+
+// =========================================
+// MODELO DE LEITURA (DESTINO DAS PROJEÇÕES)
+// =========================================
+// Os handlers descrevem o que muda nas views; o modelo de leitura decide como gravar.
+// No Postgres cada item é uma linha de View_Farmacia/View_Prontuario_Paciente; no Redis,
+// um item dentro do documento da prescrição.
+
+// ModeloLeitura aplica as alterações de um evento nas views uma única vez
+// Reentregas do mesmo evento são ignoradas e fn nunca é aplicada pela metade
+type ModeloLeitura interface {
+	Projetar(ctx context.Context, eventID, eventType string, fn func(EscritaViews) error) error
+}
+
+// EscritaViews são as alterações que um evento pode fazer nas views da farmácia e do prontuário
+type EscritaViews interface {
+	// GravarItem inclui ou substitui um medicamento da prescrição nas duas views
+	GravarItem(item ItemPrescricao) error
+	// AtualizarItem aplica nova dosagem/horário a um medicamento já projetado
+	// desde é o instante da alteração, início da nova vigência no histórico do prontuário
+	AtualizarItem(idPrescricao, idMedicamento int, horario, dosagem string, desde time.Time) error
+	// Cancelar tira a prescrição da farmácia e a marca como cancelada no prontuário
+	Cancelar(idPrescricao int, canceladaEm time.Time) error
+	// RegistrarDispensacao inclui a dispensação no histórico do prontuário e atualiza o
+	// status de dispensação do medicamento na farmácia
+	RegistrarDispensacao(idPrescricao, idMedicamento int, d readmodel.Dispensacao) error
+	// AtualizarMedico, AtualizarPaciente e AtualizarMedicamento propagam um cadastro
+	// e retornam quantas linhas (ou documentos) mudaram
+	AtualizarMedico(data MedicoAtualizadoEventData) (int64, error)
+	AtualizarPaciente(data PacienteAtualizadoEventData) (int64, error)
+	AtualizarMedicamento(data MedicamentoAtualizadoEventData) (int64, error)
+}
+
+// ItemPrescricao é um medicamento prescrito com os cadastros copiados para as views
+type ItemPrescricao struct {
+	IDPrescricao   int
+	DataPrescricao time.Time
+	Medico         readmodel.Medico
+	Paciente       readmodel.Paciente
+	Medicamento    readmodel.Medicamento
+}
+
+// =========================================
+// POSTGRES
+// =========================================
+
+type modeloLeituraPostgres struct {
+	db *sql.DB
+}
+
+// NewModeloLeituraPostgres grava as views nas tabelas View_* (registro de eventos em Processed_Events)
+func NewModeloLeituraPostgres(db *sql.DB) ModeloLeitura {
+	return &modeloLeituraPostgres{db: db}
+}
+
+func (m *modeloLeituraPostgres) Projetar(ctx context.Context, eventID, eventType string, fn func(EscritaViews) error) error {
+	return processarUmaVez(ctx, m.db, eventID, eventType, func(tx *sql.Tx) error {
+		return fn(&escritaPostgres{ctx: ctx, tx: tx})
+	})
+}
+
+type escritaPostgres struct {
+	ctx context.Context
+	tx  *sql.Tx
+}
+
+func (e *escritaPostgres) GravarItem(item ItemPrescricao) error {
+	if err := atualizarViewFarmacia(e.ctx, e.tx, item); err != nil {
+		return err
+	}
+	return atualizarViewProntuario(e.ctx, e.tx, item)
+}
+
+func (e *escritaPostgres) AtualizarItem(idPrescricao, idMedicamento int, horario, dosagem string, desde time.Time) error {
+	return atualizarMedicamentoViews(e.ctx, e.tx, idPrescricao, idMedicamento, horario, dosagem, desde)
+}
+
+func (e *escritaPostgres) Cancelar(idPrescricao int, canceladaEm time.Time) error {
+	return cancelarViews(e.ctx, e.tx, idPrescricao, canceladaEm)
+}
+
+func (e *escritaPostgres) RegistrarDispensacao(idPrescricao, idMedicamento int, d readmodel.Dispensacao) error {
+	return registrarDispensacaoViews(e.ctx, e.tx, idPrescricao, idMedicamento, d)
+}
+
+func (e *escritaPostgres) AtualizarMedico(data MedicoAtualizadoEventData) (int64, error) {
+	return projetarCadastroMedico(e.ctx, e.tx, data)
+}
+
+func (e *escritaPostgres) AtualizarPaciente(data PacienteAtualizadoEventData) (int64, error) {
+	return projetarCadastroPaciente(e.ctx, e.tx, data)
+}
+
+func (e *escritaPostgres) AtualizarMedicamento(data MedicamentoAtualizadoEventData) (int64, error) {
+	return projetarCadastroMedicamento(e.ctx, e.tx, data)
+}
+
+// =========================================
+// REDIS (DOCUMENTOS)
+// =========================================
+
+type modeloLeituraRedis struct {
+	redis *readmodel.Redis
+}
+
+// NewModeloLeituraRedis grava as views como documentos de prescrição no Redis
+func NewModeloLeituraRedis(r *readmodel.Redis) ModeloLeitura {
+	return &modeloLeituraRedis{redis: r}
+}
+
+func (m *modeloLeituraRedis) Projetar(ctx context.Context, eventID, eventType string, fn func(EscritaViews) error) error {
+	return m.redis.Projetar(ctx, eventID, eventType, func(docs *readmodel.Documentos) error {
+		return fn(&escritaRedis{docs: docs})
+	})
+}
+
+type escritaRedis struct {
+	docs *readmodel.Documentos
+}
+
+// GravarItem cria o documento na primeira vez; depois só atualiza cadastros e o item,
+// preservando o status (um evento reentregue não reativa uma prescrição cancelada)
+func (e *escritaRedis) GravarItem(item ItemPrescricao) error {
+	p, ok, err := e.docs.Prescricao(item.IDPrescricao)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		p = &readmodel.Prescricao{
+			IDPrescricao: item.IDPrescricao,
+			Status:       domain.StatusPrescricaoAtiva,
+			Medicamentos: []readmodel.Medicamento{},
+		}
+	}
+
+	p.DataPrescricao = item.DataPrescricao
+	p.Medico = item.Medico
+	p.Paciente = item.Paciente
+	p.SalvarMedicamento(item.Medicamento)
+	p.RegistrarVigencias(item.DataPrescricao)
+	e.docs.Salvar(p)
+	return nil
+}
+
+func (e *escritaRedis) AtualizarItem(idPrescricao, idMedicamento int, horario, dosagem string, desde time.Time) error {
+	p, ok, err := e.docs.Prescricao(idPrescricao)
+	if err != nil || !ok {
+		return err
+	}
+	med, ok := p.Medicamento(idMedicamento)
+	if !ok {
+		return nil
+	}
+
+	med.Horario = horario
+	med.Dosagem = dosagem
+	p.RegistrarVigencias(desde)
+	e.docs.Salvar(p)
+	return nil
+}
+
+func (e *escritaRedis) Cancelar(idPrescricao int, canceladaEm time.Time) error {
+	p, ok, err := e.docs.Prescricao(idPrescricao)
+	if err != nil || !ok {
+		return err
+	}
+
+	p.Status = domain.StatusPrescricaoCancelada
+	p.RegistrarVigencias(canceladaEm)
+	e.docs.Salvar(p)
+	return nil
+}
+
+func (e *escritaRedis) RegistrarDispensacao(idPrescricao, idMedicamento int, d readmodel.Dispensacao) error {
+	p, ok, err := e.docs.Prescricao(idPrescricao)
+	if err != nil || !ok {
+		return err
+	}
+	med, ok := p.Medicamento(idMedicamento)
+	if !ok {
+		return nil
+	}
+
+	if med.RegistrarDispensacao(d) {
+		e.docs.Salvar(p)
+	}
+	return nil
+}
+
+func (e *escritaRedis) AtualizarMedico(data MedicoAtualizadoEventData) (int64, error) {
+	novo := readmodel.Medico{ID: data.IDMedico, Nome: data.Nome, Especialidade: data.Especialidade, CRM: data.CRM}
+	return e.atualizarCadastro(cadastroMedico, readmodel.IndiceMedico, data.IDMedico, data.AtualizadoEm, func(p *readmodel.Prescricao) bool {
+		if p.Medico == novo {
+			return false
+		}
+		p.Medico = novo
+		return true
+	})
+}
+
+func (e *escritaRedis) AtualizarPaciente(data PacienteAtualizadoEventData) (int64, error) {
+	return e.atualizarCadastro(cadastroPaciente, readmodel.IndicePaciente, data.IDPaciente, data.AtualizadoEm, func(p *readmodel.Prescricao) bool {
+		atual := p.Paciente
+		if atual.Nome == data.Nome && atual.DataNascimento.Equal(data.DataNascimento) && atual.Endereco == data.Endereco {
+			return false
+		}
+		p.Paciente = readmodel.Paciente{ID: data.IDPaciente, Nome: data.Nome, DataNascimento: data.DataNascimento, Endereco: data.Endereco}
+		return true
+	})
+}
+
+func (e *escritaRedis) AtualizarMedicamento(data MedicamentoAtualizadoEventData) (int64, error) {
+	return e.atualizarCadastro(cadastroMedicamento, readmodel.IndiceMedicamento, data.IDMedicamento, data.AtualizadoEm, func(p *readmodel.Prescricao) bool {
+		med, ok := p.Medicamento(data.IDMedicamento)
+		if !ok || (med.Nome == data.Nome && med.Descricao == data.Descricao) {
+			return false
+		}
+		med.Nome = data.Nome
+		med.Descricao = data.Descricao
+		return true
+	})
+}
+
+// atualizarCadastro aplica alterar em todos os documentos que referenciam o cadastro
+// e retorna quantos mudaram; versões antigas do cadastro são ignoradas
+func (e *escritaRedis) atualizarCadastro(tipo, indice string, id int, atualizadoEm time.Time, alterar func(*readmodel.Prescricao) bool) (int64, error) {
+	if novo, err := e.docs.VersaoCadastroNova(tipo, id, atualizadoEm); err != nil || !novo {
+		return 0, err
+	}
+
+	ids, err := e.docs.IDsPorIndice(indice, id)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, idPrescricao := range ids {
+		p, ok, err := e.docs.Prescricao(idPrescricao)
+		if err != nil {
+			return 0, err
+		}
+		if ok && alterar(p) {
+			e.docs.Salvar(p)
+			total++
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,212 @@
+package eventstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// =========================================
+// ASSINATURAS (PROJEÇÕES)
+// =========================================
+// Uma assinatura lê o event store em ordem de posição global e entrega cada evento ao
+// handler. O checkpoint (última posição processada) fica em Projection_Checkpoints, então
+// ao reiniciar ela continua de onde parou. A entrega é at-least-once: depois de uma queda
+// os eventos desde o último checkpoint são reentregues (os handlers são idempotentes).
+//
+// Um evento que falha é reentregue até maxTentativas vezes; depois é estacionado em
+// Event_Store_Dead_Letters (o papel da DLQ nos consumidores Kafka) e a assinatura segue.
+// Apagar o checkpoint (make replay-views) reprojeta tudo, inclusive os estacionados.
+
+// Assinatura acompanha o event store para uma projeção
+// Só uma instância por nome deve rodar: o checkpoint não é disputado entre processos
+type Assinatura struct {
+	store         *Store
+	db            *sql.DB
+	nome          string
+	pollInterval  time.Duration
+	retryDelay    time.Duration
+	maxTentativas int
+	lote          int
+	listener      *pq.Listener
+
+	// evento que está falhando e quantas tentativas já teve
+	posicaoComFalha int64
+	tentativas      int
+}
+
+// NewAssinatura cria a assinatura nome sobre o store
+func NewAssinatura(db *sql.DB, store *Store, nome string) *Assinatura {
+	return &Assinatura{
+		store:         store,
+		db:            db,
+		nome:          nome,
+		pollInterval:  2 * time.Second, // Verifica o store a cada 2 segundos
+		retryDelay:    2 * time.Second, // Espera antes de reentregar um evento que falhou
+		maxTentativas: 5,               // Depois de 5 falhas o evento é estacionado
+		lote:          100,             // Lê até 100 eventos por vez
+	}
+}
+
+// EscutarNotificacoes faz a assinatura acordar via LISTEN a cada append
+// O polling continua como fallback (intervalo maior), cobrindo notificações perdidas em reconexões
+func (a *Assinatura) EscutarNotificacoes(databaseURL string, fallbackInterval time.Duration) error {
+	listener := pq.NewListener(databaseURL, 1*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Listener do event store: %v", err)
+		}
+	})
+
+	if err := listener.Listen(NotifyChannel); err != nil {
+		listener.Close()
+		return fmt.Errorf("erro ao escutar canal %s: %w", NotifyChannel, err)
+	}
+
+	a.listener = listener
+	a.pollInterval = fallbackInterval
+	return nil
+}
+
+// Executar entrega os eventos ao handler, em ordem, até o ctx ser cancelado
+// Um evento cujo handler falha é reentregue após retryDelay; os seguintes esperam por ele até
+// as tentativas acabarem e ele ser estacionado
+func (a *Assinatura) Executar(ctx context.Context, handler func(ctx context.Context, evento EventoArmazenado) error) error {
+	posicao, err := a.checkpoint(ctx)
+	if err != nil {
+		return err
+	}
+	log.Printf("Assinatura %s iniciada na posição %d", a.nome, posicao)
+
+	ticker := time.NewTicker(a.pollInterval)
+	defer ticker.Stop()
+
+	var notificacoes <-chan *pq.Notification
+	if a.listener != nil {
+		defer a.listener.Close()
+		notificacoes = a.listener.NotificationChannel()
+	}
+
+	for {
+		var falhou bool
+		posicao, falhou = a.drenar(ctx, posicao, handler)
+		if falhou && ctx.Err() == nil {
+			continue // drenar já esperou retryDelay: reentregar sem aguardar o próximo aviso
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Printf("Assinatura %s encerrando na posição %d", a.nome, posicao)
+			return ctx.Err()
+		case <-notificacoes:
+			// nil indica reconexão do listener: eventos podem ter chegado sem aviso, drenar do mesmo jeito
+		case <-ticker.C:
+		}
+	}
+}
+
+// drenar processa lotes até alcançar o fim do store (ou um evento falhar)
+// Retorna a posição do último evento processado e se parou por falha
+func (a *Assinatura) drenar(ctx context.Context, posicao int64, handler func(ctx context.Context, evento EventoArmazenado) error) (int64, bool) {
+	for ctx.Err() == nil {
+		eventos, err := a.store.LerAPartirDe(ctx, posicao, a.lote)
+		if err != nil {
+			log.Printf("Erro na assinatura %s: %v", a.nome, err)
+			esperar(ctx, a.retryDelay)
+			return posicao, true
+		}
+		if len(eventos) == 0 {
+			return posicao, false
+		}
+
+		inicio := posicao
+		for _, evento := range eventos {
+			if err := handler(ctx, evento); err != nil {
+				if ctx.Err() != nil || !a.desistir(ctx, evento, err) {
+					a.salvarCheckpoint(ctx, inicio, posicao)
+					esperar(ctx, a.retryDelay)
+					return posicao, true
+				}
+			}
+			posicao = evento.Posicao
+		}
+		a.salvarCheckpoint(ctx, inicio, posicao)
+	}
+	return posicao, false
+}
+
+// desistir conta a falha do evento e, esgotadas as tentativas, estaciona-o em
+// Event_Store_Dead_Letters; retorna true se a assinatura pode seguir para o próximo evento
+func (a *Assinatura) desistir(ctx context.Context, evento EventoArmazenado, causa error) bool {
+	if evento.Posicao != a.posicaoComFalha {
+		a.posicaoComFalha, a.tentativas = evento.Posicao, 0
+	}
+	a.tentativas++
+	if a.tentativas < a.maxTentativas {
+		log.Printf("Erro ao projetar evento %d (%s v%d) (tentativa %d/%d, nova tentativa em %s): %v",
+			evento.Posicao, evento.StreamID, evento.Versao, a.tentativas, a.maxTentativas, a.retryDelay, causa)
+		return false
+	}
+
+	query := `
+		INSERT INTO Event_Store_Dead_Letters (assinatura, posicao, stream_id, versao, erro, tentativas)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (assinatura, posicao) DO UPDATE
+		SET erro = EXCLUDED.erro, tentativas = EXCLUDED.tentativas, estacionado_em = NOW()
+	`
+	_, err := a.db.ExecContext(ctx, query, a.nome, evento.Posicao, evento.StreamID, evento.Versao, causa.Error(), a.tentativas)
+	if err != nil {
+		// Sem o registro o evento não pode ser pulado: tenta estacionar de novo na próxima falha
+		log.Printf("Erro ao estacionar evento %d da assinatura %s: %v", evento.Posicao, a.nome, err)
+		return false
+	}
+
+	log.Printf("☠️  Evento %d (%s v%d) estacionado em Event_Store_Dead_Letters após %d tentativa(s); assinatura %s segue: %v",
+		evento.Posicao, evento.StreamID, evento.Versao, a.tentativas, a.nome, causa)
+	a.posicaoComFalha, a.tentativas = 0, 0
+	return true
+}
+
+// checkpoint lê a última posição processada (0 para uma assinatura nova)
+func (a *Assinatura) checkpoint(ctx context.Context) (int64, error) {
+	var posicao int64
+	query := `SELECT posicao FROM Projection_Checkpoints WHERE assinatura = $1`
+	err := a.db.QueryRowContext(ctx, query, a.nome).Scan(&posicao)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("erro ao ler checkpoint da assinatura %s: %w", a.nome, err)
+	}
+	return posicao, nil
+}
+
+// salvarCheckpoint grava a posição se ela avançou desde inicio
+// Uma falha aqui só causa reentrega após reinício, então é apenas registrada no log
+func (a *Assinatura) salvarCheckpoint(ctx context.Context, inicio, posicao int64) {
+	if posicao == inicio {
+		return
+	}
+	query := `
+		INSERT INTO Projection_Checkpoints (assinatura, posicao, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (assinatura) DO UPDATE SET posicao = EXCLUDED.posicao, updated_at = NOW()
+	`
+	if _, err := a.db.ExecContext(ctx, query, a.nome, posicao); err != nil {
+		log.Printf("Erro ao salvar checkpoint da assinatura %s: %v", a.nome, err)
+	}
+}
+
+// esperar dorme pelo intervalo ou até o ctx ser cancelado
+func esperar(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
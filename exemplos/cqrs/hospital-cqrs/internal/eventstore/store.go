@@ -0,0 +1,224 @@
+package eventstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/lib/pq"
+
+	"hospital-cqrs/internal/events"
+)
+
+// =========================================
+// EVENT STORE (APPEND-ONLY)
+// =========================================
+// Cada agregado tem um stream (prescricao-<id>, medico-<id>...) com versões 1, 2, 3...
+// Event_Store só recebe INSERT (um trigger bloqueia UPDATE/DELETE). A posição global
+// ordena os eventos de todos os streams para as assinaturas das projeções.
+
+// QualquerVersao desliga a verificação de concorrência no Anexar
+// Usada pelos cadastros, que continuam com estado em tabela e só registram seus eventos no store
+const QualquerVersao = -1
+
+// NotifyChannel é o canal do pg_notify disparado a cada append (acorda as assinaturas)
+const NotifyChannel = "event_store"
+
+// lockAppend é a chave do advisory lock que serializa os appends
+// Sem ele, uma transação que pegou uma posição menor poderia confirmar depois de uma com
+// posição maior, e a assinatura que já passou daquela posição perderia o evento
+const lockAppend = 7_240_001
+
+// ErrConflitoConcorrencia indica que o stream mudou desde que o agregado foi carregado
+var ErrConflitoConcorrencia = errors.New("conflito de concorrência: o stream foi alterado por outro comando")
+
+// EventoArmazenado é um evento lido do store com sua posição no stream e na ordem global
+type EventoArmazenado struct {
+	Posicao  int64
+	StreamID string
+	Versao   int
+	Evento   events.Event // decodificado e com upcast (só em Carregar)
+	Payload  []byte       // envelope como gravado (o que os handlers de projeção recebem)
+}
+
+// Snapshot é o estado serializado de um agregado em uma versão do stream
+type Snapshot struct {
+	StreamID      string
+	Versao        int
+	SchemaVersion int // formato do estado; snapshots de outro formato são ignorados
+	Estado        json.RawMessage
+}
+
+// Store lê streams, snapshots e a ordem global do event store
+// As escritas (Anexar, SalvarSnapshot) recebem a transação do comando
+type Store struct {
+	db                *sql.DB
+	intervaloSnapshot int
+}
+
+// NewStore cria o event store; intervaloSnapshot é a cada quantos eventos o agregado é fotografado
+func NewStore(db *sql.DB, intervaloSnapshot int) *Store {
+	return &Store{db: db, intervaloSnapshot: intervaloSnapshot}
+}
+
+// IntervaloSnapshot retorna SNAPSHOT_INTERVAL (padrão 10 eventos; 0 desliga os snapshots)
+func IntervaloSnapshot() (int, error) {
+	valor := os.Getenv("SNAPSHOT_INTERVAL")
+	if valor == "" {
+		return 10, nil
+	}
+	n, err := strconv.Atoi(valor)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("SNAPSHOT_INTERVAL inválido: %q", valor)
+	}
+	return n, nil
+}
+
+// DeveGerarSnapshot informa se o append de versaoAnterior até versaoNova cruzou um múltiplo do intervalo
+func (s *Store) DeveGerarSnapshot(versaoAnterior, versaoNova int) bool {
+	if s.intervaloSnapshot <= 0 {
+		return false
+	}
+	return versaoNova/s.intervaloSnapshot > versaoAnterior/s.intervaloSnapshot
+}
+
+// Anexar grava os eventos no fim do stream e retorna a nova versão
+// Com versaoEsperada >= 0, falha com ErrConflitoConcorrencia se o stream não estiver nela
+func Anexar(ctx context.Context, tx *sql.Tx, streamID string, versaoEsperada int, eventos ...events.Event) (int, error) {
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", lockAppend); err != nil {
+		return 0, fmt.Errorf("erro ao obter lock do event store: %w", err)
+	}
+
+	var versaoAtual int
+	query := `SELECT COALESCE(MAX(versao), 0) FROM Event_Store WHERE stream_id = $1`
+	if err := tx.QueryRowContext(ctx, query, streamID).Scan(&versaoAtual); err != nil {
+		return 0, fmt.Errorf("erro ao ler versão do stream %s: %w", streamID, err)
+	}
+	if versaoEsperada != QualquerVersao && versaoAtual != versaoEsperada {
+		return 0, fmt.Errorf("%w (stream %s na versão %d, esperada %d)", ErrConflitoConcorrencia, streamID, versaoAtual, versaoEsperada)
+	}
+
+	var correlationID sql.NullString
+	if id := events.CorrelationIDFromContext(ctx); id != "" {
+		correlationID = sql.NullString{String: id, Valid: true}
+	}
+
+	insert := `
+		INSERT INTO Event_Store (stream_id, versao, event_id, event_type, schema_version, payload, correlation_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	versao := versaoAtual
+	for _, evento := range eventos {
+		payload, err := json.Marshal(evento)
+		if err != nil {
+			return 0, fmt.Errorf("erro ao serializar evento %s: %w", evento.ID, err)
+		}
+
+		versao++
+		_, err = tx.ExecContext(ctx, insert, streamID, versao, evento.ID, string(evento.Type), evento.SchemaVersion, payload, correlationID)
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			// UNIQUE (stream_id, versao): outro append venceu a corrida
+			return 0, fmt.Errorf("%w (stream %s, versão %d)", ErrConflitoConcorrencia, streamID, versao)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("erro ao anexar evento ao stream %s: %w", streamID, err)
+		}
+	}
+
+	// NOTIFY é transacional: as assinaturas só acordam depois do commit
+	if _, err := tx.ExecContext(ctx, "SELECT pg_notify($1, $2)", NotifyChannel, streamID); err != nil {
+		return 0, fmt.Errorf("erro ao notificar assinaturas do event store: %w", err)
+	}
+	return versao, nil
+}
+
+// Carregar lê os eventos do stream posteriores a aposVersao, em ordem de versão
+func (s *Store) Carregar(ctx context.Context, streamID string, aposVersao int) ([]EventoArmazenado, error) {
+	query := `
+		SELECT posicao, stream_id, versao, payload
+		FROM Event_Store
+		WHERE stream_id = $1 AND versao > $2
+		ORDER BY versao
+	`
+	rows, err := s.db.QueryContext(ctx, query, streamID, aposVersao)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao carregar stream %s: %w", streamID, err)
+	}
+	defer rows.Close()
+	return lerEventos(rows, true)
+}
+
+// LerAPartirDe lê até limite eventos de todos os streams depois da posição global informada
+// O envelope não é decodificado: um evento inválido é problema do handler, não da leitura
+func (s *Store) LerAPartirDe(ctx context.Context, posicao int64, limite int) ([]EventoArmazenado, error) {
+	query := `
+		SELECT posicao, stream_id, versao, payload
+		FROM Event_Store
+		WHERE posicao > $1
+		ORDER BY posicao
+		LIMIT $2
+	`
+	rows, err := s.db.QueryContext(ctx, query, posicao, limite)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler event store a partir da posição %d: %w", posicao, err)
+	}
+	defer rows.Close()
+	return lerEventos(rows, false)
+}
+
+func lerEventos(rows *sql.Rows, decodificar bool) ([]EventoArmazenado, error) {
+	var eventos []EventoArmazenado
+	for rows.Next() {
+		var e EventoArmazenado
+		if err := rows.Scan(&e.Posicao, &e.StreamID, &e.Versao, &e.Payload); err != nil {
+			return nil, fmt.Errorf("erro ao scanear evento do store: %w", err)
+		}
+		if decodificar {
+			// DecodeEvent aplica os upcasters: o agregado sempre vê a versão atual do schema
+			evento, err := events.DecodeEvent(e.Payload)
+			if err != nil {
+				return nil, fmt.Errorf("evento %d do stream %s: %w", e.Versao, e.StreamID, err)
+			}
+			e.Evento = evento
+		}
+		eventos = append(eventos, e)
+	}
+	return eventos, rows.Err()
+}
+
+// CarregarSnapshot retorna o snapshot mais recente do stream (nil se não houver)
+func (s *Store) CarregarSnapshot(ctx context.Context, streamID string) (*Snapshot, error) {
+	snapshot := Snapshot{StreamID: streamID}
+	query := `SELECT versao, schema_version, estado FROM Snapshots WHERE stream_id = $1`
+	err := s.db.QueryRowContext(ctx, query, streamID).Scan(&snapshot.Versao, &snapshot.SchemaVersion, &snapshot.Estado)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao carregar snapshot de %s: %w", streamID, err)
+	}
+	return &snapshot, nil
+}
+
+// SalvarSnapshot grava o snapshot na transação do comando (um por stream, o mais recente vence)
+// Snapshots são só cache: apagar a tabela não perde nada, o estado volta do replay dos eventos
+func SalvarSnapshot(ctx context.Context, tx *sql.Tx, snapshot Snapshot) error {
+	query := `
+		INSERT INTO Snapshots (stream_id, versao, schema_version, estado)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (stream_id) DO UPDATE
+		SET versao = EXCLUDED.versao, schema_version = EXCLUDED.schema_version,
+			estado = EXCLUDED.estado, created_at = NOW()
+		WHERE Snapshots.versao < EXCLUDED.versao
+	`
+	_, err := tx.ExecContext(ctx, query, snapshot.StreamID, snapshot.Versao, snapshot.SchemaVersion, []byte(snapshot.Estado))
+	if err != nil {
+		return fmt.Errorf("erro ao salvar snapshot de %s: %w", snapshot.StreamID, err)
+	}
+	return nil
+}
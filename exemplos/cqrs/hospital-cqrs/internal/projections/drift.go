@@ -0,0 +1,259 @@
+package projections
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// =========================================
+// VERIFICAÇÃO DE DIVERGÊNCIAS (DRIFT)
+// =========================================
+
+// TipoDivergencia classifica a diferença entre a view e o modelo de escrita
+type TipoDivergencia string
+
+const (
+	// DivergenciaFaltando indica linha esperada que não existe na view
+	DivergenciaFaltando TipoDivergencia = "faltando"
+	// DivergenciaSobrando indica linha na view sem correspondente no modelo de escrita
+	// (ex.: prescrição cancelada que continua na farmácia)
+	DivergenciaSobrando TipoDivergencia = "sobrando"
+	// DivergenciaValores indica linha presente nos dois lados com colunas diferentes
+	DivergenciaValores TipoDivergencia = "divergente"
+)
+
+// Divergencia é uma linha da view que não bate com o modelo de escrita
+// A chave é a mesma do índice único das views: (id_prescricao, medicamento_id)
+type Divergencia struct {
+	IDPrescricao  int
+	MedicamentoID int
+	Tipo          TipoDivergencia
+	Colunas       []string // colunas com valor diferente (apenas DivergenciaValores)
+}
+
+func (d Divergencia) String() string {
+	s := fmt.Sprintf("prescrição %d / medicamento %d: %s", d.IDPrescricao, d.MedicamentoID, d.Tipo)
+	if len(d.Colunas) > 0 {
+		s += " (" + strings.Join(d.Colunas, ", ") + ")"
+	}
+	return s
+}
+
+// RelatorioDivergencias agrupa o resultado da verificação de uma view
+type RelatorioDivergencias struct {
+	View         View
+	Divergencias []Divergencia
+	Reparadas    int // linhas corrigidas (apenas quando Reparar foi usado)
+}
+
+// Contagem retorna quantas divergências de cada tipo foram encontradas
+func (r RelatorioDivergencias) Contagem() map[TipoDivergencia]int {
+	contagem := make(map[TipoDivergencia]int)
+	for _, d := range r.Divergencias {
+		contagem[d.Tipo]++
+	}
+	return contagem
+}
+
+// Verificador compara cada view com o resultado esperado a partir do modelo de escrita
+//
+// As views são eventualmente consistentes: uma prescrição recém-gravada pode ainda não ter
+// sido projetada. Prescrições alteradas dentro da margem (criação, cancelamento, mudança de
+// medicamento ou dispensação) são ignoradas para que eventos em trânsito não apareçam como divergência.
+type Verificador struct {
+	db     *sql.DB
+	margem time.Duration
+}
+
+// NewVerificador cria um verificador de divergências
+func NewVerificador(db *sql.DB, margem time.Duration) *Verificador {
+	return &Verificador{db: db, margem: margem}
+}
+
+// Verificar lista as divergências da view sem alterá-la
+func (v *Verificador) Verificar(ctx context.Context, view View) (*RelatorioDivergencias, error) {
+	def, ok := definicoes[view]
+	if !ok {
+		return nil, fmt.Errorf("view desconhecida: %s", view)
+	}
+
+	// Snapshot único: esperado e atual são lidos no mesmo instante
+	tx, err := v.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	divergencias, err := v.divergencias(ctx, tx, def)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RelatorioDivergencias{View: view, Divergencias: divergencias}, nil
+}
+
+// Reparar verifica a view e corrige apenas as linhas divergentes
+//
+// Assim como o rebuild, usa LOCK ... IN EXCLUSIVE MODE: o event handler espera o reparo
+// terminar, e o query service continua lendo a versão anterior até o COMMIT.
+func (v *Verificador) Reparar(ctx context.Context, view View) (*RelatorioDivergencias, error) {
+	def, ok := definicoes[view]
+	if !ok {
+		return nil, fmt.Errorf("view desconhecida: %s", view)
+	}
+
+	tx, err := v.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("LOCK TABLE %s IN EXCLUSIVE MODE", def.tabela)); err != nil {
+		return nil, fmt.Errorf("erro ao bloquear %s: %w", def.tabela, err)
+	}
+
+	// Recalcular com o lock: o que foi projetado entre a verificação e o reparo não é tocado
+	divergencias, err := v.divergencias(ctx, tx, def)
+	if err != nil {
+		return nil, err
+	}
+
+	relatorio := &RelatorioDivergencias{View: view, Divergencias: divergencias}
+	if len(divergencias) == 0 {
+		return relatorio, nil
+	}
+
+	prescricoes := make([]int64, len(divergencias))
+	medicamentos := make([]int64, len(divergencias))
+	for i, d := range divergencias {
+		prescricoes[i] = int64(d.IDPrescricao)
+		medicamentos[i] = int64(d.MedicamentoID)
+	}
+
+	// Remover as linhas afetadas e reinseri-las a partir do modelo de escrita;
+	// linhas "sobrando" simplesmente não voltam
+	queryRemover := fmt.Sprintf(`
+		DELETE FROM %s
+		WHERE (id_prescricao, medicamento_id) IN (SELECT * FROM UNNEST($1::int[], $2::int[]))
+	`, def.tabela)
+	if _, err := tx.ExecContext(ctx, queryRemover, pq.Array(prescricoes), pq.Array(medicamentos)); err != nil {
+		return nil, fmt.Errorf("erro ao remover linhas divergentes de %s: %w", def.tabela, err)
+	}
+
+	colunas := strings.Join(def.colunas, ", ")
+	queryReinserir := fmt.Sprintf(`
+		WITH esperado (%s) AS (%s)
+		INSERT INTO %s (%s)
+		SELECT * FROM esperado
+		WHERE (id_prescricao, medicamento_id) IN (SELECT * FROM UNNEST($1::int[], $2::int[]))
+	`, colunas, def.esperado, def.tabela, colunas)
+	result, err := tx.ExecContext(ctx, queryReinserir, pq.Array(prescricoes), pq.Array(medicamentos))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao reinserir linhas em %s: %w", def.tabela, err)
+	}
+
+	if err := def.sincronizarVigencias(ctx, tx); err != nil {
+		return nil, err
+	}
+	if err := def.repopularDispensacoes(ctx, tx, prescricoesAfetadas(divergencias)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("erro ao confirmar reparo de %s: %w", def.tabela, err)
+	}
+
+	reinseridas, _ := result.RowsAffected()
+	relatorio.Reparadas = len(divergencias)
+	log.Printf("%s reparada: %d divergência(s) corrigida(s), %d linha(s) reinserida(s)", def.tabela, len(divergencias), reinseridas)
+
+	return relatorio, nil
+}
+
+// prescricoesAfetadas lista, sem repetição, as prescrições das divergências
+func prescricoesAfetadas(divergencias []Divergencia) []int64 {
+	vistas := make(map[int]bool)
+	prescricoes := []int64{}
+	for _, d := range divergencias {
+		if !vistas[d.IDPrescricao] {
+			vistas[d.IDPrescricao] = true
+			prescricoes = append(prescricoes, int64(d.IDPrescricao))
+		}
+	}
+	return prescricoes
+}
+
+// divergencias compara a view com o SELECT esperado via FULL OUTER JOIN na chave da view
+func (v *Verificador) divergencias(ctx context.Context, tx *sql.Tx, def definicaoView) ([]Divergencia, error) {
+	rows, err := tx.QueryContext(ctx, def.queryDivergencias(), v.margem.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("erro ao comparar %s com o modelo de escrita: %w", def.tabela, err)
+	}
+	defer rows.Close()
+
+	var divergencias []Divergencia
+	for rows.Next() {
+		var d Divergencia
+		var tipo string
+		if err := rows.Scan(&d.IDPrescricao, &d.MedicamentoID, &tipo, pq.Array(&d.Colunas)); err != nil {
+			return nil, fmt.Errorf("erro ao ler divergência: %w", err)
+		}
+		d.Tipo = TipoDivergencia(tipo)
+		divergencias = append(divergencias, d)
+	}
+
+	return divergencias, rows.Err()
+}
+
+// queryDivergencias monta a comparação entre a view e o resultado esperado
+// $1 é a margem em segundos para ignorar prescrições alteradas recentemente
+func (d definicaoView) queryDivergencias() string {
+	comparacoes := make([]string, len(d.colunas))
+	esperadas := make([]string, len(d.colunas))
+	atuais := make([]string, len(d.colunas))
+	for i, c := range d.colunas {
+		comparacoes[i] = fmt.Sprintf("CASE WHEN e.%s IS DISTINCT FROM a.%s THEN '%s' END", c, c, c)
+		esperadas[i] = "e." + c
+		atuais[i] = "a." + c
+	}
+
+	return fmt.Sprintf(`
+		WITH esperado (%s) AS (%s),
+		recentes AS (
+			SELECT id FROM Prescricoes
+			WHERE created_at > NOW() - make_interval(secs => $1)
+			   OR cancelada_em > NOW() - make_interval(secs => $1)
+			UNION
+			SELECT id_prescricao FROM Prescricao_Medicamentos
+			WHERE updated_at > NOW() - make_interval(secs => $1)
+			UNION
+			SELECT id_prescricao FROM Dispensacoes
+			WHERE dispensado_em > NOW() - make_interval(secs => $1)
+		)
+		SELECT
+			COALESCE(e.id_prescricao, a.id_prescricao),
+			COALESCE(e.medicamento_id, a.medicamento_id),
+			CASE
+				WHEN a.id_prescricao IS NULL THEN 'faltando'
+				WHEN e.id_prescricao IS NULL THEN 'sobrando'
+				ELSE 'divergente'
+			END,
+			ARRAY_REMOVE(ARRAY[%s]::text[], NULL)
+		FROM esperado e
+		FULL OUTER JOIN %s a
+			ON a.id_prescricao = e.id_prescricao AND a.medicamento_id = e.medicamento_id
+		WHERE (a.id_prescricao IS NULL OR e.id_prescricao IS NULL
+			OR ROW(%s) IS DISTINCT FROM ROW(%s))
+		  AND COALESCE(e.id_prescricao, a.id_prescricao) NOT IN (SELECT id FROM recentes)
+		ORDER BY 1, 2
+	`, strings.Join(d.colunas, ", "), d.esperado,
+		strings.Join(comparacoes, ", "),
+		d.tabela,
+		strings.Join(esperadas, ", "), strings.Join(atuais, ", "))
+}
@@ -0,0 +1,220 @@
+package projections
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"hospital-cqrs/internal/events"
+)
+
+// =========================================
+// REBUILD DOS MODELOS DE LEITURA
+// =========================================
+
+// View identifica um modelo de leitura que pode ser reconstruído
+type View string
+
+const (
+	// ViewFarmacia é o modelo de leitura da farmácia
+	ViewFarmacia View = "farmacia"
+	// ViewProntuario é o modelo de leitura do prontuário do paciente
+	ViewProntuario View = "prontuario"
+)
+
+// definicaoView descreve como reconstruir uma view a partir do modelo de escrita
+// esperado é o SELECT que produz as linhas corretas da view, na ordem de colunas;
+// o rebuild insere esse resultado e o verificador de divergências compara com ele
+// vigencias indica que a view alimenta View_Prontuario_Historico (consultas com as_of) e
+// dispensacoes, que o rebuild também refaz View_Prontuario_Dispensacoes
+type definicaoView struct {
+	tabela       string
+	colunas      []string
+	esperado     string
+	vigencias    bool
+	dispensacoes bool
+}
+
+// sincronizarVigencias alinha o histórico com a view corrigida, na mesma transação
+// Não há instante de escrita para uma correção: ela entra no histórico no momento do
+// rebuild/reparo, sem reescrever os intervalos já registrados
+func (d definicaoView) sincronizarVigencias(ctx context.Context, tx *sql.Tx) error {
+	if !d.vigencias {
+		return nil
+	}
+	return events.SincronizarVigencias(ctx, tx, 0, time.Now())
+}
+
+// repopularDispensacoes refaz o histórico de dispensações do prontuário a partir de Dispensacoes
+// Com prescricoes (reparo), só as dessas prescrições; nil refaz o histórico todo (rebuild)
+func (d definicaoView) repopularDispensacoes(ctx context.Context, tx *sql.Tx, prescricoes []int64) error {
+	if !d.dispensacoes {
+		return nil
+	}
+
+	filtro := ""
+	var args []any
+	if prescricoes != nil {
+		filtro = "WHERE id_prescricao = ANY($1::int[])"
+		args = append(args, pq.Array(prescricoes))
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM View_Prontuario_Dispensacoes `+filtro, args...); err != nil {
+		return fmt.Errorf("erro ao limpar View_Prontuario_Dispensacoes: %w", err)
+	}
+	query := `
+		INSERT INTO View_Prontuario_Dispensacoes (
+			id_dispensacao, id_prescricao, medicamento_id, status,
+			quantidade, motivo, farmaceutico, dispensado_em
+		)
+		SELECT id, id_prescricao, id_medicamento, status, quantidade, motivo, farmaceutico, dispensado_em
+		FROM Dispensacoes
+	` + filtro
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("erro ao repopular View_Prontuario_Dispensacoes: %w", err)
+	}
+	return nil
+}
+
+// populate monta o INSERT ... SELECT que repopula a view
+func (d definicaoView) populate() string {
+	return fmt.Sprintf(`
+		INSERT INTO %s (%s)
+		%s
+		ON CONFLICT (id_prescricao, medicamento_id) DO NOTHING
+	`, d.tabela, strings.Join(d.colunas, ", "), d.esperado)
+}
+
+var definicoes = map[View]definicaoView{
+	ViewFarmacia: {
+		tabela: "View_Farmacia",
+		colunas: []string{
+			"id_prescricao", "data_prescricao",
+			"paciente_id", "paciente_nome", "paciente_data_nascimento",
+			"medicamento_id", "medicamento_nome", "medicamento_descricao",
+			"horario", "dosagem", "status_dispensacao",
+		},
+		// Farmácia só enxerga prescrições ativas; o status de dispensação é o da última dispensação
+		esperado: `
+			SELECT
+				p.id, p.data_prescricao,
+				pa.id, pa.nome, pa.data_nascimento,
+				m.id, m.nome, m.descricao,
+				pm.horario, pm.dosagem,
+				COALESCE((
+					SELECT d.status FROM Dispensacoes d
+					WHERE d.id_prescricao_medicamento = pm.id
+					ORDER BY d.dispensado_em DESC, d.id DESC
+					LIMIT 1
+				), 'PENDENTE')
+			FROM Prescricoes p
+			JOIN Pacientes pa ON pa.id = p.id_paciente
+			JOIN Prescricao_Medicamentos pm ON pm.id_prescricao = p.id
+			JOIN Medicamentos m ON m.id = pm.id_medicamento
+			WHERE p.status = 'ATIVA'
+			ORDER BY p.id, pm.id
+		`,
+	},
+	ViewProntuario: {
+		tabela: "View_Prontuario_Paciente",
+		colunas: []string{
+			"id_prescricao", "data_prescricao",
+			"paciente_id", "paciente_nome", "paciente_data_nascimento", "paciente_endereco",
+			"medico_id", "medico_nome", "medico_especialidade", "medico_crm", "status",
+			"medicamento_id", "medicamento_nome", "medicamento_descricao",
+			"horario", "dosagem",
+		},
+		// Prontuário mantém o histórico completo, inclusive canceladas
+		esperado: `
+			SELECT
+				p.id, p.data_prescricao,
+				pa.id, pa.nome, pa.data_nascimento, pa.endereco,
+				me.id, me.nome, me.especialidade, me.crm, p.status,
+				m.id, m.nome, m.descricao,
+				pm.horario, pm.dosagem
+			FROM Prescricoes p
+			JOIN Pacientes pa ON pa.id = p.id_paciente
+			JOIN Medicos me ON me.id = p.id_medico
+			JOIN Prescricao_Medicamentos pm ON pm.id_prescricao = p.id
+			JOIN Medicamentos m ON m.id = pm.id_medicamento
+			ORDER BY p.id, pm.id
+		`,
+		vigencias:    true,
+		dispensacoes: true,
+	},
+}
+
+// Rebuilder reconstrói os modelos de leitura direto das tabelas de escrita
+//
+// A reconstrução acontece em uma única transação por view:
+//  1. LOCK ... IN EXCLUSIVE MODE bloqueia apenas escritores (event handler / relay),
+//     leituras do query service continuam liberadas;
+//  2. as linhas atuais são removidas e a view é repopulada com INSERT ... SELECT;
+//  3. no COMMIT a nova versão aparece de uma vez para os leitores (MVCC).
+//
+// Nenhum leitor enxerga a view vazia ou pela metade, e eventos que chegarem durante
+// o rebuild ficam aguardando o lock e são aplicados por cima da versão reconstruída.
+type Rebuilder struct {
+	db *sql.DB
+}
+
+// NewRebuilder cria um novo reconstrutor de views
+func NewRebuilder(db *sql.DB) *Rebuilder {
+	return &Rebuilder{db: db}
+}
+
+// Views retorna as views conhecidas pelo rebuild
+func Views() []View {
+	return []View{ViewFarmacia, ViewProntuario}
+}
+
+// Rebuild reconstrói a view informada e retorna a quantidade de linhas geradas
+func (r *Rebuilder) Rebuild(ctx context.Context, view View) (int64, error) {
+	def, ok := definicoes[view]
+	if !ok {
+		return 0, fmt.Errorf("view desconhecida: %s", view)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("LOCK TABLE %s IN EXCLUSIVE MODE", def.tabela)); err != nil {
+		return 0, fmt.Errorf("erro ao bloquear %s: %w", def.tabela, err)
+	}
+
+	// DELETE em vez de TRUNCATE: TRUNCATE exige ACCESS EXCLUSIVE e bloquearia as leituras
+	removidas, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s", def.tabela))
+	if err != nil {
+		return 0, fmt.Errorf("erro ao limpar %s: %w", def.tabela, err)
+	}
+
+	result, err := tx.ExecContext(ctx, def.populate())
+	if err != nil {
+		return 0, fmt.Errorf("erro ao repopular %s: %w", def.tabela, err)
+	}
+
+	if err := def.sincronizarVigencias(ctx, tx); err != nil {
+		return 0, err
+	}
+	if err := def.repopularDispensacoes(ctx, tx, nil); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("erro ao confirmar rebuild de %s: %w", def.tabela, err)
+	}
+
+	antes, _ := removidas.RowsAffected()
+	depois, _ := result.RowsAffected()
+	log.Printf("%s reconstruída: %d linha(s) antes, %d linha(s) depois", def.tabela, antes, depois)
+
+	return depois, nil
+}
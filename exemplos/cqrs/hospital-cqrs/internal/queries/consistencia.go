@@ -0,0 +1,79 @@
+package queries
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrTokenInvalido indica um token de consistência malformado
+var ErrTokenInvalido = errors.New("token de consistência inválido")
+
+// Consistencia verifica se as projeções já refletem uma escrita (read-your-writes)
+// O formato do token depende da propagação (EVENT_PROPAGATION):
+//   - direto/outbox: o id do evento de domínio; a escrita está visível quando o event handler
+//     registrou esse evento no modelo de leitura (Processed_Events no Postgres, um set no Redis)
+//   - cdc: "prescricao:<id>:v<versao>" (ver domain.TokenConsistencia); a escrita está visível
+//     quando View_Versao_Prescricao alcançou essa versão
+//
+// Nos dois casos o registro acontece na mesma transação que atualiza as views
+type Consistencia struct {
+	store     ReadModelStore
+	intervalo time.Duration
+}
+
+// NewConsistencia cria o verificador de consistência
+func NewConsistencia(store ReadModelStore) *Consistencia {
+	return &Consistencia{store: store, intervalo: 50 * time.Millisecond}
+}
+
+// Aguardar espera a projeção alcançar o token até o ctx expirar
+// Retorna false (sem erro) se o prazo acabou antes
+func (c *Consistencia) Aguardar(ctx context.Context, token string) (bool, error) {
+	projetado, err := c.verificador(token)
+	if err != nil {
+		return false, err
+	}
+
+	ticker := time.NewTicker(c.intervalo)
+	defer ticker.Stop()
+
+	for {
+		processado, err := projetado(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return false, nil
+			}
+			return false, fmt.Errorf("erro ao verificar token de consistência: %w", err)
+		}
+		if processado {
+			return true, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// verificador escolhe a consulta conforme o formato do token
+func (c *Consistencia) verificador(token string) (func(ctx context.Context) (bool, error), error) {
+	if _, err := uuid.Parse(token); err == nil {
+		return func(ctx context.Context) (bool, error) {
+			return c.store.EventoProjetado(ctx, token)
+		}, nil
+	}
+
+	var idPrescricao, versao int
+	if _, err := fmt.Sscanf(token, "prescricao:%d:v%d", &idPrescricao, &versao); err != nil || versao < 1 {
+		return nil, fmt.Errorf("%w: %q", ErrTokenInvalido, token)
+	}
+	return func(ctx context.Context) (bool, error) {
+		return c.store.VersaoProjetada(ctx, idPrescricao, versao)
+	}, nil
+}
@@ -0,0 +1,332 @@
+package queries
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"hospital-cqrs/internal/domain"
+	"hospital-cqrs/internal/readmodel"
+)
+
+// =========================================
+// MODELO DE LEITURA DOCUMENTAL (REDIS)
+// =========================================
+
+// DocumentStore consulta as views guardadas como documentos de prescrição no Redis
+// Os índices do Redis delimitam o conjunto (prescrições ativas ou do paciente); filtros,
+// ordenação e cursor são aplicados em memória com a mesma semântica do QueryRepository
+type DocumentStore struct {
+	redis *readmodel.Redis
+}
+
+// NewDocumentStore cria o store documental
+func NewDocumentStore(r *readmodel.Redis) *DocumentStore {
+	return &DocumentStore{redis: r}
+}
+
+var (
+	_ ReadModelStore = (*QueryRepository)(nil)
+	_ ReadModelStore = (*DocumentStore)(nil)
+)
+
+// GetPrescricoesFarmacia retorna uma página das prescrições ativas
+func (s *DocumentStore) GetPrescricoesFarmacia(ctx context.Context, filtro FiltroPrescricoes) (*domain.PaginaPrescricoesFarmaciaDTO, error) {
+	ordenacao, err := filtro.normalizar(colunasOrdenacaoFarmacia)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := s.redis.IDsFarmacia(ctx)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := s.redis.Carregar(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar prescrições da farmácia: %w", err)
+	}
+
+	docs = filtrarDocumentos(docs, filtro, func(p *readmodel.Prescricao) bool {
+		return p.Status == domain.StatusPrescricaoAtiva && (filtro.PacienteID == 0 || p.Paciente.ID == filtro.PacienteID)
+	})
+
+	pagina, err := paginarDocumentos(docs, &filtro, ordenacao)
+	if err != nil {
+		return nil, err
+	}
+
+	prescricoes := make([]domain.PrescricaoFarmaciaDTO, len(pagina.docs))
+	for i := range pagina.docs {
+		prescricoes[i] = prescricaoFarmacia(&pagina.docs[i])
+	}
+
+	return &domain.PaginaPrescricoesFarmaciaDTO{
+		Prescricoes: prescricoes,
+		Paginacao:   pagina.paginacao(filtro),
+	}, nil
+}
+
+// GetPrescricaoFarmaciaByID retorna uma prescrição ativa para a farmácia
+func (s *DocumentStore) GetPrescricaoFarmaciaByID(ctx context.Context, idPrescricao int) (*domain.PrescricaoFarmaciaDTO, error) {
+	docs, err := s.redis.Carregar(ctx, []int{idPrescricao})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar prescrição: %w", err)
+	}
+	if len(docs) == 0 || docs[0].Status != domain.StatusPrescricaoAtiva {
+		return nil, fmt.Errorf("prescrição não encontrada")
+	}
+
+	prescricao := prescricaoFarmacia(&docs[0])
+	return &prescricao, nil
+}
+
+// GetProntuarioPaciente retorna o prontuário de um paciente com uma página de prescrições
+func (s *DocumentStore) GetProntuarioPaciente(ctx context.Context, idPaciente int, filtro FiltroPrescricoes) (*domain.ProntuarioPacienteDTO, error) {
+	ordenacao, err := filtro.normalizar(colunasOrdenacaoProntuario)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := s.redis.IDsPorIndice(ctx, readmodel.IndicePaciente, idPaciente)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := s.redis.Carregar(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar prontuário: %w", err)
+	}
+
+	// O índice pode guardar prescrições de quem era o paciente antes; vale o documento
+	docs = filtrarDocumentos(docs, FiltroPrescricoes{}, func(p *readmodel.Prescricao) bool {
+		return p.Paciente.ID == idPaciente
+	})
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("paciente não encontrado")
+	}
+
+	paciente := docs[0].Paciente
+	prontuario := &domain.ProntuarioPacienteDTO{
+		PacienteID:             paciente.ID,
+		PacienteNome:           paciente.Nome,
+		PacienteDataNascimento: paciente.DataNascimento,
+		PacienteEndereco:       paciente.Endereco,
+	}
+
+	// Com AsOf, cada documento é substituído pela versão vigente no instante
+	if filtro.AsOf != nil {
+		vigentes := make([]readmodel.Prescricao, 0, len(docs))
+		for i := range docs {
+			if p, ok := docs[i].Em(*filtro.AsOf); ok {
+				vigentes = append(vigentes, p)
+			}
+		}
+		docs = vigentes
+	}
+
+	docs = filtrarDocumentos(docs, filtro, func(*readmodel.Prescricao) bool { return true })
+	pagina, err := paginarDocumentos(docs, &filtro, ordenacao)
+	if err != nil {
+		return nil, err
+	}
+
+	prontuario.Prescricoes = make([]domain.PrescricaoProntuarioDTO, len(pagina.docs))
+	for i := range pagina.docs {
+		prontuario.Prescricoes[i] = prescricaoProntuario(&pagina.docs[i])
+	}
+
+	paginacao := pagina.paginacao(filtro)
+	prontuario.Paginacao = &paginacao
+	return prontuario, nil
+}
+
+// EventoProjetado consulta o set de eventos gravado junto com os documentos
+func (s *DocumentStore) EventoProjetado(ctx context.Context, eventID string) (bool, error) {
+	return s.redis.EventoProjetado(ctx, eventID)
+}
+
+// VersaoProjetada não se aplica aos documentos: tokens de versão só existem com
+// EVENT_PROPAGATION=cdc, que projeta nas tabelas View_* do Postgres
+func (s *DocumentStore) VersaoProjetada(context.Context, int, int) (bool, error) {
+	return false, errors.New("tokens de versão exigem READ_MODEL_BACKEND=postgres (propagação cdc)")
+}
+
+// filtrarDocumentos aplica período, médico e medicamento do filtro, além do critério da view
+func filtrarDocumentos(docs []readmodel.Prescricao, filtro FiltroPrescricoes, incluir func(*readmodel.Prescricao) bool) []readmodel.Prescricao {
+	filtrados := docs[:0]
+	for i := range docs {
+		p := &docs[i]
+		if !incluir(p) {
+			continue
+		}
+		if filtro.DataInicio != nil && p.DataPrescricao.Before(*filtro.DataInicio) {
+			continue
+		}
+		if filtro.DataFim != nil && !p.DataPrescricao.Before(*filtro.DataFim) {
+			continue
+		}
+		if filtro.MedicoID > 0 && p.Medico.ID != filtro.MedicoID {
+			continue
+		}
+		if filtro.MedicamentoID > 0 {
+			if _, ok := p.Medicamento(filtro.MedicamentoID); !ok {
+				continue
+			}
+		}
+		filtrados = append(filtrados, *p)
+	}
+	return filtrados
+}
+
+// paginaDocumentos é a página já ordenada, com o total antes da paginação
+type paginaDocumentos struct {
+	docs          []readmodel.Prescricao
+	total         int
+	proximoCursor string
+}
+
+// paginarDocumentos ordena por (chave, id) e corta a página a partir do cursor
+func paginarDocumentos(docs []readmodel.Prescricao, filtro *FiltroPrescricoes, ordenacao string) (*paginaDocumentos, error) {
+	cursor, err := filtro.decodificarCursor()
+	if err != nil {
+		return nil, err
+	}
+
+	comparar := func(a, b *readmodel.Prescricao) int {
+		var c int
+		switch ordenacao {
+		case "paciente_nome":
+			c = strings.Compare(a.Paciente.Nome, b.Paciente.Nome)
+		case "medico_nome":
+			c = strings.Compare(a.Medico.Nome, b.Medico.Nome)
+		default:
+			c = a.DataPrescricao.Compare(b.DataPrescricao)
+		}
+		if c == 0 {
+			c = a.IDPrescricao - b.IDPrescricao
+		}
+		if filtro.Direcao == "desc" {
+			c = -c
+		}
+		return c
+	}
+	sort.Slice(docs, func(i, j int) bool { return comparar(&docs[i], &docs[j]) < 0 })
+
+	pagina := &paginaDocumentos{total: len(docs)}
+
+	inicio := 0
+	if cursor != nil {
+		// O cursor vira um documento fictício com a mesma chave e id
+		ultimo := readmodel.Prescricao{IDPrescricao: cursor.ID}
+		switch ordenacao {
+		case "paciente_nome":
+			ultimo.Paciente.Nome = cursor.Chave
+		case "medico_nome":
+			ultimo.Medico.Nome = cursor.Chave
+		default:
+			t, err := time.Parse(time.RFC3339Nano, cursor.Chave)
+			if err != nil {
+				return nil, fmt.Errorf("%w: cursor malformado", ErrFiltroInvalido)
+			}
+			ultimo.DataPrescricao = t
+		}
+		inicio = sort.Search(len(docs), func(i int) bool { return comparar(&docs[i], &ultimo) > 0 })
+	}
+
+	fim := min(inicio+filtro.Limite, len(docs))
+	pagina.docs = docs[inicio:fim]
+	if fim < len(docs) {
+		ultimo := &docs[fim-1]
+		chave := ultimo.DataPrescricao.Format(time.RFC3339Nano)
+		switch ordenacao {
+		case "paciente_nome":
+			chave = ultimo.Paciente.Nome
+		case "medico_nome":
+			chave = ultimo.Medico.Nome
+		}
+		pagina.proximoCursor = filtro.codificarCursor(chave, ultimo.IDPrescricao)
+	}
+
+	return pagina, nil
+}
+
+func (p *paginaDocumentos) paginacao(filtro FiltroPrescricoes) domain.PaginacaoDTO {
+	return domain.PaginacaoDTO{
+		Total:         p.total,
+		Limite:        filtro.Limite,
+		Ordenacao:     filtro.Ordenacao,
+		Direcao:       filtro.Direcao,
+		ProximoCursor: p.proximoCursor,
+	}
+}
+
+// medicamentosOrdenados devolve os itens na ordem das views SQL (nome, depois id)
+func medicamentosOrdenados(p *readmodel.Prescricao) []readmodel.Medicamento {
+	meds := append([]readmodel.Medicamento{}, p.Medicamentos...)
+	sort.Slice(meds, func(i, j int) bool {
+		if meds[i].Nome != meds[j].Nome {
+			return meds[i].Nome < meds[j].Nome
+		}
+		return meds[i].ID < meds[j].ID
+	})
+	return meds
+}
+
+func prescricaoFarmacia(p *readmodel.Prescricao) domain.PrescricaoFarmaciaDTO {
+	dto := domain.PrescricaoFarmaciaDTO{
+		IDPrescricao:           p.IDPrescricao,
+		DataPrescricao:         p.DataPrescricao,
+		PacienteID:             p.Paciente.ID,
+		PacienteNome:           p.Paciente.Nome,
+		PacienteDataNascimento: p.Paciente.DataNascimento,
+		Medicamentos:           []domain.MedicamentoFarmaciaDTO{},
+	}
+	for _, m := range medicamentosOrdenados(p) {
+		dto.Medicamentos = append(dto.Medicamentos, domain.MedicamentoFarmaciaDTO{
+			MedicamentoID:        m.ID,
+			MedicamentoNome:      m.Nome,
+			MedicamentoDescricao: m.Descricao,
+			Horario:              m.Horario,
+			Dosagem:              m.Dosagem,
+			StatusDispensacao:    m.StatusDispensacao(),
+		})
+	}
+	return dto
+}
+
+func prescricaoProntuario(p *readmodel.Prescricao) domain.PrescricaoProntuarioDTO {
+	dto := domain.PrescricaoProntuarioDTO{
+		IDPrescricao:        p.IDPrescricao,
+		DataPrescricao:      p.DataPrescricao,
+		MedicoID:            p.Medico.ID,
+		MedicoNome:          p.Medico.Nome,
+		MedicoEspecialidade: p.Medico.Especialidade,
+		MedicoCRM:           p.Medico.CRM,
+		Status:              p.Status,
+		Medicamentos:        []domain.MedicamentoProntuarioDTO{},
+	}
+	for _, m := range medicamentosOrdenados(p) {
+		med := domain.MedicamentoProntuarioDTO{
+			MedicamentoID:        m.ID,
+			MedicamentoNome:      m.Nome,
+			MedicamentoDescricao: m.Descricao,
+			Horario:              m.Horario,
+			Dosagem:              m.Dosagem,
+			StatusDispensacao:    m.StatusDispensacao(),
+		}
+		for _, d := range m.Dispensacoes {
+			med.Dispensacoes = append(med.Dispensacoes, domain.DispensacaoDTO{
+				IDDispensacao: d.ID,
+				Status:        d.Status,
+				Quantidade:    d.Quantidade,
+				Motivo:        d.Motivo,
+				Farmaceutico:  d.Farmaceutico,
+				DispensadoEm:  d.DispensadoEm,
+			})
+		}
+		dto.Medicamentos = append(dto.Medicamentos, med)
+	}
+	return dto
+}
@@ -0,0 +1,148 @@
+package queries
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrFiltroInvalido indica parâmetros de consulta inválidos (ordenação, cursor, datas)
+var ErrFiltroInvalido = errors.New("filtro inválido")
+
+const (
+	limitePadrao = 20
+	limiteMaximo = 100
+)
+
+// FiltroPrescricoes reúne filtros, ordenação e paginação das listagens de prescrições
+// Campos zerados não filtram
+type FiltroPrescricoes struct {
+	DataInicio    *time.Time // inclusive
+	DataFim       *time.Time // exclusive
+	PacienteID    int
+	MedicoID      int
+	MedicamentoID int
+
+	// AsOf consulta o prontuário como era até esse instante (nil = estado atual)
+	// Só o prontuário aceita; a farmácia mostra apenas o que pode ser dispensado agora
+	AsOf *time.Time
+
+	Ordenacao string // campo de ordenação (depende da view)
+	Direcao   string // "asc" ou "desc"
+	Cursor    string // opaco, retornado em proximo_cursor
+	Limite    int
+}
+
+// cursorPagina é o conteúdo do cursor: última chave de ordenação e id vistos na página anterior
+type cursorPagina struct {
+	Ordenacao string `json:"o"`
+	Chave     string `json:"k"`
+	ID        int    `json:"id"`
+}
+
+// ParseData aceita "2006-01-02" ou RFC3339; fimDoDia avança datas simples para o dia seguinte
+// (para usar como limite exclusivo em data_fim)
+func ParseData(valor string, fimDoDia bool) (*time.Time, error) {
+	if valor == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse("2006-01-02", valor); err == nil {
+		if fimDoDia {
+			t = t.AddDate(0, 0, 1)
+		}
+		return &t, nil
+	}
+	t, err := time.Parse(time.RFC3339, valor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: data %q (use AAAA-MM-DD ou RFC3339)", ErrFiltroInvalido, valor)
+	}
+	return &t, nil
+}
+
+// ParseInstante lê o as_of: "2006-01-02" vira o último instante do dia (precisão de
+// microssegundo do timestamp do Postgres), para que as comparações inclusivas com o instante
+// não incluam o que foi gravado à meia-noite do dia seguinte; RFC3339 é usado como veio
+func ParseInstante(valor string) (*time.Time, error) {
+	if t, err := time.Parse("2006-01-02", valor); err == nil {
+		t = t.AddDate(0, 0, 1).Add(-time.Microsecond)
+		return &t, nil
+	}
+	return ParseData(valor, false)
+}
+
+// normalizar aplica os padrões e valida ordenação contra as colunas permitidas da view
+// Retorna a coluna SQL de ordenação
+func (f *FiltroPrescricoes) normalizar(colunasOrdenacao map[string]string) (string, error) {
+	if f.Limite <= 0 {
+		f.Limite = limitePadrao
+	}
+	if f.Limite > limiteMaximo {
+		f.Limite = limiteMaximo
+	}
+
+	if f.Ordenacao == "" {
+		f.Ordenacao = "data_prescricao"
+	}
+	coluna, ok := colunasOrdenacao[f.Ordenacao]
+	if !ok {
+		permitidas := make([]string, 0, len(colunasOrdenacao))
+		for nome := range colunasOrdenacao {
+			permitidas = append(permitidas, nome)
+		}
+		sort.Strings(permitidas)
+		return "", fmt.Errorf("%w: ordenação %q (permitidas: %s)", ErrFiltroInvalido, f.Ordenacao, strings.Join(permitidas, ", "))
+	}
+
+	f.Direcao = strings.ToLower(f.Direcao)
+	if f.Direcao == "" {
+		// Datas: mais recentes primeiro; nomes: ordem alfabética
+		f.Direcao = "asc"
+		if f.Ordenacao == "data_prescricao" {
+			f.Direcao = "desc"
+		}
+	}
+	if f.Direcao != "asc" && f.Direcao != "desc" {
+		return "", fmt.Errorf("%w: direção %q (use asc ou desc)", ErrFiltroInvalido, f.Direcao)
+	}
+
+	if f.DataInicio != nil && f.DataFim != nil && !f.DataInicio.Before(*f.DataFim) {
+		return "", fmt.Errorf("%w: data_inicio deve ser anterior a data_fim", ErrFiltroInvalido)
+	}
+
+	return coluna, nil
+}
+
+// ordenacaoCursor identifica a ordenação embutida no cursor; cursor de outra ordenação é inválido
+func (f *FiltroPrescricoes) ordenacaoCursor() string {
+	return f.Ordenacao + ":" + f.Direcao
+}
+
+func (f *FiltroPrescricoes) decodificarCursor() (*cursorPagina, error) {
+	if f.Cursor == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(f.Cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cursor malformado", ErrFiltroInvalido)
+	}
+
+	var cursor cursorPagina
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, fmt.Errorf("%w: cursor malformado", ErrFiltroInvalido)
+	}
+	if cursor.Ordenacao != f.ordenacaoCursor() {
+		return nil, fmt.Errorf("%w: cursor gerado para outra ordenação (%s)", ErrFiltroInvalido, cursor.Ordenacao)
+	}
+
+	return &cursor, nil
+}
+
+func (f *FiltroPrescricoes) codificarCursor(chave string, id int) string {
+	raw, _ := json.Marshal(cursorPagina{Ordenacao: f.ordenacaoCursor(), Chave: chave, ID: id})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
@@ -0,0 +1,599 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"hospital-cqrs/internal/domain"
+)
+
+// ReadModelStore são as consultas das views da farmácia e do prontuário
+// O query service escolhe a implementação por READ_MODEL_BACKEND: QueryRepository (Postgres)
+// ou DocumentStore (Redis)
+type ReadModelStore interface {
+	GetPrescricoesFarmacia(ctx context.Context, filtro FiltroPrescricoes) (*domain.PaginaPrescricoesFarmaciaDTO, error)
+	GetPrescricaoFarmaciaByID(ctx context.Context, idPrescricao int) (*domain.PrescricaoFarmaciaDTO, error)
+	GetProntuarioPaciente(ctx context.Context, idPaciente int, filtro FiltroPrescricoes) (*domain.ProntuarioPacienteDTO, error)
+	// EventoProjetado informa se o evento já foi aplicado nas views (tokens de consistência)
+	EventoProjetado(ctx context.Context, eventID string) (bool, error)
+	// VersaoProjetada informa se as views já refletem a versão da prescrição (tokens do CDC)
+	VersaoProjetada(ctx context.Context, idPrescricao, versao int) (bool, error)
+}
+
+// QueryRepository gerencia as consultas nos modelos de leitura (Read Side)
+type QueryRepository struct {
+	db *sql.DB
+}
+
+// NewQueryRepository cria um novo repositório de queries
+func NewQueryRepository(db *sql.DB) *QueryRepository {
+	return &QueryRepository{db: db}
+}
+
+// EventoProjetado consulta Processed_Events, gravado na mesma transação que atualiza as views
+func (r *QueryRepository) EventoProjetado(ctx context.Context, eventID string) (bool, error) {
+	var processado bool
+	query := `SELECT EXISTS(SELECT 1 FROM Processed_Events WHERE event_id = $1)`
+	if err := r.db.QueryRowContext(ctx, query, eventID).Scan(&processado); err != nil {
+		return false, fmt.Errorf("erro ao verificar evento projetado: %w", err)
+	}
+	return processado, nil
+}
+
+// VersaoProjetada consulta View_Versao_Prescricao, gravada pelo handler CDC junto com as views
+func (r *QueryRepository) VersaoProjetada(ctx context.Context, idPrescricao, versao int) (bool, error) {
+	var alcancou bool
+	query := `SELECT EXISTS(SELECT 1 FROM View_Versao_Prescricao WHERE id_prescricao = $1 AND versao >= $2)`
+	if err := r.db.QueryRowContext(ctx, query, idPrescricao, versao).Scan(&alcancou); err != nil {
+		return false, fmt.Errorf("erro ao verificar versão projetada: %w", err)
+	}
+	return alcancou, nil
+}
+
+// =========================================
+// QUERIES - VIEW FARMÁCIA
+// =========================================
+
+// colunasOrdenacaoFarmacia são as ordenações aceitas na listagem da farmácia
+var colunasOrdenacaoFarmacia = map[string]string{
+	"data_prescricao": "data_prescricao",
+	"paciente_nome":   "paciente_nome",
+}
+
+// GetPrescricoesFarmacia retorna uma página de prescrições para a farmácia
+// A paginação é por prescrição (não por linha de medicamento), com cursor e ordem estável:
+// a chave de ordenação é desempatada pelo id da prescrição
+func (r *QueryRepository) GetPrescricoesFarmacia(ctx context.Context, filtro FiltroPrescricoes) (*domain.PaginaPrescricoesFarmaciaDTO, error) {
+	colunaOrdenacao, err := filtro.normalizar(colunasOrdenacaoFarmacia)
+	if err != nil {
+		return nil, err
+	}
+
+	// Contagem, página e detalhes na mesma foto do banco
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao iniciar transação de leitura: %w", err)
+	}
+	defer tx.Rollback()
+
+	consulta := novaConsultaPaginada("View_Farmacia", colunaOrdenacao)
+	consulta.filtrarPeriodo(filtro)
+	if filtro.PacienteID > 0 {
+		consulta.onde("v.paciente_id = " + consulta.arg(filtro.PacienteID))
+	}
+	if filtro.MedicoID > 0 {
+		// View_Farmacia não guarda o médico: o prontuário tem a mesma prescrição com medico_id
+		consulta.onde(`EXISTS (
+			SELECT 1 FROM View_Prontuario_Paciente pp
+			WHERE pp.id_prescricao = v.id_prescricao AND pp.medico_id = ` + consulta.arg(filtro.MedicoID) + `)`)
+	}
+	consulta.filtrarMedicamento(filtro)
+
+	pagina, err := consulta.executar(ctx, tx, &filtro)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar prescrições da farmácia: %w", err)
+	}
+
+	query := `
+		SELECT 
+			id_prescricao, data_prescricao,
+			paciente_id, paciente_nome, paciente_data_nascimento,
+			medicamento_id, medicamento_nome, medicamento_descricao,
+			horario, dosagem, status_dispensacao
+		FROM View_Farmacia
+		WHERE id_prescricao = ANY($1)
+		ORDER BY id_prescricao, medicamento_nome, medicamento_id
+	`
+
+	rows, err := tx.QueryContext(ctx, query, pq.Array(pagina.ids))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar prescrições da farmácia: %w", err)
+	}
+	defer rows.Close()
+
+	// Agrupar por prescrição, preservando a ordem da página
+	prescricoes := make([]domain.PrescricaoFarmaciaDTO, len(pagina.ids))
+	posicao := pagina.posicoes()
+
+	for rows.Next() {
+		var (
+			idPrescricao           int
+			dataPrescricao         time.Time
+			pacienteID             int
+			pacienteNome           string
+			pacienteDataNascimento time.Time
+			medicamentoID          int
+			medicamentoNome        string
+			medicamentoDescricao   string
+			horario                string
+			dosagem                string
+			statusDispensacao      string
+		)
+
+		if err := rows.Scan(&idPrescricao, &dataPrescricao, &pacienteID, &pacienteNome,
+			&pacienteDataNascimento, &medicamentoID, &medicamentoNome,
+			&medicamentoDescricao, &horario, &dosagem, &statusDispensacao); err != nil {
+			return nil, fmt.Errorf("erro ao scanear linha: %w", err)
+		}
+
+		p := &prescricoes[posicao[idPrescricao]]
+		if p.IDPrescricao == 0 {
+			*p = domain.PrescricaoFarmaciaDTO{
+				IDPrescricao:           idPrescricao,
+				DataPrescricao:         dataPrescricao,
+				PacienteID:             pacienteID,
+				PacienteNome:           pacienteNome,
+				PacienteDataNascimento: pacienteDataNascimento,
+				Medicamentos:           []domain.MedicamentoFarmaciaDTO{},
+			}
+		}
+
+		// Adicionar medicamento à prescrição
+		p.Medicamentos = append(p.Medicamentos, domain.MedicamentoFarmaciaDTO{
+			MedicamentoID:        medicamentoID,
+			MedicamentoNome:      medicamentoNome,
+			MedicamentoDescricao: medicamentoDescricao,
+			Horario:              horario,
+			Dosagem:              dosagem,
+			StatusDispensacao:    statusDispensacao,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler prescrições da farmácia: %w", err)
+	}
+
+	return &domain.PaginaPrescricoesFarmaciaDTO{
+		Prescricoes: prescricoes,
+		Paginacao:   pagina.paginacao(filtro),
+	}, nil
+}
+
+// GetPrescricaoFarmaciaByID retorna uma prescrição específica para a farmácia
+func (r *QueryRepository) GetPrescricaoFarmaciaByID(ctx context.Context, idPrescricao int) (*domain.PrescricaoFarmaciaDTO, error) {
+	query := `
+		SELECT 
+			id_prescricao, data_prescricao,
+			paciente_id, paciente_nome, paciente_data_nascimento,
+			medicamento_id, medicamento_nome, medicamento_descricao,
+			horario, dosagem, status_dispensacao
+		FROM View_Farmacia
+		WHERE id_prescricao = $1
+		ORDER BY medicamento_nome
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, idPrescricao)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar prescrição: %w", err)
+	}
+	defer rows.Close()
+
+	var prescricao *domain.PrescricaoFarmaciaDTO
+
+	for rows.Next() {
+		var (
+			idPresc     int
+			dataPresc   time.Time
+			pacID       int
+			pacNome     string
+			pacDataNasc time.Time
+			medID       int
+			medNome     string
+			medDesc     string
+			horario     string
+			dosagem     string
+			statusDisp  string
+		)
+
+		if err := rows.Scan(&idPresc, &dataPresc, &pacID, &pacNome, &pacDataNasc,
+			&medID, &medNome, &medDesc, &horario, &dosagem, &statusDisp); err != nil {
+			return nil, fmt.Errorf("erro ao scanear linha: %w", err)
+		}
+
+		if prescricao == nil {
+			prescricao = &domain.PrescricaoFarmaciaDTO{
+				IDPrescricao:           idPresc,
+				DataPrescricao:         dataPresc,
+				PacienteID:             pacID,
+				PacienteNome:           pacNome,
+				PacienteDataNascimento: pacDataNasc,
+				Medicamentos:           []domain.MedicamentoFarmaciaDTO{},
+			}
+		}
+
+		medicamento := domain.MedicamentoFarmaciaDTO{
+			MedicamentoID:        medID,
+			MedicamentoNome:      medNome,
+			MedicamentoDescricao: medDesc,
+			Horario:              horario,
+			Dosagem:              dosagem,
+			StatusDispensacao:    statusDisp,
+		}
+		prescricao.Medicamentos = append(prescricao.Medicamentos, medicamento)
+	}
+
+	if prescricao == nil {
+		return nil, fmt.Errorf("prescrição não encontrada")
+	}
+
+	return prescricao, nil
+}
+
+// =========================================
+// QUERIES - VIEW PRONTUÁRIO
+// =========================================
+
+// colunasOrdenacaoProntuario são as ordenações aceitas no prontuário
+var colunasOrdenacaoProntuario = map[string]string{
+	"data_prescricao": "data_prescricao",
+	"medico_nome":     "medico_nome",
+}
+
+// fonteProntuario é o prontuário vigente até o instante (placeholder asOf), a partir das
+// vigências de View_Prontuario_Historico (intervalos [valido_de, valido_ate)): mesmas colunas de View_Prontuario_Paciente, com
+// status, horário e dosagem da época; cadastros (nomes, CRM) são os atuais da view
+func fonteProntuario(asOf string) string {
+	return `(
+		SELECT
+			v.id_prescricao, v.data_prescricao, v.paciente_id,
+			v.medico_id, v.medico_nome, v.medico_especialidade, v.medico_crm, h.status,
+			v.medicamento_id, v.medicamento_nome, v.medicamento_descricao,
+			h.horario, h.dosagem
+		FROM View_Prontuario_Historico h
+		JOIN View_Prontuario_Paciente v
+			ON v.id_prescricao = h.id_prescricao AND v.medicamento_id = h.medicamento_id
+		WHERE h.valido_de <= ` + asOf + ` AND (h.valido_ate IS NULL OR h.valido_ate > ` + asOf + `)
+	)`
+}
+
+// GetProntuarioPaciente retorna o prontuário de um paciente com uma página de prescrições
+// Filtros que não casam com nenhuma prescrição retornam o prontuário com a lista vazia
+// Com filtro.AsOf, as prescrições e itens são os vigentes naquele instante (inclusive os que
+// depois foram alterados ou cancelados), com os valores da época
+func (r *QueryRepository) GetProntuarioPaciente(ctx context.Context, idPaciente int, filtro FiltroPrescricoes) (*domain.ProntuarioPacienteDTO, error) {
+	colunaOrdenacao, err := filtro.normalizar(colunasOrdenacaoProntuario)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao iniciar transação de leitura: %w", err)
+	}
+	defer tx.Rollback()
+
+	prontuario := &domain.ProntuarioPacienteDTO{Prescricoes: []domain.PrescricaoProntuarioDTO{}}
+	queryPaciente := `
+		SELECT paciente_id, paciente_nome, paciente_data_nascimento, paciente_endereco
+		FROM View_Prontuario_Paciente
+		WHERE paciente_id = $1
+		LIMIT 1
+	`
+	err = tx.QueryRowContext(ctx, queryPaciente, idPaciente).Scan(&prontuario.PacienteID, &prontuario.PacienteNome,
+		&prontuario.PacienteDataNascimento, &prontuario.PacienteEndereco)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("paciente não encontrado")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar prontuário: %w", err)
+	}
+
+	consulta := novaConsultaPaginada("View_Prontuario_Paciente", colunaOrdenacao)
+	if filtro.AsOf != nil {
+		consulta.view = fonteProntuario(consulta.arg(*filtro.AsOf))
+	}
+	consulta.onde("v.paciente_id = " + consulta.arg(idPaciente))
+	consulta.filtrarPeriodo(filtro)
+	if filtro.MedicoID > 0 {
+		consulta.onde("v.medico_id = " + consulta.arg(filtro.MedicoID))
+	}
+	consulta.filtrarMedicamento(filtro)
+
+	pagina, err := consulta.executar(ctx, tx, &filtro)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar prontuário: %w", err)
+	}
+
+	fonte := "View_Prontuario_Paciente"
+	args := []interface{}{idPaciente, pq.Array(pagina.ids)}
+	if filtro.AsOf != nil {
+		fonte = fonteProntuario("$3")
+		args = append(args, *filtro.AsOf)
+	}
+
+	query := `
+		SELECT 
+			id_prescricao, data_prescricao,
+			medico_id, medico_nome, medico_especialidade, medico_crm, status,
+			medicamento_id, medicamento_nome, medicamento_descricao,
+			horario, dosagem
+		FROM ` + fonte + ` p
+		WHERE paciente_id = $1 AND id_prescricao = ANY($2)
+		ORDER BY id_prescricao, medicamento_nome, medicamento_id
+	`
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar prontuário: %w", err)
+	}
+	defer rows.Close()
+
+	prontuario.Prescricoes = make([]domain.PrescricaoProntuarioDTO, len(pagina.ids))
+	posicao := pagina.posicoes()
+
+	for rows.Next() {
+		var (
+			idPresc   int
+			dataPresc time.Time
+			medID     int
+			medNome   string
+			medEspec  string
+			medCRM    string
+			status    string
+			medicID   int
+			medicNome string
+			medicDesc string
+			horario   string
+			dosagem   string
+		)
+
+		if err := rows.Scan(&idPresc, &dataPresc, &medID, &medNome, &medEspec, &medCRM, &status,
+			&medicID, &medicNome, &medicDesc, &horario, &dosagem); err != nil {
+			return nil, fmt.Errorf("erro ao scanear linha: %w", err)
+		}
+
+		p := &prontuario.Prescricoes[posicao[idPresc]]
+		if p.IDPrescricao == 0 {
+			*p = domain.PrescricaoProntuarioDTO{
+				IDPrescricao:        idPresc,
+				DataPrescricao:      dataPresc,
+				MedicoID:            medID,
+				MedicoNome:          medNome,
+				MedicoEspecialidade: medEspec,
+				MedicoCRM:           medCRM,
+				Status:              status,
+				Medicamentos:        []domain.MedicamentoProntuarioDTO{},
+			}
+		}
+
+		// Adicionar medicamento à prescrição
+		p.Medicamentos = append(p.Medicamentos, domain.MedicamentoProntuarioDTO{
+			MedicamentoID:        medicID,
+			MedicamentoNome:      medicNome,
+			MedicamentoDescricao: medicDesc,
+			Horario:              horario,
+			Dosagem:              dosagem,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler prontuário: %w", err)
+	}
+	rows.Close()
+
+	if err := anexarDispensacoes(ctx, tx, prontuario.Prescricoes, posicao, pagina.ids, filtro.AsOf); err != nil {
+		return nil, err
+	}
+
+	paginacao := pagina.paginacao(filtro)
+	prontuario.Paginacao = &paginacao
+	return prontuario, nil
+}
+
+// anexarDispensacoes inclui nos medicamentos da página o histórico de dispensações e o status
+// da mais recente; com asOf, só as dispensações feitas antes do instante
+func anexarDispensacoes(ctx context.Context, tx *sql.Tx, prescricoes []domain.PrescricaoProntuarioDTO, posicao map[int]int, ids []int, asOf *time.Time) error {
+	query := `
+		SELECT id_dispensacao, id_prescricao, medicamento_id, status,
+			COALESCE(quantidade, ''), COALESCE(motivo, ''), farmaceutico, dispensado_em
+		FROM View_Prontuario_Dispensacoes
+		WHERE id_prescricao = ANY($1) AND ($2::timestamp IS NULL OR dispensado_em <= $2)
+		ORDER BY dispensado_em, id_dispensacao
+	`
+
+	rows, err := tx.QueryContext(ctx, query, pq.Array(ids), asOf)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar dispensações: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			d             domain.DispensacaoDTO
+			idPresc       int
+			idMedicamento int
+		)
+		if err := rows.Scan(&d.IDDispensacao, &idPresc, &idMedicamento, &d.Status,
+			&d.Quantidade, &d.Motivo, &d.Farmaceutico, &d.DispensadoEm); err != nil {
+			return fmt.Errorf("erro ao scanear dispensação: %w", err)
+		}
+
+		p := &prescricoes[posicao[idPresc]]
+		for i := range p.Medicamentos {
+			if p.Medicamentos[i].MedicamentoID == idMedicamento {
+				p.Medicamentos[i].Dispensacoes = append(p.Medicamentos[i].Dispensacoes, d)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("erro ao ler dispensações: %w", err)
+	}
+
+	for i := range prescricoes {
+		for j := range prescricoes[i].Medicamentos {
+			m := &prescricoes[i].Medicamentos[j]
+			m.StatusDispensacao = domain.StatusDispensacaoAtual(m.Dispensacoes)
+		}
+	}
+	return nil
+}
+
+// =========================================
+// PAGINAÇÃO POR PRESCRIÇÃO
+// =========================================
+
+// consultaPaginada monta a seleção de prescrições de uma view (agrupando as linhas de medicamento)
+// com filtros parametrizados e paginação por keyset (chave de ordenação, id_prescricao)
+type consultaPaginada struct {
+	view            string
+	colunaOrdenacao string
+	condicoes       []string
+	having          []string
+	args            []interface{}
+}
+
+func novaConsultaPaginada(view, colunaOrdenacao string) *consultaPaginada {
+	return &consultaPaginada{view: view, colunaOrdenacao: colunaOrdenacao}
+}
+
+// arg registra um parâmetro e retorna seu placeholder
+func (q *consultaPaginada) arg(valor interface{}) string {
+	q.args = append(q.args, valor)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+func (q *consultaPaginada) onde(condicao string) {
+	q.condicoes = append(q.condicoes, condicao)
+}
+
+func (q *consultaPaginada) filtrarPeriodo(filtro FiltroPrescricoes) {
+	if filtro.DataInicio != nil {
+		q.onde("v.data_prescricao >= " + q.arg(*filtro.DataInicio))
+	}
+	if filtro.DataFim != nil {
+		q.onde("v.data_prescricao < " + q.arg(*filtro.DataFim))
+	}
+}
+
+// filtrarMedicamento seleciona prescrições que contêm o medicamento, mas retorna todos os itens delas
+func (q *consultaPaginada) filtrarMedicamento(filtro FiltroPrescricoes) {
+	if filtro.MedicamentoID > 0 {
+		q.having = append(q.having, "bool_or(v.medicamento_id = "+q.arg(filtro.MedicamentoID)+")")
+	}
+}
+
+// sqlPrescricoes retorna a subconsulta com uma linha por prescrição (id_prescricao, chave)
+func (q *consultaPaginada) sqlPrescricoes() string {
+	query := `SELECT v.id_prescricao, MAX(v.` + q.colunaOrdenacao + `) AS chave FROM ` + q.view + ` v`
+	if len(q.condicoes) > 0 {
+		query += ` WHERE ` + strings.Join(q.condicoes, " AND ")
+	}
+	query += ` GROUP BY v.id_prescricao`
+	if len(q.having) > 0 {
+		query += ` HAVING ` + strings.Join(q.having, " AND ")
+	}
+	return query
+}
+
+// paginaPrescricoes é o resultado da seleção: ids na ordem da página e total sem paginação
+type paginaPrescricoes struct {
+	ids           []int
+	total         int
+	proximoCursor string
+}
+
+// executar conta o total e busca os ids da página a partir do cursor
+func (q *consultaPaginada) executar(ctx context.Context, tx *sql.Tx, filtro *FiltroPrescricoes) (*paginaPrescricoes, error) {
+	cursor, err := filtro.decodificarCursor()
+	if err != nil {
+		return nil, err
+	}
+
+	pagina := &paginaPrescricoes{ids: []int{}}
+	base := q.sqlPrescricoes()
+
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM (`+base+`) p`, q.args...).Scan(&pagina.total); err != nil {
+		return nil, err
+	}
+
+	args := append([]interface{}{}, q.args...)
+	placeholder := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	comparador, direcao := ">", "ASC"
+	if filtro.Direcao == "desc" {
+		comparador, direcao = "<", "DESC"
+	}
+
+	query := `SELECT id_prescricao, chave FROM (` + base + `) p`
+	if cursor != nil {
+		var chave interface{} = cursor.Chave
+		if q.colunaOrdenacao == "data_prescricao" {
+			t, err := time.Parse(time.RFC3339Nano, cursor.Chave)
+			if err != nil {
+				return nil, fmt.Errorf("%w: cursor malformado", ErrFiltroInvalido)
+			}
+			chave = t
+		}
+		query += ` WHERE (chave, id_prescricao) ` + comparador + ` (` + placeholder(chave) + `, ` + placeholder(cursor.ID) + `)`
+	}
+	// Busca um a mais para saber se existe próxima página
+	query += ` ORDER BY chave ` + direcao + `, id_prescricao ` + direcao + ` LIMIT ` + placeholder(filtro.Limite+1)
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ultimaChave string
+	for rows.Next() {
+		var id int
+		var chave string
+		if err := rows.Scan(&id, &chave); err != nil {
+			return nil, err
+		}
+		if len(pagina.ids) == filtro.Limite {
+			pagina.proximoCursor = filtro.codificarCursor(ultimaChave, pagina.ids[len(pagina.ids)-1])
+			break
+		}
+		pagina.ids = append(pagina.ids, id)
+		ultimaChave = chave
+	}
+
+	return pagina, rows.Err()
+}
+
+// posicoes indexa cada id pela sua posição na página
+func (p *paginaPrescricoes) posicoes() map[int]int {
+	posicao := make(map[int]int, len(p.ids))
+	for i, id := range p.ids {
+		posicao[id] = i
+	}
+	return posicao
+}
+
+func (p *paginaPrescricoes) paginacao(filtro FiltroPrescricoes) domain.PaginacaoDTO {
+	return domain.PaginacaoDTO{
+		Total:         p.total,
+		Limite:        filtro.Limite,
+		Ordenacao:     filtro.Ordenacao,
+		Direcao:       filtro.Direcao,
+		ProximoCursor: p.proximoCursor,
+	}
+}
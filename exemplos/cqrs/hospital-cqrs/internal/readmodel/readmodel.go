@@ -0,0 +1,210 @@
+package readmodel
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"hospital-cqrs/internal/domain"
+)
+
+// =========================================
+// MODELO DE LEITURA DOCUMENTAL
+// =========================================
+// As views da farmácia e do prontuário podem ficar no Postgres (tabelas View_*) ou em um
+// armazenamento de documentos separado do modelo de escrita. No formato documental cada
+// prescrição é um documento com tudo o que as duas views exibem: a farmácia é o conjunto
+// das prescrições ativas e o prontuário, as prescrições de um paciente.
+
+// Backends de modelo de leitura aceitos em READ_MODEL_BACKEND
+const (
+	BackendPostgres = "postgres"
+	BackendRedis    = "redis"
+)
+
+// Backend retorna o backend configurado em READ_MODEL_BACKEND (padrão postgres)
+func Backend() (string, error) {
+	backend := os.Getenv("READ_MODEL_BACKEND")
+	switch backend {
+	case "", BackendPostgres:
+		return BackendPostgres, nil
+	case BackendRedis:
+		return BackendRedis, nil
+	default:
+		return "", fmt.Errorf("READ_MODEL_BACKEND inválido: %q (use %s ou %s)", backend, BackendPostgres, BackendRedis)
+	}
+}
+
+// Prescricao é o documento de uma prescrição no modelo de leitura
+type Prescricao struct {
+	IDPrescricao   int           `json:"id_prescricao"`
+	DataPrescricao time.Time     `json:"data_prescricao"`
+	Status         string        `json:"status"`
+	Paciente       Paciente      `json:"paciente"`
+	Medico         Medico        `json:"medico"`
+	Medicamentos   []Medicamento `json:"medicamentos"`
+	AtualizadoEm   time.Time     `json:"atualizado_em"`
+}
+
+// Paciente é a cópia do cadastro do paciente guardada no documento
+type Paciente struct {
+	ID             int       `json:"id"`
+	Nome           string    `json:"nome"`
+	DataNascimento time.Time `json:"data_nascimento"`
+	Endereco       string    `json:"endereco"`
+}
+
+// Medico é a cópia do cadastro do médico guardada no documento
+type Medico struct {
+	ID            int    `json:"id"`
+	Nome          string `json:"nome"`
+	Especialidade string `json:"especialidade"`
+	CRM           string `json:"crm"`
+}
+
+// Medicamento é um item prescrito, com a cópia do cadastro do medicamento
+type Medicamento struct {
+	ID        int        `json:"id"`
+	Nome      string     `json:"nome"`
+	Descricao string     `json:"descricao"`
+	Horario   string     `json:"horario"`
+	Dosagem   string     `json:"dosagem"`
+	Vigencias []Vigencia `json:"vigencias,omitempty"` // histórico para o prontuário em uma data
+	// Dispensacoes em ordem cronológica; a última define o status de dispensação do item
+	Dispensacoes []Dispensacao `json:"dispensacoes,omitempty"`
+}
+
+// Dispensacao é uma dispensação do item registrada pela farmácia
+type Dispensacao struct {
+	ID           int       `json:"id"`
+	Status       string    `json:"status"`
+	Quantidade   string    `json:"quantidade,omitempty"`
+	Motivo       string    `json:"motivo,omitempty"`
+	Farmaceutico string    `json:"farmaceutico"`
+	DispensadoEm time.Time `json:"dispensado_em"`
+}
+
+// StatusDispensacao é o status da dispensação mais recente do item (PENDENTE se não houver)
+func (m *Medicamento) StatusDispensacao() string {
+	if len(m.Dispensacoes) == 0 {
+		return domain.StatusDispensacaoPendente
+	}
+	return m.Dispensacoes[len(m.Dispensacoes)-1].Status
+}
+
+// RegistrarDispensacao inclui a dispensação no histórico do item, se ainda não estiver lá
+// A ordem é pela data da dispensação (e id), não pela ordem de chegada dos eventos
+func (m *Medicamento) RegistrarDispensacao(d Dispensacao) bool {
+	for _, existente := range m.Dispensacoes {
+		if existente.ID == d.ID {
+			return false
+		}
+	}
+	m.Dispensacoes = append(m.Dispensacoes, d)
+	sort.SliceStable(m.Dispensacoes, func(i, j int) bool {
+		a, b := m.Dispensacoes[i], m.Dispensacoes[j]
+		if !a.DispensadoEm.Equal(b.DispensadoEm) {
+			return a.DispensadoEm.Before(b.DispensadoEm)
+		}
+		return a.ID < b.ID
+	})
+	return true
+}
+
+// Vigencia é um intervalo [De, Ate) em que o item valeu com status, horário e dosagem
+// Ate nil indica a vigência atual (mesma semântica de View_Prontuario_Historico)
+type Vigencia struct {
+	Status  string     `json:"status"`
+	Horario string     `json:"horario"`
+	Dosagem string     `json:"dosagem"`
+	De      time.Time  `json:"de"`
+	Ate     *time.Time `json:"ate,omitempty"`
+}
+
+// contem informa se o instante está no intervalo [De, Ate)
+func (v Vigencia) contem(instante time.Time) bool {
+	return !v.De.After(instante) && (v.Ate == nil || v.Ate.After(instante))
+}
+
+// Medicamento devolve o item do medicamento na prescrição, se houver
+func (p *Prescricao) Medicamento(id int) (*Medicamento, bool) {
+	for i := range p.Medicamentos {
+		if p.Medicamentos[i].ID == id {
+			return &p.Medicamentos[i], true
+		}
+	}
+	return nil, false
+}
+
+// SalvarMedicamento inclui o item ou substitui o existente (mesma chave das views:
+// prescrição + medicamento), de modo que reaplicar um evento não duplica itens
+// Os históricos de vigências e de dispensações do item existente são preservados
+func (p *Prescricao) SalvarMedicamento(m Medicamento) {
+	if atual, ok := p.Medicamento(m.ID); ok {
+		m.Vigencias = atual.Vigencias
+		m.Dispensacoes = atual.Dispensacoes
+		*atual = m
+		return
+	}
+	p.Medicamentos = append(p.Medicamentos, m)
+}
+
+// RegistrarVigencias alinha o histórico de cada item com o estado atual do documento
+// Mesmo algoritmo de events.SincronizarVigencias: sem mudança nada é gravado; com mudança o
+// intervalo vigente é fechado em desde (nunca antes do seu início) e outro é aberto. O primeiro
+// intervalo de um item começa na data da prescrição.
+func (p *Prescricao) RegistrarVigencias(desde time.Time) {
+	for i := range p.Medicamentos {
+		m := &p.Medicamentos[i]
+		inicio := p.DataPrescricao
+		if n := len(m.Vigencias); n > 0 {
+			atual := &m.Vigencias[n-1]
+			if atual.Ate == nil {
+				if atual.Status == p.Status && atual.Horario == m.Horario && atual.Dosagem == m.Dosagem {
+					continue
+				}
+				fim := desde
+				if fim.Before(atual.De) {
+					fim = atual.De
+				}
+				atual.Ate = &fim
+			}
+			inicio = *atual.Ate
+		}
+		m.Vigencias = append(m.Vigencias, Vigencia{Status: p.Status, Horario: m.Horario, Dosagem: m.Dosagem, De: inicio})
+	}
+}
+
+// Em devolve a prescrição como era até o instante: só os itens vigentes, com status,
+// horário e dosagem daquele momento e as dispensações feitas até ele; ok=false se
+// nenhum item valia então
+// Cadastros (nomes, CRM, endereço) ficam como estão hoje, como no Postgres
+func (p *Prescricao) Em(instante time.Time) (Prescricao, bool) {
+	copia := *p
+	copia.Medicamentos = []Medicamento{}
+	for _, m := range p.Medicamentos {
+		for _, v := range m.Vigencias {
+			if !v.contem(instante) {
+				continue
+			}
+			m.Horario, m.Dosagem, m.Vigencias = v.Horario, v.Dosagem, nil
+			m.Dispensacoes = dispensacoesAte(m.Dispensacoes, instante)
+			copia.Status = v.Status
+			copia.Medicamentos = append(copia.Medicamentos, m)
+			break
+		}
+	}
+	return copia, len(copia.Medicamentos) > 0
+}
+
+// dispensacoesAte devolve as dispensações feitas até o instante, inclusive (a lista é cronológica)
+func dispensacoesAte(dispensacoes []Dispensacao, instante time.Time) []Dispensacao {
+	n := sort.Search(len(dispensacoes), func(i int) bool {
+		return dispensacoes[i].DispensadoEm.After(instante)
+	})
+	if n == 0 {
+		return nil
+	}
+	return dispensacoes[:n:n]
+}
@@ -0,0 +1,308 @@
+package readmodel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"hospital-cqrs/internal/domain"
+)
+
+// Chaves do modelo de leitura no Redis
+//
+//	rm:prescricao:<id>                   documento JSON da prescrição
+//	rm:farmacia                          sorted set das prescrições ativas (score = data da prescrição)
+//	rm:paciente:<id>:prescricoes         set das prescrições do paciente (prontuário)
+//	rm:medico:<id>:prescricoes           set das prescrições do médico
+//	rm:medicamento:<id>:prescricoes      set das prescrições que contêm o medicamento
+//	rm:cadastro:<tipo>:<id>              versão (updated_at) do cadastro já aplicada
+//	rm:eventos_processados               set dos eventos já projetados (idempotência)
+const (
+	chaveFarmacia           = "rm:farmacia"
+	chaveEventosProcessados = "rm:eventos_processados"
+)
+
+func chavePrescricao(id int) string { return fmt.Sprintf("rm:prescricao:%d", id) }
+
+func chaveIndice(tipo string, id int) string { return fmt.Sprintf("rm:%s:%d:prescricoes", tipo, id) }
+
+func chaveCadastro(tipo string, id int) string { return fmt.Sprintf("rm:cadastro:%s:%d", tipo, id) }
+
+// Índices secundários mantidos para cada documento
+const (
+	IndicePaciente    = "paciente"
+	IndiceMedico      = "medico"
+	IndiceMedicamento = "medicamento"
+)
+
+// maxTentativasProjecao limita as repetições quando outra projeção altera o Redis ao mesmo tempo
+const maxTentativasProjecao = 10
+
+// Redis guarda as views como documentos no Redis, fora do banco do modelo de escrita
+type Redis struct {
+	client *redis.Client
+}
+
+// ConectarRedis conecta ao Redis configurado em REDIS_URL (padrão redis://localhost:6379/0)
+func ConectarRedis(ctx context.Context) (*Redis, error) {
+	url := os.Getenv("REDIS_URL")
+	if url == "" {
+		url = "redis://localhost:6379/0"
+	}
+
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("REDIS_URL inválida: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("erro ao conectar ao Redis: %w", err)
+	}
+
+	log.Printf("Modelo de leitura no Redis (%s)", opts.Addr)
+	return &Redis{client: client}, nil
+}
+
+// Close encerra a conexão com o Redis
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
+
+// =========================================
+// LEITURA
+// =========================================
+
+// IDsFarmacia retorna as prescrições ativas (as que a farmácia enxerga)
+func (r *Redis) IDsFarmacia(ctx context.Context) ([]int, error) {
+	membros, err := r.client.ZRange(ctx, chaveFarmacia, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar prescrições da farmácia: %w", err)
+	}
+	return paraInts(membros)
+}
+
+// IDsPorIndice retorna as prescrições de um paciente, médico ou medicamento
+func (r *Redis) IDsPorIndice(ctx context.Context, tipo string, id int) ([]int, error) {
+	membros, err := r.client.SMembers(ctx, chaveIndice(tipo, id)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar prescrições por %s: %w", tipo, err)
+	}
+	return paraInts(membros)
+}
+
+// Carregar lê os documentos das prescrições; ids sem documento são omitidos
+func (r *Redis) Carregar(ctx context.Context, ids []int) ([]Prescricao, error) {
+	return carregar(ctx, r.client, ids)
+}
+
+// EventoProjetado informa se o evento já foi aplicado no modelo de leitura
+func (r *Redis) EventoProjetado(ctx context.Context, eventID string) (bool, error) {
+	ok, err := r.client.SIsMember(ctx, chaveEventosProcessados, eventID).Result()
+	if err != nil {
+		return false, fmt.Errorf("erro ao verificar evento projetado: %w", err)
+	}
+	return ok, nil
+}
+
+// =========================================
+// ESCRITA
+// =========================================
+
+// errJaProjetado interrompe a projeção de um evento reentregue
+var errJaProjetado = errors.New("evento já projetado")
+
+// Projetar aplica fn uma única vez por evento
+//
+// Os documentos são lidos sob WATCH e gravados, junto com o registro do evento, em um único
+// MULTI/EXEC: ou tudo é aplicado ou nada. Todo evento altera rm:eventos_processados, então
+// duas projeções concorrentes não se sobrepõem: a segunda falha no EXEC e é repetida.
+func (r *Redis) Projetar(ctx context.Context, eventID, eventType string, fn func(*Documentos) error) error {
+	for tentativa := 1; tentativa <= maxTentativasProjecao; tentativa++ {
+		err := r.client.Watch(ctx, func(tx *redis.Tx) error {
+			projetado, err := tx.SIsMember(ctx, chaveEventosProcessados, eventID).Result()
+			if err != nil {
+				return fmt.Errorf("erro ao verificar evento projetado: %w", err)
+			}
+			if projetado {
+				return errJaProjetado
+			}
+
+			docs := &Documentos{ctx: ctx, tx: tx, carregados: make(map[int]*Prescricao), alterados: make(map[int]bool)}
+			if err := fn(docs); err != nil {
+				return err
+			}
+
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				if err := docs.gravar(pipe); err != nil {
+					return err
+				}
+				pipe.SAdd(ctx, chaveEventosProcessados, eventID)
+				return nil
+			})
+			return err
+		}, chaveEventosProcessados)
+
+		switch {
+		case err == nil:
+			return nil
+		case errors.Is(err, errJaProjetado):
+			log.Printf("Evento %s (%s) já processado - ignorando reentrega", eventID, eventType)
+			return nil
+		case errors.Is(err, redis.TxFailedErr):
+			continue
+		default:
+			return err
+		}
+	}
+	return fmt.Errorf("evento %s: conflito de escrita no Redis após %d tentativas", eventID, maxTentativasProjecao)
+}
+
+// Documentos dá acesso aos documentos dentro de uma projeção
+// Leituras vão direto ao Redis; alterações ficam em memória até o EXEC
+type Documentos struct {
+	ctx        context.Context
+	tx         *redis.Tx
+	carregados map[int]*Prescricao
+	alterados  map[int]bool
+	versoes    map[string]time.Time
+}
+
+// Prescricao devolve o documento da prescrição (o mesmo ponteiro durante toda a projeção)
+func (d *Documentos) Prescricao(id int) (*Prescricao, bool, error) {
+	if p, ok := d.carregados[id]; ok {
+		return p, true, nil
+	}
+
+	docs, err := carregar(d.ctx, d.tx, []int{id})
+	if err != nil {
+		return nil, false, err
+	}
+	if len(docs) == 0 {
+		return nil, false, nil
+	}
+	d.carregados[id] = &docs[0]
+	return &docs[0], true, nil
+}
+
+// Salvar marca o documento para gravação ao final da projeção
+func (d *Documentos) Salvar(p *Prescricao) {
+	d.carregados[p.IDPrescricao] = p
+	d.alterados[p.IDPrescricao] = true
+}
+
+// IDsPorIndice retorna as prescrições de um paciente, médico ou medicamento
+func (d *Documentos) IDsPorIndice(tipo string, id int) ([]int, error) {
+	membros, err := d.tx.SMembers(d.ctx, chaveIndice(tipo, id)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar prescrições por %s: %w", tipo, err)
+	}
+	return paraInts(membros)
+}
+
+// VersaoCadastroNova informa se atualizadoEm é mais recente que a versão do cadastro já
+// aplicada e, se for, registra a nova versão. Versão zero é sempre aplicada.
+func (d *Documentos) VersaoCadastroNova(tipo string, id int, atualizadoEm time.Time) (bool, error) {
+	if atualizadoEm.IsZero() {
+		return true, nil
+	}
+
+	chave := chaveCadastro(tipo, id)
+	atual, err := d.tx.Get(d.ctx, chave).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return false, fmt.Errorf("erro ao ler versão do cadastro %s %d: %w", tipo, id, err)
+	}
+	if err == nil && atual >= atualizadoEm.UnixNano() {
+		log.Printf("Cadastro %s %d: versão de %s já superada - ignorando", tipo, id, atualizadoEm.Format(time.RFC3339))
+		return false, nil
+	}
+
+	if d.versoes == nil {
+		d.versoes = make(map[string]time.Time)
+	}
+	d.versoes[chave] = atualizadoEm
+	return true, nil
+}
+
+// gravar enfileira no MULTI os documentos alterados e seus índices
+func (d *Documentos) gravar(pipe redis.Pipeliner) error {
+	for id := range d.alterados {
+		p := d.carregados[id]
+		p.AtualizadoEm = time.Now()
+
+		doc, err := json.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("erro ao serializar prescrição %d: %w", id, err)
+		}
+		pipe.Set(d.ctx, chavePrescricao(id), doc, 0)
+
+		// A farmácia só enxerga o que ainda pode ser dispensado; o prontuário mantém o histórico
+		if p.Status == domain.StatusPrescricaoAtiva {
+			pipe.ZAdd(d.ctx, chaveFarmacia, redis.Z{Score: float64(p.DataPrescricao.UnixMilli()), Member: id})
+		} else {
+			pipe.ZRem(d.ctx, chaveFarmacia, id)
+		}
+
+		pipe.SAdd(d.ctx, chaveIndice(IndicePaciente, p.Paciente.ID), id)
+		pipe.SAdd(d.ctx, chaveIndice(IndiceMedico, p.Medico.ID), id)
+		for _, m := range p.Medicamentos {
+			pipe.SAdd(d.ctx, chaveIndice(IndiceMedicamento, m.ID), id)
+		}
+	}
+
+	for chave, versao := range d.versoes {
+		pipe.Set(d.ctx, chave, versao.UnixNano(), 0)
+	}
+	return nil
+}
+
+// carregar lê documentos com MGET (no cliente ou dentro de um WATCH)
+func carregar(ctx context.Context, c redis.Cmdable, ids []int) ([]Prescricao, error) {
+	if len(ids) == 0 {
+		return []Prescricao{}, nil
+	}
+
+	chaves := make([]string, len(ids))
+	for i, id := range ids {
+		chaves[i] = chavePrescricao(id)
+	}
+
+	valores, err := c.MGet(ctx, chaves...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao carregar prescrições: %w", err)
+	}
+
+	docs := make([]Prescricao, 0, len(valores))
+	for i, v := range valores {
+		s, ok := v.(string)
+		if !ok {
+			continue // documento ainda não projetado (ou índice desatualizado)
+		}
+		var p Prescricao
+		if err := json.Unmarshal([]byte(s), &p); err != nil {
+			return nil, fmt.Errorf("documento da prescrição %d corrompido: %w", ids[i], err)
+		}
+		docs = append(docs, p)
+	}
+	return docs, nil
+}
+
+func paraInts(membros []string) ([]int, error) {
+	ids := make([]int, 0, len(membros))
+	for _, m := range membros {
+		id, err := strconv.Atoi(m)
+		if err != nil {
+			return nil, fmt.Errorf("id inválido no índice do Redis: %q", m)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,250 @@
+package regras
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"hospital-cqrs/internal/domain"
+)
+
+// =========================================
+// REGRAS CLÍNICAS DA PRESCRIÇÃO
+// =========================================
+// Interações medicamentosas, duplicidade terapêutica e dose máxima diária vêm de um arquivo
+// JSON (REGRAS_CLINICAS_ARQUIVO) e são avaliadas pelo command service contra as prescrições
+// ativas do paciente antes de criar uma nova. REGRAS_CLINICAS_MODO decide o que um alerta faz:
+// bloquear (padrão) recusa o comando a menos que o médico informe uma justificativa; alertar
+// cria a prescrição e devolve os alertas na resposta. Nos dois modos, prescrever apesar dos
+// alertas gera o evento de auditoria prescricao.alertas_ignorados.
+
+// Modos aceitos em REGRAS_CLINICAS_MODO
+const (
+	ModoBloquear = "bloquear"
+	ModoAlertar  = "alertar"
+)
+
+// Interacao é um par de medicamentos que não deve ser usado junto
+type Interacao struct {
+	Medicamentos [2]int `json:"medicamentos"`
+	Descricao    string `json:"descricao"`
+}
+
+// ClasseTerapeutica agrupa medicamentos de mesmo efeito: dois deles ao mesmo tempo são
+// duplicidade terapêutica (o mesmo medicamento em duas prescrições ativas também é)
+type ClasseTerapeutica struct {
+	Classe       string `json:"classe"`
+	Medicamentos []int  `json:"medicamentos"`
+}
+
+// DoseMaxima é o limite diário de um medicamento, somando todas as prescrições ativas
+type DoseMaxima struct {
+	Medicamento  int     `json:"medicamento"`
+	Nome         string  `json:"nome"`
+	DoseDiariaMg float64 `json:"dose_diaria_mg"`
+}
+
+// Regras é o conteúdo do arquivo de regras
+type Regras struct {
+	Interacoes   []Interacao         `json:"interacoes"`
+	Duplicidades []ClasseTerapeutica `json:"duplicidades"`
+	DosesMaximas []DoseMaxima        `json:"doses_maximas"`
+}
+
+// Motor avalia as regras carregadas
+type Motor struct {
+	modo       string
+	interacoes map[[2]int]string // par (menor id, maior id) -> descrição
+	classes    map[int][]string  // medicamento -> classes terapêuticas
+	doses      map[int]DoseMaxima
+}
+
+// Carregar lê o modo e o arquivo de regras do ambiente
+// Sem REGRAS_CLINICAS_ARQUIVO o motor não tem regras e nenhuma prescrição gera alerta
+func Carregar() (*Motor, error) {
+	modo := os.Getenv("REGRAS_CLINICAS_MODO")
+	if modo == "" {
+		modo = ModoBloquear
+	}
+
+	var regras Regras
+	if caminho := os.Getenv("REGRAS_CLINICAS_ARQUIVO"); caminho != "" {
+		var err error
+		if regras, err = LerArquivo(caminho); err != nil {
+			return nil, err
+		}
+	}
+	return NovoMotor(regras, modo)
+}
+
+// LerArquivo lê e decodifica o arquivo de regras; campos desconhecidos são erro
+func LerArquivo(caminho string) (Regras, error) {
+	conteudo, err := os.ReadFile(caminho)
+	if err != nil {
+		return Regras{}, fmt.Errorf("erro ao ler regras clínicas: %w", err)
+	}
+
+	var regras Regras
+	decoder := json.NewDecoder(bytes.NewReader(conteudo))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&regras); err != nil {
+		return Regras{}, fmt.Errorf("arquivo de regras clínicas %s inválido: %w", caminho, err)
+	}
+	return regras, nil
+}
+
+// NovoMotor valida as regras e monta os índices de consulta
+func NovoMotor(regras Regras, modo string) (*Motor, error) {
+	if modo != ModoBloquear && modo != ModoAlertar {
+		return nil, fmt.Errorf("REGRAS_CLINICAS_MODO inválido: %q (use %s ou %s)", modo, ModoBloquear, ModoAlertar)
+	}
+
+	m := &Motor{
+		modo:       modo,
+		interacoes: make(map[[2]int]string),
+		classes:    make(map[int][]string),
+		doses:      make(map[int]DoseMaxima),
+	}
+	for i, in := range regras.Interacoes {
+		a, b := in.Medicamentos[0], in.Medicamentos[1]
+		if a <= 0 || b <= 0 || a == b {
+			return nil, fmt.Errorf("interacoes[%d]: informe dois medicamentos diferentes", i)
+		}
+		m.interacoes[par(a, b)] = in.Descricao
+	}
+	for i, c := range regras.Duplicidades {
+		if c.Classe == "" || len(c.Medicamentos) == 0 {
+			return nil, fmt.Errorf("duplicidades[%d]: informe a classe e os medicamentos", i)
+		}
+		for _, id := range c.Medicamentos {
+			m.classes[id] = append(m.classes[id], c.Classe)
+		}
+	}
+	for i, d := range regras.DosesMaximas {
+		if d.Medicamento <= 0 || d.DoseDiariaMg <= 0 {
+			return nil, fmt.Errorf("doses_maximas[%d]: informe o medicamento e uma dose diária positiva", i)
+		}
+		m.doses[d.Medicamento] = d
+	}
+	return m, nil
+}
+
+// Modo retorna o modo configurado (bloquear ou alertar)
+func (m *Motor) Modo() string {
+	return m.modo
+}
+
+// Bloqueia informa se alertas sem justificativa recusam o comando
+func (m *Motor) Bloqueia() bool {
+	return m.modo == ModoBloquear
+}
+
+// Resumo descreve as regras carregadas para o log de inicialização
+func (m *Motor) Resumo() string {
+	return fmt.Sprintf("%d interação(ões), %d medicamento(s) em classes terapêuticas, %d dose(s) máxima(s); modo %s",
+		len(m.interacoes), len(m.classes), len(m.doses), m.modo)
+}
+
+// Avaliar confere os medicamentos da nova prescrição entre si e contra os itens das
+// prescrições ativas do paciente; sem alertas retorna nil
+func (m *Motor) Avaliar(novos []domain.MedicamentoPrescrito, ativos []domain.PrescricaoMedicamento) []domain.AlertaClinico {
+	var alertas []domain.AlertaClinico
+
+	for i, novo := range novos {
+		for _, outro := range novos[i+1:] {
+			alertas = append(alertas, m.conflitos(novo.IDMedicamento, outro.IDMedicamento, 0)...)
+		}
+		for _, ativo := range ativos {
+			if ativo.IDMedicamento == novo.IDMedicamento {
+				alertas = append(alertas, domain.AlertaClinico{
+					Tipo:              domain.AlertaDuplicidade,
+					Medicamentos:      []int{novo.IDMedicamento},
+					IDPrescricaoAtiva: ativo.IDPrescricao,
+					Mensagem:          fmt.Sprintf("Medicamento %d já prescrito na prescrição ativa %d", novo.IDMedicamento, ativo.IDPrescricao),
+				})
+				continue
+			}
+			alertas = append(alertas, m.conflitos(novo.IDMedicamento, ativo.IDMedicamento, ativo.IDPrescricao)...)
+		}
+		if alerta, ok := m.doseExcedida(novo, ativos); ok {
+			alertas = append(alertas, alerta)
+		}
+	}
+	return alertas
+}
+
+// conflitos verifica interação e duplicidade de classe entre dois medicamentos diferentes
+func (m *Motor) conflitos(a, b, idPrescricaoAtiva int) []domain.AlertaClinico {
+	var alertas []domain.AlertaClinico
+	if descricao, ok := m.interacoes[par(a, b)]; ok {
+		alertas = append(alertas, domain.AlertaClinico{
+			Tipo:              domain.AlertaInteracao,
+			Medicamentos:      []int{a, b},
+			IDPrescricaoAtiva: idPrescricaoAtiva,
+			Mensagem:          fmt.Sprintf("Interação entre os medicamentos %d e %d: %s", a, b, descricao),
+		})
+	}
+	for _, classe := range m.classes[a] {
+		if contem(m.classes[b], classe) {
+			alertas = append(alertas, domain.AlertaClinico{
+				Tipo:              domain.AlertaDuplicidade,
+				Medicamentos:      []int{a, b},
+				IDPrescricaoAtiva: idPrescricaoAtiva,
+				Mensagem:          fmt.Sprintf("Duplicidade terapêutica (%s): medicamentos %d e %d", classe, a, b),
+			})
+		}
+	}
+	return alertas
+}
+
+// doseExcedida soma a dose diária do novo item com a do mesmo medicamento nas prescrições
+// ativas; itens sem dose calculável (ver domain.DoseDiariaMg) ficam fora da soma
+func (m *Motor) doseExcedida(novo domain.MedicamentoPrescrito, ativos []domain.PrescricaoMedicamento) (domain.AlertaClinico, bool) {
+	limite, ok := m.doses[novo.IDMedicamento]
+	if !ok {
+		return domain.AlertaClinico{}, false
+	}
+	total, ok := domain.DoseDiariaMg(novo.Horario, novo.Dosagem)
+	if !ok {
+		return domain.AlertaClinico{}, false
+	}
+	for _, ativo := range ativos {
+		if ativo.IDMedicamento != novo.IDMedicamento {
+			continue
+		}
+		if dose, ok := domain.DoseDiariaMg(ativo.Horario, ativo.Dosagem); ok {
+			total += dose
+		}
+	}
+	if total <= limite.DoseDiariaMg {
+		return domain.AlertaClinico{}, false
+	}
+
+	nome := fmt.Sprintf("medicamento %d", novo.IDMedicamento)
+	if limite.Nome != "" {
+		nome = fmt.Sprintf("%s (medicamento %d)", limite.Nome, novo.IDMedicamento)
+	}
+	return domain.AlertaClinico{
+		Tipo:         domain.AlertaDoseMaxima,
+		Medicamentos: []int{novo.IDMedicamento},
+		Mensagem:     fmt.Sprintf("Dose diária de %s de %gmg excede o máximo de %gmg", nome, total, limite.DoseDiariaMg),
+	}, true
+}
+
+// par ordena os ids para que (a, b) e (b, a) sejam a mesma interação
+func par(a, b int) [2]int {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]int{a, b}
+}
+
+func contem(lista []string, valor string) bool {
+	for _, v := range lista {
+		if v == valor {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,305 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Producer representa um produtor Kafka
+// O tópico informado na criação é o padrão; PublishMessage pode enviar para qualquer tópico
+type Producer struct {
+	writer       *kafka.Writer
+	defaultTopic string
+}
+
+// NewProducer cria um novo produtor Kafka
+func NewProducer(topic ...string) (*Producer, error) {
+	brokers := os.Getenv("KAFKA_BROKERS")
+	if brokers == "" {
+		return nil, fmt.Errorf("KAFKA_BROKERS não configurada")
+	}
+
+	// Topic padrão ou fornecido
+	defaultTopic := "prescricoes"
+	if len(topic) > 0 && topic[0] != "" {
+		defaultTopic = topic[0]
+	}
+
+	// Sem Topic no writer: o tópico vai em cada mensagem, permitindo roteamento por evento
+	// As publicações são síncronas, uma mensagem por vez: sem BatchTimeout curto cada escrita
+	// esperaria o padrão de 1s do kafka-go para fechar o lote
+	writer := &kafka.Writer{
+		Addr:                   kafka.TCP(strings.Split(brokers, ",")...),
+		Balancer:               &kafka.LeastBytes{},
+		BatchTimeout:           10 * time.Millisecond,
+		AllowAutoTopicCreation: true,
+	}
+
+	log.Printf("Produtor Kafka criado (tópico padrão: %s)\n", defaultTopic)
+	return &Producer{writer: writer, defaultTopic: defaultTopic}, nil
+}
+
+// Publish publica uma mensagem no Kafka
+func (p *Producer) Publish(ctx context.Context, key string, value interface{}) error {
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar mensagem: %w", err)
+	}
+
+	return p.PublishMessage(ctx, p.defaultTopic, key, valueBytes, nil)
+}
+
+// PublishRaw publica bytes brutos (já serializados) no tópico padrão
+func (p *Producer) PublishRaw(ctx context.Context, key string, valueBytes []byte) error {
+	return p.PublishMessage(ctx, p.defaultTopic, key, valueBytes, nil)
+}
+
+// PublishMessage publica bytes já serializados em um tópico específico, com headers
+func (p *Producer) PublishMessage(ctx context.Context, topic, key string, valueBytes []byte, headers map[string]string) error {
+	msg := kafka.Message{
+		Topic: topic,
+		Key:   []byte(key),
+		Value: valueBytes,
+	}
+	for nome, valor := range headers {
+		msg.Headers = append(msg.Headers, kafka.Header{Key: nome, Value: []byte(valor)})
+	}
+
+	if err := p.writer.WriteMessages(ctx, msg); err != nil {
+		return fmt.Errorf("erro ao publicar mensagem: %w", err)
+	}
+
+	log.Printf("Mensagem publicada no tópico %s com key: %s\n", topic, key)
+	return nil
+}
+
+// GetTopic retorna o tópico padrão
+func (p *Producer) GetTopic() string {
+	return p.defaultTopic
+}
+
+// Close fecha o produtor
+func (p *Producer) Close() error {
+	return p.writer.Close()
+}
+
+// ErrMensagemInvalida marca um erro do handler que nenhuma nova tentativa resolve
+// (mensagem malformada): a mensagem vai direto para a DLQ
+var ErrMensagemInvalida = errors.New("mensagem inválida")
+
+// Cabeçalhos adicionados às mensagens enviadas para a DLQ
+const (
+	HeaderDLQTopico     = "dlq-topico-original"
+	HeaderDLQParticao   = "dlq-particao-original"
+	HeaderDLQOffset     = "dlq-offset-original"
+	HeaderDLQErro       = "dlq-erro"
+	HeaderDLQTentativas = "dlq-tentativas"
+)
+
+// Consumer representa um consumidor Kafka com entrega at-least-once
+//
+// O offset só é confirmado (CommitMessages) depois que o handler processa a mensagem ou
+// ela é enviada para a DLQ; se o processo cair no meio, a mensagem é reentregue. O handler
+// precisa ser idempotente (as projeções usam Processed_Events).
+type Consumer struct {
+	reader   *kafka.Reader
+	dlq      *kafka.Writer
+	dlqTopic string // KAFKA_DLQ_TOPIC; vazio usa <topico de origem>.dlq
+
+	maxTentativas int
+	esperaInicial time.Duration
+	esperaMaxima  time.Duration
+}
+
+// NewConsumer cria um novo consumidor Kafka
+// KAFKA_MAX_RETRIES define as tentativas por mensagem (padrão 5) e KAFKA_DLQ_TOPIC o tópico
+// de dead letter (padrão <topico>.dlq)
+func NewConsumer(topic, groupID string) (*Consumer, error) {
+	return NewConsumerTopicos([]string{topic}, groupID)
+}
+
+// NewConsumerTopicos cria um consumidor de vários tópicos no mesmo grupo
+// Sem KAFKA_DLQ_TOPIC, cada mensagem morta vai para a DLQ do seu tópico (<topico>.dlq)
+func NewConsumerTopicos(topics []string, groupID string) (*Consumer, error) {
+	if len(topics) == 0 {
+		return nil, fmt.Errorf("nenhum tópico informado")
+	}
+
+	brokers := os.Getenv("KAFKA_BROKERS")
+	if brokers == "" {
+		return nil, fmt.Errorf("KAFKA_BROKERS não configurada")
+	}
+
+	if groupID == "" {
+		groupID = os.Getenv("KAFKA_GROUP_ID")
+		if groupID == "" {
+			groupID = "default-group"
+		}
+	}
+
+	maxTentativas := 5
+	if v, err := strconv.Atoi(os.Getenv("KAFKA_MAX_RETRIES")); err == nil && v > 0 {
+		maxTentativas = v
+	}
+
+	dlqTopic := os.Getenv("KAFKA_DLQ_TOPIC")
+	dlqLog := dlqTopic
+	if dlqLog == "" {
+		dlqLog = "<topico>.dlq"
+	}
+
+	config := kafka.ReaderConfig{
+		Brokers:  strings.Split(brokers, ","),
+		GroupID:  groupID,
+		MinBytes: 10e3, // 10KB
+		MaxBytes: 10e6, // 10MB
+	}
+	if len(topics) == 1 {
+		config.Topic = topics[0]
+	} else {
+		config.GroupTopics = topics
+	}
+	reader := kafka.NewReader(config)
+
+	// Sem Topic no writer: o tópico da DLQ vai em cada mensagem
+	dlq := &kafka.Writer{
+		Addr:                   kafka.TCP(strings.Split(brokers, ",")...),
+		Balancer:               &kafka.Hash{}, // mesma key, mesma partição: preserva a ordem na DLQ
+		BatchTimeout:           10 * time.Millisecond,
+		AllowAutoTopicCreation: true,
+	}
+
+	log.Printf("Consumidor Kafka criado para tópico(s): %s (grupo: %s, DLQ: %s, tentativas: %d)\n",
+		strings.Join(topics, ", "), groupID, dlqLog, maxTentativas)
+	return &Consumer{
+		reader:        reader,
+		dlq:           dlq,
+		dlqTopic:      dlqTopic,
+		maxTentativas: maxTentativas,
+		esperaInicial: 500 * time.Millisecond,
+		esperaMaxima:  30 * time.Second,
+	}, nil
+}
+
+// Consume consome mensagens do Kafka até o ctx ser cancelado
+//
+// Cada mensagem é tentada até maxTentativas vezes, com backoff exponencial; esgotadas as
+// tentativas (ou com ErrMensagemInvalida) ela vai para a DLQ e o consumo segue. Com o ctx
+// cancelado, retorna nil sem confirmar a mensagem em andamento, que será reentregue.
+func (c *Consumer) Consume(ctx context.Context, handler func([]byte) error) error {
+	for {
+		msg, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("erro ao ler mensagem: %w", err)
+		}
+
+		log.Printf("Mensagem recebida do tópico %s: %s\n", msg.Topic, string(msg.Key))
+
+		if err := c.processar(ctx, msg, handler); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		if err := c.reader.CommitMessages(ctx, msg); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("erro ao confirmar offset %d: %w", msg.Offset, err)
+		}
+	}
+}
+
+// processar entrega a mensagem ao handler com retry; sem sucesso, envia para a DLQ
+func (c *Consumer) processar(ctx context.Context, msg kafka.Message, handler func([]byte) error) error {
+	espera := c.esperaInicial
+	var err error
+	tentativa := 1
+	for ; ; tentativa++ {
+		err = handler(msg.Value)
+		if err == nil {
+			log.Println("Mensagem processada com sucesso")
+			return nil
+		}
+		if errors.Is(err, ErrMensagemInvalida) || tentativa >= c.maxTentativas {
+			break
+		}
+
+		log.Printf("Erro ao processar mensagem (tentativa %d/%d, nova tentativa em %s): %v\n",
+			tentativa, c.maxTentativas, espera, err)
+		if err := esperar(ctx, espera); err != nil {
+			return err
+		}
+		espera = min(espera*2, c.esperaMaxima)
+	}
+
+	log.Printf("❌ Enviando mensagem para a DLQ %s (offset %d, %d tentativa(s)): %v\n",
+		c.topicoDLQ(msg), msg.Offset, tentativa, err)
+	return c.enviarDLQ(ctx, msg, err, tentativa)
+}
+
+// enviarDLQ publica a mensagem original na DLQ com a origem e o erro nos headers
+// Sem a DLQ disponível a mensagem não pode ser confirmada: tenta até conseguir ou o ctx acabar
+func (c *Consumer) enviarDLQ(ctx context.Context, msg kafka.Message, causa error, tentativas int) error {
+	headers := append([]kafka.Header{}, msg.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: HeaderDLQTopico, Value: []byte(msg.Topic)},
+		kafka.Header{Key: HeaderDLQParticao, Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: HeaderDLQOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		kafka.Header{Key: HeaderDLQErro, Value: []byte(causa.Error())},
+		kafka.Header{Key: HeaderDLQTentativas, Value: []byte(strconv.Itoa(tentativas))},
+	)
+	dead := kafka.Message{Topic: c.topicoDLQ(msg), Key: msg.Key, Value: msg.Value, Headers: headers}
+
+	espera := c.esperaInicial
+	for {
+		err := c.dlq.WriteMessages(ctx, dead)
+		if err == nil {
+			return nil
+		}
+
+		log.Printf("Erro ao publicar na DLQ %s (nova tentativa em %s): %v\n", dead.Topic, espera, err)
+		if err := esperar(ctx, espera); err != nil {
+			return err
+		}
+		espera = min(espera*2, c.esperaMaxima)
+	}
+}
+
+// topicoDLQ é KAFKA_DLQ_TOPIC ou, sem ele, <topico de origem>.dlq
+func (c *Consumer) topicoDLQ(msg kafka.Message) string {
+	if c.dlqTopic != "" {
+		return c.dlqTopic
+	}
+	return msg.Topic + ".dlq"
+}
+
+// esperar dorme pelo intervalo ou até o ctx ser cancelado
+func esperar(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// Close fecha o consumidor e o produtor da DLQ
+func (c *Consumer) Close() error {
+	errReader := c.reader.Close()
+	errDLQ := c.dlq.Close()
+	return errors.Join(errReader, errDLQ)
+}